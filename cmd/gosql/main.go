@@ -2,6 +2,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -23,7 +24,25 @@ func main() {
 		os.Exit(2)
 	}
 
-	if err := cmd.Run(); err != nil {
+	switch config.Subcommand {
+	case "":
+		if err = cmd.Run(); errors.Is(err, command.ErrStale) {
+			os.Exit(1)
+		}
+	case "enums":
+		err = cmd.RunEnums()
+	case "models":
+		err = cmd.RunModels()
+	case "scaffold":
+		err = cmd.RunScaffold()
+	case "drift":
+		if err = cmd.RunDrift(); errors.Is(err, command.ErrDrift) {
+			os.Exit(1)
+		}
+	default:
+		err = fmt.Errorf("unknown subcommand: %q", config.Subcommand)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "gosql: an error occurred ...\n - %v\n", err)
 		os.Exit(2)
 	}
@@ -10,10 +10,8 @@ require (
 	github.com/frk/fql v0.0.1
 	github.com/frk/tagutil v0.0.0-20190626082652-a41f4c5605cc
 	github.com/lib/pq v1.10.2
+	golang.org/x/mod v0.24.0
 	golang.org/x/tools v0.32.0
 )
 
-require (
-	golang.org/x/mod v0.24.0 // indirect
-	golang.org/x/sync v0.13.0 // indirect
-)
+require golang.org/x/sync v0.13.0 // indirect
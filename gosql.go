@@ -190,6 +190,28 @@ type (
 	// The All directive accepts no tags.
 	All directive
 
+	// The SoftDelete directive can be used inside a relation struct type to
+	// declare that the relation's records are "soft-deleted" by setting the
+	// specified column, which is expected to be a nullable timestamp column.
+	//
+	// DeleteXxx query types whose relation has this directive will produce an
+	// UPDATE query that sets the column to now() instead of a DELETE query,
+	// and SelectXxx query types will produce a WHERE clause that excludes
+	// the rows whose column is not NULL. The same holds for relations that
+	// are joined in a "join struct" if they are configured as soft-deleted.
+	// The expected format for the directive's tag value is:
+	//
+	//	`sql:"{ column_ident }"`
+	SoftDelete directive
+
+	// The WithDeleted directive can be used in SelectXxx and DeleteXxx query
+	// types to opt out of the SoftDelete policy of the target relation, meaning
+	// that the SELECT query will include the soft-deleted records and that the
+	// DELETE query will actually delete the records.
+	//
+	// The WithDeleted directive accepts no tags.
+	WithDeleted directive
+
 	// The Relation directive has two use cases:
 	//
 	// (1) It can be used in a DeleteXxx query type as the "mount" for the
@@ -219,6 +241,12 @@ type (
 	//
 	//	`sql:"{ column_ident [ predicate-type [ quantifier ] { column_ident | literal } ] }"`
 	//
+	// Besides the predefined predicate types, the predicate-type can be any
+	// binary operator that exists in the database and returns a boolean, e.g.
+	// `sql:"t.tags @> '{a}'"`, or `sql:"t.name % 'foo'"` with the pg_trgm
+	// extension. The same applies to the tags of plain "where struct" fields
+	// and to the join conditions of the join directives.
+	//
 	// (2) It can be used in an "on_conflict struct" to specify the resulting
 	// ON CONFLICT clause's conflict_target as a list of column names that have
 	// an index associated with them. The list of columns should be provided
@@ -241,7 +269,9 @@ type (
 	// The InnerJoin directive can be used to produce the INNER JOIN clause.
 	// This directive can only be used within a "join struct", a "using struct",
 	// or a "from struct". The `sql` struct tag supplied with the directive will
-	// be used to produce the join_condition.
+	// be used to produce the join_condition. If the join_condition is omitted
+	// it will be inferred from the single foreign key that links the joined
+	// relation to one of the relations preceding it in the query.
 	//
 	// The expected format for the directive's tag value is:
 	//
@@ -251,7 +281,9 @@ type (
 	// The LeftJoin directive can be used to produce the LEFT JOIN clause.
 	// This directive can only be used within a "join struct", a "using struct",
 	// or a "from struct". The `sql` struct tag supplied with the directive will
-	// be used to produce the join_condition.
+	// be used to produce the join_condition. If the join_condition is omitted
+	// it will be inferred from the single foreign key that links the joined
+	// relation to one of the relations preceding it in the query.
 	//
 	// The expected format for the directive's tag value is:
 	//
@@ -261,7 +293,9 @@ type (
 	// The RightJoin directive can be used to produce the RIGHT JOIN clause.
 	// This directive can only be used within a "join struct", a "using struct",
 	// or a "from struct". The `sql` struct tag supplied with the directive will
-	// be used to produce the join_condition.
+	// be used to produce the join_condition. If the join_condition is omitted
+	// it will be inferred from the single foreign key that links the joined
+	// relation to one of the relations preceding it in the query.
 	//
 	// The expected format for the directive's tag value is:
 	//
@@ -271,7 +305,9 @@ type (
 	// The FullJoin directive can be used to produce the FULL JOIN clause.
 	// This directive can only be used within a "join struct", a "using struct",
 	// or a "from struct". The `sql` struct tag supplied with the directive will
-	// be used to produce the join_condition.
+	// be used to produce the join_condition. If the join_condition is omitted
+	// it will be inferred from the single foreign key that links the joined
+	// relation to one of the relations preceding it in the query.
 	//
 	// The expected format for the directive's tag value is:
 	//
@@ -340,11 +376,18 @@ type (
 	//
 	// and the expected format of the order_by_item is:
 	//
-	//	[ - ]column_ident[ :nullsfirst | :nullslast ]
+	//	[ - ]column_ident[ operator $FieldName ][ :nullsfirst | :nullslast ]
 	//
 	// The optional preceding "-" produces the DESC sort direction option,
 	// if no "-" is provided ASC is produced instead. The optional ":nullsfirst"
 	// and ":nullslast" produce the NULLS FIRST and NULLS LAST options respectively.
+	//
+	// The optional "operator $FieldName" orders by the result of the binary
+	// operator applied to the column and to the value of the query type's
+	// field named FieldName, which is passed to the query as a parameter.
+	// This is how nearest-neighbor searches are produced, for example, the
+	// `sql:"location <-> $Point"` tag produces "ORDER BY location <-> $1".
+	// Items with an operator can only be used in a plain SelectXxx query type.
 	OrderBy directive
 
 	// The Override directive can be used in an InsertXxx query type to produce
@@ -424,3 +467,48 @@ func InValueList(num, pos int) string {
 
 	return b.String()
 }
+
+// FilterAndWhere merges the given search condition into the WHERE clause of
+// the given filterString, which is expected to have been produced by a Filter's
+// ToSQL method. If the filterString has no WHERE clause then a new one, with
+// just the given search condition, is prepended to the filterString. If the
+// search condition is empty the filterString is returned unchanged.
+//
+// The generated code uses FilterAndWhere to apply static conditions, like the
+// one produced for a gosql.SoftDelete relation, to queries that use a Filter.
+func FilterAndWhere(filterString string, cond string) string {
+	const where = " WHERE "
+	if cond = strings.TrimSpace(cond); len(cond) == 0 {
+		return filterString
+	}
+	if !strings.HasPrefix(filterString, where) {
+		return where + cond + filterString
+	}
+
+	// Find the end of the WHERE clause's search condition, that is the
+	// start of the ORDER BY, LIMIT, or OFFSET clause, if there is one,
+	// while skipping over quoted and parenthesized text.
+	rest := filterString[len(where):]
+	end, depth, quote := len(rest), 0, byte(0)
+	for i := 0; i < len(rest) && end == len(rest); i++ {
+		switch c := rest[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ' ' && depth == 0:
+			if strings.HasPrefix(rest[i:], " ORDER BY ") ||
+				strings.HasPrefix(rest[i:], " LIMIT ") ||
+				strings.HasPrefix(rest[i:], " OFFSET ") {
+				end = i
+			}
+		}
+	}
+	return where + cond + " AND (" + rest[:end] + ")" + rest[end:]
+}
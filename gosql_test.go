@@ -0,0 +1,46 @@
+package gosql
+
+import (
+	"testing"
+
+	"github.com/frk/compare"
+)
+
+func TestFilterAndWhere(t *testing.T) {
+	tests := []struct {
+		filter string
+		cond   string
+		want   string
+	}{{
+		filter: "",
+		cond:   "",
+		want:   "",
+	}, {
+		filter: ` WHERE "a" = $1`,
+		cond:   "",
+		want:   ` WHERE "a" = $1`,
+	}, {
+		filter: "",
+		cond:   `"deleted_at" IS NULL `,
+		want:   ` WHERE "deleted_at" IS NULL`,
+	}, {
+		filter: ` ORDER BY "a" ASC LIMIT 10`,
+		cond:   `"deleted_at" IS NULL`,
+		want:   ` WHERE "deleted_at" IS NULL ORDER BY "a" ASC LIMIT 10`,
+	}, {
+		filter: ` WHERE "a" = $1 OR "b" = $2`,
+		cond:   `"deleted_at" IS NULL`,
+		want:   ` WHERE "deleted_at" IS NULL AND ("a" = $1 OR "b" = $2)`,
+	}, {
+		filter: ` WHERE ("a" = $1 OR "b" = ' LIMIT ') ORDER BY "a" DESC OFFSET 5`,
+		cond:   `"deleted_at" IS NULL`,
+		want:   ` WHERE "deleted_at" IS NULL AND (("a" = $1 OR "b" = ' LIMIT ')) ORDER BY "a" DESC OFFSET 5`,
+	}}
+
+	for _, tt := range tests {
+		got := FilterAndWhere(tt.filter, tt.cond)
+		if err := compare.Compare(got, tt.want); err != nil {
+			t.Errorf("FilterAndWhere(%q, %q): %v", tt.filter, tt.cond, err)
+		}
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/frk/gosql/internal/config"
 	"github.com/frk/gosql/internal/typesutil"
@@ -286,6 +288,9 @@ func analyzeQueryStruct(a *analysis, structType *types.Struct) (*QueryStruct, er
 		}
 	}
 
+	analyzeSoftDeletePolicy(a)
+	analyzeAutoNowReturn(a)
+
 	// TODO(mkopriva): if QueryKind is Select, Update, or Insert, and the analyzed
 	// RelType.Fields slice is empty (for Select also check ResultType.Fields), then fail.
 
@@ -369,6 +374,9 @@ func analyzeQueryStructDirective(a *analysis, f *types.Var, tag string, dirname
 		"offset":   analyzeOffsetFieldOrDirective,
 		"orderby":  analyzeOrderByDirective,
 		"override": analyzeOverrideDirective,
+		// NOTE(mkopriva): the SoftDelete directive is not listed here because
+		// it is analyzed as part of the relation type, see analyzeFieldInfoList.
+		"withdeleted": analyzeWithDeletedDirective,
 	}
 	if afunc, ok := analyzers[strings.ToLower(dirname)]; ok {
 		return afunc(a, f, tag)
@@ -390,6 +398,7 @@ func analyzeQueryStructField(a *analysis, f *types.Var, tag string) error {
 		"limit":        analyzeLimitFieldOrDirective,
 		"offset":       analyzeOffsetFieldOrDirective,
 		"rowsaffected": analyzeRowsAffectedField,
+		"totalcount":   analyzeTotalCountField,
 	}
 	if afunc, ok := analyzers[tolower(f.Name())]; ok {
 		return afunc(a, f, tag)
@@ -501,10 +510,6 @@ func analyzeRelType(a *analysis, rt *RelType, field *types.Var) error {
 		rt.Base.IsImported = isImportedType(a, named)
 		rt.IsAfterScanner = typesutil.ImplementsAfterScanner(named)
 		ftyp = named.Underlying()
-
-		relTypeCache.Lock()
-		relTypeCache.m[cacheKey] = rt
-		relTypeCache.Unlock()
 	}
 
 	rt.Base.Kind = analyzeTypeKind(ftyp)
@@ -513,11 +518,22 @@ func analyzeRelType(a *analysis, rt *RelType, field *types.Var) error {
 	}
 
 	styp := ftyp.(*types.Struct)
-	return analyzeFieldInfoList(a, rt, styp)
+	if err := analyzeFieldInfoList(a, rt, styp); err != nil {
+		return err
+	}
+
+	// Cache the relType only once it's been fully analyzed, otherwise
+	// a concurrent analysis could end up with an incomplete copy of it.
+	if named != nil {
+		relTypeCache.Lock()
+		relTypeCache.m[cacheKey] = rt
+		relTypeCache.Unlock()
+	}
+	return nil
 }
 
 // analyzeFieldInfoList
-func analyzeFieldInfoList(a *analysis, rt *RelType, styp *types.Struct) error {
+func analyzeFieldInfoList(a *analysis, rt *RelType, styp *types.Struct) (err error) {
 	// The loopstate type holds the state of a loop over a struct's fields.
 	type loopstate struct {
 		styp     *types.Struct // the struct type whose fields are being analyzed
@@ -544,6 +560,16 @@ stackloop:
 			// when continuing to the outer loop.
 			loop.idx++
 
+			// The gosql.SoftDelete directive is the only directive that
+			// is recognized inside a relation type, it declares the column
+			// that marks the type's records as deleted.
+			if fvar.Name() == "_" && typesutil.IsDirective("SoftDelete", fvar.Type()) {
+				if err := analyzeSoftDeleteDirective(a, rt, fvar, ftag); err != nil {
+					return err
+				}
+				continue
+			}
+
 			// Ignore the field if:
 			// - no column name or sql tag was provided
 			if sqltag == "" ||
@@ -606,6 +632,59 @@ stackloop:
 				continue stackloop
 			}
 
+			// If the `sql` tag has the "many" option the field is a read-only
+			// field that holds the records of a child relation which are
+			// selected, in the same query, using the tag's join conditions.
+			if tag.HasOption("sql", "many") {
+				if f.Many, err = analyzeManyRelation(a, rt, fvar, ftag); err != nil {
+					return err
+				}
+
+				f.ColIdent = ColIdent{Name: exprFieldAlias(loop.selector, f.Name)}
+				f.Selector = loop.selector
+				f.Mode = mode_select
+				rt.Fields = append(rt.Fields, f)
+				a.info.FieldMap[f] = FieldVar{Var: fvar, Tag: ftag}
+				continue
+			}
+
+			// If the `sql` tag starts with "=" the field is a read-only
+			// field whose value is produced by the SQL expression that
+			// follows the "=". Since the expression itself may contain
+			// commas it is taken verbatim from the raw tag.
+			if strings.HasPrefix(sqltag, "=") {
+				expr := reflect.StructTag(ftag).Get("sql")
+				expr = strings.TrimSpace(strings.TrimPrefix(expr, "="))
+				if len(expr) == 0 {
+					return a.error(errMissingTagValue, fvar, "", ftag, "", "")
+				}
+
+				f.Expr = expr
+				f.ColIdent = ColIdent{Name: exprFieldAlias(loop.selector, f.Name)}
+				f.Selector = loop.selector
+				f.Mode = mode_select
+				rt.Fields = append(rt.Fields, f)
+				a.info.FieldMap[f] = FieldVar{Var: fvar, Tag: ftag}
+				continue
+			}
+
+			// If the `sql` tag starts with "@" the field is a read-only
+			// field whose value is produced by a window function call.
+			if strings.HasPrefix(sqltag, "@") {
+				win, ecode, eval := parseWindowFunc(a, tag)
+				if ecode > 0 {
+					return a.error(ecode, fvar, "", ftag, "", eval)
+				}
+
+				f.Window = win
+				f.ColIdent = ColIdent{Name: exprFieldAlias(loop.selector, f.Name)}
+				f.Selector = loop.selector
+				f.Mode = mode_select
+				rt.Fields = append(rt.Fields, f)
+				a.info.FieldMap[f] = FieldVar{Var: fvar, Tag: ftag}
+				continue
+			}
+
 			// Resolve the column id.
 			cid, ecode, eval := parseColIdent(a, loop.pfx+sqltag)
 			if ecode > 0 {
@@ -626,6 +705,12 @@ stackloop:
 			f.UseAdd = tag.HasOption("sql", "add")
 			f.UseDefault = tag.HasOption("sql", "default")
 			f.UseCoalesce, f.CoalesceValue = parseCoalesceInfo(tag)
+			if f.AutoNow, f.AutoNowFunc, eval = parseAutoNowInfo(tag); len(eval) > 0 {
+				return a.error(errBadAutoNowTagValue, fvar, "", ftag, "", eval)
+			}
+			if styp, ok := ftyp.(*types.Struct); ok {
+				f.Composite = analyzeCompositeFields(a, f.Type, styp)
+			}
 
 			if err := parseFilterColumnKey(a, f); err != nil {
 				return err
@@ -640,6 +725,121 @@ stackloop:
 	return nil
 }
 
+// analyzeCompositeFields analyzes the fields of the given struct type as the
+// fields of a composite type's row value. Only the exported fields that have
+// an `sql` tag are included in the result. If the field's type implements the
+// sql.Scanner or the driver.Valuer interface nil is returned.
+func analyzeCompositeFields(a *analysis, typ TypeInfo, styp *types.Struct) (fields []*CompositeField) {
+	for typ.Elem != nil && (typ.Kind == TypeKindPtr ||
+		typ.Kind == TypeKindSlice || typ.Kind == TypeKindArray) {
+		typ = *typ.Elem
+	}
+	if typ.IsScanner || typ.IsValuer {
+		return nil
+	}
+
+	for i := 0; i < styp.NumFields(); i++ {
+		fvar := styp.Field(i)
+		attr := tagutil.New(styp.Tag(i)).First("sql")
+		if attr == "" || attr == "-" || fvar.Name() == "_" || !fvar.Exported() {
+			continue
+		}
+
+		cf := new(CompositeField)
+		cf.Name = fvar.Name()
+		cf.Type, _ = analyzeTypeInfo(a, fvar.Type())
+		cf.Attr = attr
+		fields = append(fields, cf)
+	}
+	return fields
+}
+
+// analyzeManyRelation analyzes the given field as a "many" field, i.e. a field
+// whose `sql` tag has the "many" option, and returns the result. The FieldMap
+// entries of the child relation's type are added to the given parent RelType.
+//
+// ✅ The type of the field MUST be a slice of named structs or a slice of
+// pointers to named structs.
+// ✅ The `sql` tag MUST start with a valid relation identifier.
+// ✅ The fields of the element type MUST NOT be nested, window function, or "many" fields.
+func analyzeManyRelation(a *analysis, rt *RelType, f *types.Var, ftag string) (*ManyRelation, error) {
+	if !isManyFieldType(f.Type()) {
+		return nil, a.error(errBadManyFieldType, f, "", ftag, "", "")
+	}
+
+	tag := tagutil.New(ftag)
+	rid, ecode := parseRelIdent(tag.First("sql"))
+	if ecode > 0 {
+		return nil, a.error(ecode, f, "", ftag, "", tag.First("sql"))
+	} else if ecode, errval := addToRelSpace(a, rid); ecode > 0 {
+		return nil, a.error(ecode, f, "", ftag, "", errval)
+	}
+
+	var conds []string
+	for _, val := range tag["sql"][1:] {
+		if val != "many" {
+			conds = append(conds, val)
+		}
+	}
+
+	many := new(ManyRelation)
+	many.RelIdent = rid
+	items, err := parseJoinTagItems(a, rid, conds, f, "", ftag)
+	if err != nil {
+		return nil, err
+	}
+	many.TagItems = items
+
+	if err := analyzeRelType(a, &many.Type, f); err != nil {
+		return nil, err
+	}
+	for k, v := range many.Type.FieldMap {
+		rt.FieldMap[k] = v
+	}
+	for _, cf := range many.Type.Fields {
+		if len(cf.Selector) > 0 || cf.Window != nil || cf.Many != nil {
+			fv := many.Type.FieldMap[cf]
+			return nil, a.error(errIllegalManyChildField, fv.Var, "", fv.Tag, "", "")
+		}
+	}
+
+	// The records of the child relation that are marked as deleted,
+	// either by the gosql.SoftDelete directive or by the config, are
+	// excluded from the field.
+	qual := rid.Alias
+	if len(qual) == 0 {
+		qual = rid.Name
+	}
+	if sd := many.Type.SoftDelete; sd != nil {
+		many.SoftDelete = new(SoftDeleteDirective)
+		many.SoftDelete.ColIdent = ColIdent{Name: sd.Name, Qualifier: qual}
+		rt.FieldMap[many.SoftDelete] = many.Type.FieldMap[sd]
+	} else if col := softDeleteColumn(a, rid); len(col) > 0 {
+		many.SoftDelete = new(SoftDeleteDirective)
+		many.SoftDelete.ColIdent = ColIdent{Name: col, Qualifier: qual}
+		rt.FieldMap[many.SoftDelete] = FieldVar{Var: f, Tag: ftag}
+	}
+	return many, nil
+}
+
+// isManyFieldType reports whether or not the given type can be used
+// as the type of a "many" field.
+func isManyFieldType(typ types.Type) bool {
+	slice, ok := typ.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	elem := slice.Elem()
+	if ptr, ok := elem.(*types.Pointer); ok {
+		elem = ptr.Elem()
+	}
+	if named, ok := elem.(*types.Named); ok {
+		_, ok = named.Underlying().(*types.Struct)
+		return ok
+	}
+	return false
+}
+
 // analyzeTypeInfo function analyzes the given type and returns the result. The analysis
 // looks only for information of "named types" and in case of slice, array, map, or
 // pointer types it will analyze the element type of those types. The second return
@@ -857,7 +1057,7 @@ stackloop:
 
 					item := new(WhereColumnDirective)
 					item.LHSColIdent = cid
-					item.Predicate = stringToPredicate[op]
+					item.Predicate, item.Operator = parsePredicate(op)
 					item.Quantifier = stringToQuantifier[op2]
 
 					if cid, ecode, eval := parseColIdent(a, rhs); ecode > 0 {
@@ -997,7 +1197,7 @@ stackloop:
 			item.Name = fvar.Name()
 			item.Type, _ = analyzeTypeInfo(a, fvar.Type())
 			item.ColIdent = cid
-			item.Predicate = stringToPredicate[op]
+			item.Predicate, item.Operator = parsePredicate(op)
 			item.Quantifier = stringToQuantifier[op2]
 			item.FuncName = parseFuncName(tag["sql"][1:])
 
@@ -1009,6 +1209,8 @@ stackloop:
 				return a.error(errIllegalFieldQuantifier, fvar, f.Name(), ftag, sqltag, op2)
 			} else if item.Predicate.IsArray() && !item.Type.IsSequence() {
 				return a.error(errIllegalListPredicate, fvar, f.Name(), ftag, sqltag, op)
+			} else if item.Predicate.IsOperator() && len(item.FuncName) > 0 {
+				return a.error(errIllegalOperatorModifier, fvar, f.Name(), ftag, sqltag, string(item.FuncName))
 			}
 
 			a.info.FieldMap[item] = FieldVar{Var: fvar, Tag: ftag}
@@ -1127,8 +1329,20 @@ func analyzeJoinStructJoinDirective(a *analysis, j *JoinStruct, dirName string,
 	dir := new(JoinDirective)
 	dir.RelIdent = rid
 	dir.JoinType = stringToJoinType[dirName]
+	if dir.TagItems, err = parseJoinTagItems(a, rid, tag["sql"][1:], f, j.FieldName, ftag); err != nil {
+		return err
+	}
 
-	for _, val := range tag["sql"][1:] {
+	j.Directives = append(j.Directives, dir)
+	a.info.FieldMap[dir] = FieldVar{Var: f, Tag: ftag}
+	return nil
+}
+
+// parseJoinTagItems parses the given `sql` tag values as the join conditions
+// of the relation identified by rid and returns the result as []JoinTagItem.
+// The values are ANDed together, except for those separated by ";" which are ORed.
+func parseJoinTagItems(a *analysis, rid RelIdent, tagvals []string, f *types.Var, blockName, ftag string) (items []JoinTagItem, err error) {
+	for _, val := range tagvals {
 		vals := strings.Split(val, ";")
 		for i, val := range vals {
 
@@ -1147,7 +1361,7 @@ func analyzeJoinStructJoinDirective(a *analysis, j *JoinStruct, dirName string,
 
 			cid, ecode, eval := parseColIdent(a, lhs)
 			if ecode > 0 {
-				return a.error(ecode, f, j.FieldName, ftag, val, eval)
+				return nil, a.error(ecode, f, blockName, ftag, val, eval)
 			}
 
 			// NOTE(mkopriva): At the moment a join condition's left-hand-side
@@ -1159,19 +1373,19 @@ func analyzeJoinStructJoinDirective(a *analysis, j *JoinStruct, dirName string,
 			// operands regardless of which side they are positioned in.
 			if len(cid.Qualifier) > 0 && (len(rid.Alias) > 0 && rid.Alias != cid.Qualifier) ||
 				(len(rid.Alias) == 0 && rid.Name != cid.Qualifier) {
-				return a.error(errBadJoinConditionLHS, f, j.FieldName, ftag, val, lhs)
+				return nil, a.error(errBadJoinConditionLHS, f, blockName, ftag, val, lhs)
 			}
 
 			item := new(JoinConditionTagItem)
 			item.LHSColIdent = cid
-			item.Predicate = stringToPredicate[op]
+			item.Predicate, item.Operator = parsePredicate(op)
 			item.Quantifier = stringToQuantifier[op2]
 
 			// binary expression?
 			if len(rhs) > 0 {
 				if cid, ecode, eval := parseColIdent(a, rhs); ecode > 0 {
 					if ecode != errBadColIdTagValue {
-						return a.error(ecode, f, j.FieldName, ftag, val, eval)
+						return nil, a.error(ecode, f, blockName, ftag, val, eval)
 					}
 					// assume literal expression
 					item.RHSLiteral = rhs
@@ -1180,9 +1394,9 @@ func analyzeJoinStructJoinDirective(a *analysis, j *JoinStruct, dirName string,
 				}
 
 				if item.Predicate.IsUnary() {
-					return a.error(errIllegalUnaryPredicate, f, j.FieldName, ftag, val, op)
+					return nil, a.error(errIllegalUnaryPredicate, f, blockName, ftag, val, op)
 				} else if item.Quantifier > 0 && !item.Predicate.CanQuantify() {
-					return a.error(errIllegalPredicateQuantifier, f, j.FieldName, ftag, val, op2)
+					return nil, a.error(errIllegalPredicateQuantifier, f, blockName, ftag, val, op2)
 				}
 			} else { // unary expression?
 
@@ -1193,24 +1407,21 @@ func analyzeJoinStructJoinDirective(a *analysis, j *JoinStruct, dirName string,
 
 				// TODO
 				if !item.Predicate.IsUnary() {
-					return a.error(errBadDirectiveBooleanExpr, f, j.FieldName, ftag, "", val)
+					return nil, a.error(errBadDirectiveBooleanExpr, f, blockName, ftag, "", val)
 				} else if len(op2) > 0 {
-					return a.error(errIllegalPredicateQuantifier, f, j.FieldName, ftag, val, op2)
+					return nil, a.error(errIllegalPredicateQuantifier, f, blockName, ftag, val, op2)
 				}
 			}
 
-			if len(dir.TagItems) > 0 && i == 0 {
-				dir.TagItems = append(dir.TagItems, &JoinBoolTagItem{BoolAnd})
-			} else if len(dir.TagItems) > 0 && i > 0 {
-				dir.TagItems = append(dir.TagItems, &JoinBoolTagItem{BoolOr})
+			if len(items) > 0 && i == 0 {
+				items = append(items, &JoinBoolTagItem{BoolAnd})
+			} else if len(items) > 0 && i > 0 {
+				items = append(items, &JoinBoolTagItem{BoolOr})
 			}
-			dir.TagItems = append(dir.TagItems, item)
+			items = append(items, item)
 		}
 	}
-
-	j.Directives = append(j.Directives, dir)
-	a.info.FieldMap[dir] = FieldVar{Var: f, Tag: ftag}
-	return nil
+	return items, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -1548,6 +1759,23 @@ func analyzeRowsAffectedField(a *analysis, f *types.Var, tag string) error {
 	return nil
 }
 
+func analyzeTotalCountField(a *analysis, f *types.Var, tag string) error {
+	if a.query.Kind != QueryKindSelect || a.query.Rel.Type.IsSingle() {
+		return a.error(errIllegalQueryField, f, "", tag, "", "")
+	}
+
+	ftyp := f.Type()
+	if !isIntegerType(ftyp) {
+		return a.error(errBadFieldTypeInt, f, "", tag, "", "")
+	}
+
+	a.query.TotalCount = new(TotalCountField)
+	a.query.TotalCount.Name = f.Name()
+	a.query.TotalCount.TypeKind = analyzeTypeKind(ftyp)
+	a.info.FieldMap[a.query.TotalCount] = FieldVar{Var: f, Tag: tag}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Directive Fields Analysis
 //
@@ -1586,6 +1814,26 @@ func analyzeOrderByDirective(a *analysis, f *types.Var, tag string) (err error)
 			val = val[:i]
 		}
 
+		// An item of the form "column operator $Field" orders by the
+		// result of the operator, e.g. the distance between the column
+		// and the field's value in a nearest-neighbor search.
+		if lhs, op, op2, rhs := parsePredicateExpr(val); len(op) > 0 {
+			if len(op2) > 0 || !isOperatorChar(op[0]) || a.query.Kind != QueryKindSelect {
+				return a.error(errBadOrderByTagValue, f, "", tag, "", val)
+			}
+			if len(rhs) < 2 || rhs[0] != '$' {
+				return a.error(errBadOrderByTagValue, f, "", tag, "", val)
+			}
+
+			param := analyzeOrderByParamField(a, rhs[1:])
+			if param == nil {
+				return a.error(errBadOrderByTagValue, f, "", tag, "", val)
+			}
+			item.Operator = op
+			item.Param = param
+			val = lhs
+		}
+
 		cid, ecode, eval := parseColIdent(a, val)
 		if ecode > 0 {
 			return a.error(ecode, f, "", tag, val, eval)
@@ -1601,6 +1849,30 @@ func analyzeOrderByDirective(a *analysis, f *types.Var, tag string) (err error)
 	return nil
 }
 
+// analyzeOrderByParamField looks up the query struct's field with the given
+// name and returns the result of its analysis, or nil if the query struct
+// has no such field or if the field is not accessible.
+func analyzeOrderByParamField(a *analysis, name string) *OrderByParamField {
+	st, ok := a.named.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		fvar := st.Field(i)
+		if fvar.Name() != name || !isAccessible(a, fvar, a.named) {
+			continue
+		}
+
+		param := new(OrderByParamField)
+		param.Name = fvar.Name()
+		param.Type, _ = analyzeTypeInfo(a, fvar.Type())
+		a.info.FieldMap[param] = FieldVar{Var: fvar, Tag: st.Tag(i)}
+		return param
+	}
+	return nil
+}
+
 // analyzeOverrideDirective
 func analyzeOverrideDirective(a *analysis, f *types.Var, tag string) (err error) {
 	if a.query.Kind != QueryKindInsert {
@@ -1737,6 +2009,136 @@ func analyzeOptionalDirective(a *analysis, f *types.Var, tag string) error {
 	return nil
 }
 
+func analyzeWithDeletedDirective(a *analysis, f *types.Var, tag string) error {
+	if !a.query.Kind.isSelect() && a.query.Kind != QueryKindDelete {
+		return a.error(errIllegalQueryField, f, "", tag, "", "")
+	}
+	if a.query.WithDeleted != nil {
+		return a.error(errConflictingFieldOrDirective, f, "", tag, "", "")
+	}
+
+	a.query.WithDeleted = new(WithDeletedDirective)
+	a.info.FieldMap[a.query.WithDeleted] = FieldVar{Var: f, Tag: tag}
+	return nil
+}
+
+// analyzeSoftDeleteDirective analyzes the given field and its tag as the
+// gosql.SoftDelete directive and sets the result to the given RelType.
+func analyzeSoftDeleteDirective(a *analysis, rt *RelType, f *types.Var, tag string) error {
+	if rt.SoftDelete != nil {
+		return a.error(errConflictingFieldOrDirective, f, "", tag, "", "")
+	}
+
+	tval := tagutil.New(tag).First("sql")
+	tval = strings.ToLower(strings.TrimSpace(tval))
+	if len(tval) == 0 {
+		return a.error(errMissingTagValue, f, "", tag, "", "")
+	}
+
+	cid, ecode, eval := parseColIdent(a, tval)
+	if ecode > 0 {
+		return a.error(ecode, f, "", tag, tval, eval)
+	}
+
+	rt.SoftDelete = new(SoftDeleteDirective)
+	rt.SoftDelete.ColIdent = cid
+	rt.FieldMap[rt.SoftDelete] = FieldVar{Var: f, Tag: tag}
+	return nil
+}
+
+// analyzeAutoNowReturn ensures that the columns of the fields tagged with the
+// "autonow" option, that apply to the query's kind, are returned by the query.
+// If the query has no gosql.Return directive, one is added implicitly, and if
+// it has one that lists specific columns the missing columns are appended to it.
+//
+// The columns are not returned if the query has a result field or
+// a gosql.RowsAffected directive.
+func analyzeAutoNowReturn(a *analysis) {
+	if !a.query.IsInsertOrUpdate() || a.query.Result != nil || a.query.RowsAffected != nil {
+		return
+	}
+
+	kind := AutoNowInsert
+	if a.query.Kind == QueryKindUpdate {
+		kind = AutoNowUpdate
+	}
+
+	for _, f := range a.query.Rel.Type.Fields {
+		if !f.AutoNow.Has(kind) {
+			continue
+		}
+
+		if a.query.Return == nil {
+			a.query.Return = new(ReturnDirective)
+			a.info.FieldMap[a.query.Return] = a.info.FieldMap[f]
+		}
+		if !a.query.Return.Contains(f.ColIdent) {
+			a.query.Return.Items = append(a.query.Return.Items, f.ColIdent)
+		}
+	}
+}
+
+// analyzeSoftDeletePolicy resolves the soft-delete policy of the query's target
+// relation and of the relations joined by the query. The policy of the target
+// relation is taken from its type's gosql.SoftDelete directive or, if absent,
+// from the config, the policy of the joined relations is taken only from the config.
+//
+// The policy is applied only to select and delete queries and only if the
+// query does not have the gosql.WithDeleted directive.
+func analyzeSoftDeletePolicy(a *analysis) {
+	if (!a.query.Kind.isSelect() && a.query.Kind != QueryKindDelete) || a.query.WithDeleted != nil {
+		return
+	}
+
+	rel := a.query.Rel
+	if rel.Type.SoftDelete != nil {
+		a.query.SoftDelete = new(SoftDeleteDirective)
+		a.query.SoftDelete.ColIdent = rel.Type.SoftDelete.ColIdent
+		a.info.FieldMap[a.query.SoftDelete] = a.info.FieldMap[rel.Type.SoftDelete]
+	} else if col := softDeleteColumn(a, rel.Id); len(col) > 0 {
+		a.query.SoftDelete = new(SoftDeleteDirective)
+		a.query.SoftDelete.ColIdent = ColIdent{Name: col}
+		a.info.FieldMap[a.query.SoftDelete] = a.info.FieldMap[rel]
+	}
+
+	// Qualify the column with the alias of the target relation to avoid
+	// ambiguity in case the query joins relations with the same column.
+	if a.query.SoftDelete != nil && len(a.query.SoftDelete.Qualifier) == 0 {
+		a.query.SoftDelete.Qualifier = rel.Id.Alias
+	}
+
+	if a.query.Join == nil {
+		return
+	}
+	for _, dir := range a.query.Join.Directives {
+		if dir.JoinType == JoinTypeCross {
+			continue
+		}
+		if col := softDeleteColumn(a, dir.RelIdent); len(col) > 0 {
+			qual := dir.RelIdent.Alias
+			if len(qual) == 0 {
+				qual = dir.RelIdent.Name
+			}
+
+			dir.SoftDelete = new(SoftDeleteDirective)
+			dir.SoftDelete.ColIdent = ColIdent{Name: col, Qualifier: qual}
+			a.info.FieldMap[dir.SoftDelete] = a.info.FieldMap[dir]
+		}
+	}
+}
+
+// softDeleteColumn returns the name of the soft-delete column that's
+// configured for the relation identified by the given RelIdent, or empty.
+func softDeleteColumn(a *analysis, id RelIdent) string {
+	for _, sd := range a.cfg.SoftDeleteRelations {
+		if sd.Relation.Value == id.Name || sd.Relation.Value == id.QualifiedName() ||
+			(len(id.Qualifier) == 0 && sd.Relation.Value == "public."+id.Name) {
+			return tolower(sd.Column.Value)
+		}
+	}
+	return ""
+}
+
 // analyzeTextSearchDirective analyzes the given field and its tag as the gosql.TextSearch
 // directive and sets the result to the given analysis' filter.
 func analyzeTextSearchDirective(a *analysis, f *types.Var, tag string) error {
@@ -1807,6 +2209,39 @@ func analyzeTypeKind(typ types.Type) TypeKind {
 // Parsers
 //
 
+// parsePredicate returns the predicate denoted by the given string. A string
+// of operator characters that does not denote one of the predefined predicates
+// is returned as the name of a custom operator together with IsOperator.
+func parsePredicate(op string) (pred Predicate, oper string) {
+	if pred, ok := stringToPredicate[op]; ok {
+		return pred, ""
+	}
+	if len(op) > 0 && isOperatorChar(op[0]) {
+		return IsOperator, op
+	}
+	return 0, ""
+}
+
+// isOperatorChar reports whether or not c is one of the characters
+// from which PostgreSQL operator names are composed.
+func isOperatorChar(c byte) bool {
+	return strings.IndexByte("+-*/<>=~!@#%^&|`?", c) > -1
+}
+
+// trimOperator trims the trailing "+" and "-" characters from the given
+// multi-character operator unless it contains at least one of the characters
+// "~!@#%^&|`?", which is how PostgreSQL splits, for example, "=-1" into
+// the "=" operator followed by "-1".
+func trimOperator(op string) string {
+	if strings.ContainsAny(op, "~!@#%^&|`?") {
+		return op
+	}
+	for len(op) > 1 && (op[len(op)-1] == '+' || op[len(op)-1] == '-') {
+		op = op[:len(op)-1]
+	}
+	return op
+}
+
 // parsePredicateExpr parses the given string as a predicate expression and
 // returns the individual elements of that expression. The expected format is:
 // { column [ predicate-type [ quantifier ] { column | literal } ] }
@@ -1814,34 +2249,19 @@ func parsePredicateExpr(expr string) (lhs, cop, qua, rhs string) {
 	expr = strings.TrimSpace(expr)
 
 	for i := range expr {
-		switch expr[i] {
-		case '=': // =
-			lhs, cop, rhs = expr[:i], expr[i:i+1], expr[i+1:]
-		case '!': // !=, !~, !~*
-			if len(expr[i:]) > 2 && (expr[i+1] == '~' && expr[i+2] == '*') {
-				lhs, cop, rhs = expr[:i], expr[i:i+3], expr[i+3:]
-			} else if len(expr[i:]) > 1 && (expr[i+1] == '=' || expr[i+1] == '~') {
-				lhs, cop, rhs = expr[:i], expr[i:i+2], expr[i+2:]
-			}
-		case '<': // <, <=, <>
-			if len(expr[i:]) > 1 && (expr[i+1] == '=' || expr[i+1] == '>') {
-				lhs, cop, rhs = expr[:i], expr[i:i+2], expr[i+2:]
-			} else {
-				lhs, cop, rhs = expr[:i], expr[i:i+1], expr[i+1:]
-			}
-		case '>': // >, >=
-			if len(expr[i:]) > 1 && expr[i+1] == '=' {
-				lhs, cop, rhs = expr[:i], expr[i:i+2], expr[i+2:]
-			} else {
-				lhs, cop, rhs = expr[:i], expr[i:i+1], expr[i+1:]
-			}
-		case '~': // ~, ~*
-			if len(expr[i:]) > 1 && expr[i+1] == '*' {
-				lhs, cop, rhs = expr[:i], expr[i:i+2], expr[i+2:]
-			} else {
-				lhs, cop, rhs = expr[:i], expr[i:i+1], expr[i+1:]
+		switch c := expr[i]; {
+		case isOperatorChar(c):
+			// The operator is the longest sequence of operator characters
+			// that satisfies PostgreSQL's lexical rules for operators, this
+			// covers the predefined predicates (=, <>, !~*, etc.) as well
+			// as any custom operator (<->, @>, %, etc.).
+			j := i + 1
+			for j < len(expr) && isOperatorChar(expr[j]) {
+				j++
 			}
-		case ' ':
+			op := trimOperator(expr[i:j])
+			lhs, cop, rhs = expr[:i], op, expr[i+len(op):]
+		case c == ' ':
 
 			var (
 				j     = i + 1
@@ -2002,6 +2422,178 @@ func parseCoalesceInfo(tag tagutil.Tag) (use bool, val string) {
 	return use, val
 }
 
+// exprFieldAlias returns the alias under which the result of an expression
+// field's SQL expression is selected. The alias is the snake_case version of
+// the field's name prefixed with the names of its parent fields, if any.
+func exprFieldAlias(selector []*FieldSelectorNode, name string) string {
+	names := make([]string, 0, len(selector)+1)
+	for _, node := range selector {
+		names = append(names, node.Name)
+	}
+	names = append(names, name)
+
+	var b strings.Builder
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+
+		rs := []rune(n)
+		for j, r := range rs {
+			if unicode.IsUpper(r) {
+				if j > 0 && (unicode.IsLower(rs[j-1]) || unicode.IsDigit(rs[j-1]) ||
+					(j+1 < len(rs) && unicode.IsLower(rs[j+1]))) {
+					b.WriteByte('_')
+				}
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// windowFuncs is the set of supported window functions, the value
+// indicates whether or not the function requires a column argument.
+var windowFuncs = map[string]bool{
+	"row_number":   false,
+	"rank":         false,
+	"dense_rank":   false,
+	"percent_rank": false,
+	"cume_dist":    false,
+	"count":        false,
+	"lag":          true,
+	"lead":         true,
+	"first_value":  true,
+	"last_value":   true,
+}
+
+// parseWindowFunc parses the window function call declared by the given tag.
+// The first value of the `sql` tag is expected to have the format "@name" or
+// "@name(column_ident)", and the window definition is taken from the optional
+// "over" option which has the format "over[:key=value[;key=value...]]" where
+// key is either "partition" or "order". The keys can be repeated, the value
+// of "partition" is a column identifier and the value of "order" is a column
+// identifier in the format used by the gosql.OrderBy directive.
+func parseWindowFunc(a *analysis, tag tagutil.Tag) (win *WindowFunc, ecode errorCode, eval string) {
+	sqltag := tag["sql"]
+
+	win = new(WindowFunc)
+	win.Name = strings.TrimPrefix(sqltag[0], "@")
+
+	var arg string
+	if i := strings.IndexByte(win.Name, '('); i > -1 {
+		if !strings.HasSuffix(win.Name, ")") {
+			return nil, errBadWindowTagValue, sqltag[0]
+		}
+		win.Name, arg = win.Name[:i], strings.TrimSpace(win.Name[i+1:len(win.Name)-1])
+	}
+	win.Name = strings.ToLower(win.Name)
+
+	needsArg, ok := windowFuncs[win.Name]
+	if !ok || (needsArg && (len(arg) == 0 || arg == "*")) {
+		return nil, errBadWindowTagValue, sqltag[0]
+	}
+	if len(arg) > 0 && arg != "*" {
+		cid, ecode, eval := parseColIdent(a, arg)
+		if ecode > 0 {
+			return nil, ecode, eval
+		}
+		win.Arg = cid
+	}
+
+	for _, opt := range sqltag[1:] {
+		if opt == "over" || !strings.HasPrefix(opt, "over:") {
+			continue
+		}
+
+		for _, clause := range strings.Split(opt[5:], ";") {
+			i := strings.IndexByte(clause, '=')
+			if i < 0 {
+				return nil, errBadWindowTagValue, clause
+			}
+
+			key, val := clause[:i], clause[i+1:]
+			switch key {
+			case "partition":
+				cid, ecode, eval := parseColIdent(a, val)
+				if ecode > 0 {
+					return nil, ecode, eval
+				}
+				win.Partition = append(win.Partition, cid)
+			case "order":
+				var item OrderByTagItem
+				if len(val) > 0 && val[0] == '-' {
+					item.Direction = OrderDesc
+					val = val[1:]
+				}
+				if j := strings.Index(val, ":"); j > -1 {
+					if val[j+1:] == "nullsfirst" {
+						item.Nulls = NullsFirst
+					} else if val[j+1:] == "nullslast" {
+						item.Nulls = NullsLast
+					} else {
+						return nil, errBadNullsOrderTagValue, val[j+1:]
+					}
+					val = val[:j]
+				}
+
+				cid, ecode, eval := parseColIdent(a, val)
+				if ecode > 0 {
+					return nil, ecode, eval
+				}
+				item.ColIdent = cid
+				win.OrderBy = append(win.OrderBy, item)
+			default:
+				return nil, errBadWindowTagValue, clause
+			}
+		}
+	}
+	return win, 0, ""
+}
+
+// parseAutoNowInfo parses the "autonow" option from the given tag. The option
+// is expected to have the format "autonow[=kind[:func]]" where kind is one of
+// "insert" or "update", and func is the name of the SQL function that produces
+// the current timestamp. If the option is malformed the invalid value is
+// returned as the last result.
+func parseAutoNowInfo(tag tagutil.Tag) (kind AutoNowKind, fn string, eval string) {
+	sqltag := tag["sql"]
+	if len(sqltag) == 0 {
+		return 0, "", ""
+	}
+
+	for _, opt := range sqltag[1:] {
+		if opt != "autonow" && !strings.HasPrefix(opt, "autonow=") {
+			continue
+		}
+
+		kind, fn = AutoNowAlways, "now"
+		if i := strings.IndexByte(opt, '='); i > -1 {
+			val := opt[i+1:]
+			if j := strings.IndexByte(val, ':'); j > -1 {
+				val, fn = val[:j], strings.ToLower(val[j+1:])
+			}
+
+			switch strings.ToLower(val) {
+			case "insert":
+				kind = AutoNowInsert
+			case "update":
+				kind = AutoNowUpdate
+			default:
+				return 0, "", opt
+			}
+		}
+
+		switch fn {
+		case "now", "clock_timestamp", "statement_timestamp", "transaction_timestamp":
+			return kind, fn, ""
+		}
+		return 0, "", opt
+	}
+	return 0, "", ""
+}
+
 func parseFuncName(tagvals []string) FuncName {
 	for _, v := range tagvals {
 		if len(v) > 0 && v[0] == '@' {
@@ -117,6 +117,27 @@ func TestAnalysis_queryStruct(t *testing.T) {
 	reltypeTs := makeReltypeT()
 	reltypeTs.IsSlice = true
 	reltypeA0 := RelType{Base: TypeInfo{Kind: TypeKindStruct}} // anon empty
+	reltypeSoftDelete := RelType{
+		Base: TypeInfo{
+			Name:     "softDeleteRecord",
+			Kind:     TypeKindStruct,
+			PkgPath:  "path/to/test",
+			PkgName:  "testdata",
+			PkgLocal: "testdata",
+		},
+		Fields: []*FieldInfo{{
+			Name:            "Id",
+			Type:            TypeInfo{Kind: TypeKindInt},
+			ColIdent:        ColIdent{Name: "id"},
+			FilterColumnKey: "Id",
+			Tag:             tagutil.Tag{"sql": {"id"}},
+			IsExported:      true,
+			Mode:            mode_default,
+		}},
+		IsSlice:    true,
+		IsPointer:  true,
+		SoftDelete: &SoftDeleteDirective{ColIdent{Name: "deleted_at"}},
+	}
 	notstructs := makeReltypeNS()
 	notstructs.IsPointer = true
 	notstructs.IsSlice = true
@@ -2171,6 +2192,147 @@ func TestAnalysis_queryStruct(t *testing.T) {
 			FileName:      "../testdata/analysis_bad.go",
 			FileLine:      980,
 		},
+	}, {
+		Name: "InsertAnalysisTestBAD_WithDeleted",
+		err: &anError{
+			Code:          errIllegalQueryField,
+			PkgPath:       "path/to/test",
+			TargetName:    "InsertAnalysisTestBAD_WithDeleted",
+			RelType:       reltypeT,
+			RelField:      "Rel",
+			FieldType:     "github.com/frk/gosql.WithDeleted",
+			FieldTypeKind: "struct",
+			FieldName:     "_",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1000,
+		},
+	}, {
+		Name: "InsertAnalysisTestBAD_BadAutoNowOption",
+		err: &anError{
+			Code:          errBadAutoNowTagValue,
+			PkgPath:       "path/to/test",
+			TargetName:    "InsertAnalysisTestBAD_BadAutoNowOption",
+			RelType:       reltypeA0,
+			RelField:      "Rel",
+			BlockName:     "",
+			FieldType:     "string",
+			FieldTypeKind: "string",
+			FieldName:     "Foo",
+			TagString:     `sql:"foo,autonow=delete"`,
+			TagError:      "autonow=delete",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1006,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_EmptyFieldExpr",
+		err: &anError{
+			Code:          errMissingTagValue,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_EmptyFieldExpr",
+			RelType:       reltypeA0,
+			RelField:      "Rel",
+			FieldType:     "string",
+			FieldTypeKind: "string",
+			FieldName:     "Foo",
+			TagString:     `sql:"="`,
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1013,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_BadWindowFunc",
+		err: &anError{
+			Code:          errBadWindowTagValue,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_BadWindowFunc",
+			RelType:       reltypeA0,
+			RelField:      "Rel",
+			FieldType:     "int64",
+			FieldTypeKind: "int64",
+			FieldName:     "Foo",
+			TagString:     `sql:"@sum(a.foo),over"`,
+			TagError:      "@sum(a.foo)",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1020,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_IllegalTotalCountField",
+		err: &anError{
+			Code:          errIllegalQueryField,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_IllegalTotalCountField",
+			RelType:       reltypeT,
+			RelField:      "Rel",
+			FieldType:     "int",
+			FieldTypeKind: "int",
+			FieldName:     "TotalCount",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1027,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_BadManyFieldType",
+		err: &anError{
+			Code:          errBadManyFieldType,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_BadManyFieldType",
+			RelType:       reltypeA0,
+			RelField:      "Rel",
+			FieldType:     "path/to/test.T",
+			FieldTypeKind: "struct",
+			FieldName:     "Foo",
+			TagString:     `sql:"relation_b:b,many,b.a_id = a.id"`,
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1033,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_BadOrderByDirectiveOperatorParam",
+		err: &anError{
+			Code:          errBadOrderByTagValue,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_BadOrderByDirectiveOperatorParam",
+			RelType:       reltypeTs,
+			RelField:      "Rel",
+			FieldType:     "github.com/frk/gosql.OrderBy",
+			FieldTypeKind: "struct",
+			FieldName:     "_",
+			TagString:     `sql:"a.location <-> $Point"`,
+			TagError:      "a.location <-> $Point",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1040,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_IllegalOperatorModifier",
+		err: &anError{
+			Code:          errIllegalOperatorModifier,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_IllegalOperatorModifier",
+			RelType:       reltypeTs,
+			RelField:      "Rel",
+			BlockName:     "Where",
+			FieldType:     "string",
+			FieldTypeKind: "string",
+			FieldName:     "Name",
+			TagString:     `sql:"a.name %,@lower"`,
+			TagExpr:       "a.name %",
+			TagError:      "lower",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1047,
+		},
+	}, {
+		Name: "SelectAnalysisTestBAD_BadWindowFuncStarArg",
+		err: &anError{
+			Code:          errBadWindowTagValue,
+			PkgPath:       "path/to/test",
+			TargetName:    "SelectAnalysisTestBAD_BadWindowFuncStarArg",
+			RelType:       reltypeA0,
+			RelField:      "Rel",
+			FieldType:     "int64",
+			FieldTypeKind: "int64",
+			FieldName:     "Foo",
+			TagString:     `sql:"@lag(*),over"`,
+			TagError:      "@lag(*)",
+			FileName:      "../testdata/analysis_bad.go",
+			FileLine:      1054,
+		},
 	}, {
 		Name: "InsertAnalysisTestOK1",
 		want: &QueryStruct{
@@ -3903,6 +4065,434 @@ func TestAnalysis_queryStruct(t *testing.T) {
 			},
 			Context: &ContextField{Name: "Ctx"},
 		},
+	}, {
+		Name: "SelectAnalysisTestOK_SoftDelete",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_SoftDelete",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type:      reltypeSoftDelete,
+			},
+			SoftDelete: &SoftDeleteDirective{ColIdent{Name: "deleted_at", Qualifier: "a"}},
+		},
+	}, {
+		Name: "DeleteAnalysisTestOK_WithDeleted",
+		want: &QueryStruct{
+			TypeName: "DeleteAnalysisTestOK_WithDeleted",
+			Kind:     QueryKindDelete,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type:      reltypeSoftDelete,
+			},
+			All:         &AllDirective{},
+			WithDeleted: &WithDeletedDirective{},
+		},
+	}, {
+		Name: "UpdateAnalysisTestOK_AutoNow",
+		want: &QueryStruct{
+			TypeName: "UpdateAnalysisTestOK_AutoNow",
+			Kind:     QueryKindUpdate,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type: RelType{
+					Base: TypeInfo{
+						Name:     "autoNowRecord",
+						Kind:     TypeKindStruct,
+						PkgPath:  "path/to/test",
+						PkgName:  "testdata",
+						PkgLocal: "testdata",
+					},
+					Fields: []*FieldInfo{{
+						Name:            "Id",
+						Type:            TypeInfo{Kind: TypeKindInt},
+						ColIdent:        ColIdent{Name: "id"},
+						FilterColumnKey: "Id",
+						Tag:             tagutil.Tag{"sql": {"id", "pk"}},
+						IsExported:      true,
+						Mode:            mode_default,
+					}, {
+						Name: "CreatedAt",
+						Type: TypeInfo{
+							Name:              "Time",
+							Kind:              TypeKindStruct,
+							PkgPath:           "time",
+							PkgName:           "time",
+							PkgLocal:          "time",
+							IsImported:        true,
+							IsJSONMarshaler:   true,
+							IsJSONUnmarshaler: true,
+						},
+						ColIdent:        ColIdent{Name: "created_at"},
+						FilterColumnKey: "CreatedAt",
+						Tag:             tagutil.Tag{"sql": {"created_at", "autonow=insert"}},
+						IsExported:      true,
+						Mode:            mode_default,
+						AutoNow:         AutoNowInsert,
+						AutoNowFunc:     "now",
+					}, {
+						Name: "UpdatedAt",
+						Type: TypeInfo{
+							Name:              "Time",
+							Kind:              TypeKindStruct,
+							PkgPath:           "time",
+							PkgName:           "time",
+							PkgLocal:          "time",
+							IsImported:        true,
+							IsJSONMarshaler:   true,
+							IsJSONUnmarshaler: true,
+						},
+						ColIdent:        ColIdent{Name: "updated_at"},
+						FilterColumnKey: "UpdatedAt",
+						Tag:             tagutil.Tag{"sql": {"updated_at", "autonow=update:clock_timestamp"}},
+						IsExported:      true,
+						Mode:            mode_default,
+						AutoNow:         AutoNowUpdate,
+						AutoNowFunc:     "clock_timestamp",
+					}},
+					IsPointer: true,
+				},
+			},
+			Return: &ReturnDirective{ColIdentList{
+				Items: []ColIdent{{Name: "updated_at"}},
+			}},
+		},
+	}, {
+		Name: "SelectAnalysisTestOK_ExprFields",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_ExprFields",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type: RelType{
+					Base: TypeInfo{Kind: TypeKindStruct},
+					Fields: []*FieldInfo{{
+						Name:            "Id",
+						Type:            TypeInfo{Kind: TypeKindInt},
+						ColIdent:        ColIdent{Name: "id"},
+						FilterColumnKey: "Id",
+						Tag:             tagutil.Tag{"sql": {"id"}},
+						IsExported:      true,
+						Mode:            mode_default,
+					}, {
+						Name:       "EmailLower",
+						Type:       TypeInfo{Kind: TypeKindString},
+						ColIdent:   ColIdent{Name: "email_lower"},
+						Expr:       "lower(a.email)",
+						Tag:        tagutil.Tag{"sql": {"=lower(a.email)"}},
+						IsExported: true,
+						Mode:       mode_select,
+					}, {
+						Name:       "FullName",
+						Type:       TypeInfo{Kind: TypeKindString},
+						ColIdent:   ColIdent{Name: "full_name"},
+						Expr:       "concat_ws(' ', a.first_name, a.last_name)",
+						Tag:        tagutil.Tag{"sql": {"=concat_ws(' '", " a.first_name", " a.last_name)"}},
+						IsExported: true,
+						Mode:       mode_select,
+					}},
+				},
+			},
+		},
+	}, {
+		Name: "SelectAnalysisTestOK_WindowFields",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_WindowFields",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type: RelType{
+					Base: TypeInfo{
+						Name:     "windowRecord",
+						Kind:     TypeKindStruct,
+						PkgPath:  "path/to/test",
+						PkgName:  "testdata",
+						PkgLocal: "testdata",
+					},
+					Fields: []*FieldInfo{{
+						Name:            "Id",
+						Type:            TypeInfo{Kind: TypeKindInt},
+						ColIdent:        ColIdent{Name: "id"},
+						FilterColumnKey: "Id",
+						Tag:             tagutil.Tag{"sql": {"id"}},
+						IsExported:      true,
+						Mode:            mode_default,
+					}, {
+						Name:     "RowNumber",
+						Type:     TypeInfo{Kind: TypeKindInt64},
+						ColIdent: ColIdent{Name: "row_number"},
+						Window: &WindowFunc{
+							Name:      "row_number",
+							Partition: []ColIdent{{Name: "org_id", Qualifier: "a"}},
+							OrderBy: []OrderByTagItem{{
+								ColIdent:  ColIdent{Name: "created_at", Qualifier: "a"},
+								Direction: OrderDesc,
+							}},
+						},
+						Tag:        tagutil.Tag{"sql": {"@row_number", "over:partition=a.org_id;order=-a.created_at"}},
+						IsExported: true,
+						Mode:       mode_select,
+					}, {
+						Name: "PrevId",
+						Type: TypeInfo{
+							Kind: TypeKindPtr,
+							Elem: &TypeInfo{Kind: TypeKindInt},
+						},
+						ColIdent: ColIdent{Name: "prev_id"},
+						Window: &WindowFunc{
+							Name: "lag",
+							Arg:  ColIdent{Name: "id", Qualifier: "a"},
+						},
+						Tag:        tagutil.Tag{"sql": {"@lag(a.id)", "over"}},
+						IsExported: true,
+						Mode:       mode_select,
+					}},
+					IsSlice:   true,
+					IsPointer: true,
+				},
+			},
+			TotalCount: &TotalCountField{Name: "TotalCount", TypeKind: TypeKindInt},
+		},
+	}, {
+		Name: "SelectAnalysisTestOK_ManyFields",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_ManyFields",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type: RelType{
+					Base: TypeInfo{
+						Name:     "manyRecord",
+						Kind:     TypeKindStruct,
+						PkgPath:  "path/to/test",
+						PkgName:  "testdata",
+						PkgLocal: "testdata",
+					},
+					Fields: []*FieldInfo{{
+						Name:            "Id",
+						Type:            TypeInfo{Kind: TypeKindInt},
+						ColIdent:        ColIdent{Name: "id"},
+						FilterColumnKey: "Id",
+						Tag:             tagutil.Tag{"sql": {"id"}},
+						IsExported:      true,
+						Mode:            mode_default,
+					}, {
+						Name: "Children",
+						Type: TypeInfo{
+							Kind: TypeKindSlice,
+							Elem: &TypeInfo{
+								Kind: TypeKindPtr,
+								Elem: &TypeInfo{
+									Name:     "manyChildRecord",
+									Kind:     TypeKindStruct,
+									PkgPath:  "path/to/test",
+									PkgName:  "testdata",
+									PkgLocal: "testdata",
+								},
+							},
+						},
+						ColIdent: ColIdent{Name: "children"},
+						Many: &ManyRelation{
+							RelIdent: RelIdent{Name: "relation_b", Alias: "b"},
+							Type: RelType{
+								Base: TypeInfo{
+									Name:     "manyChildRecord",
+									Kind:     TypeKindStruct,
+									PkgPath:  "path/to/test",
+									PkgName:  "testdata",
+									PkgLocal: "testdata",
+								},
+								Fields: []*FieldInfo{{
+									Name:            "Id",
+									Type:            TypeInfo{Kind: TypeKindInt},
+									ColIdent:        ColIdent{Name: "id"},
+									FilterColumnKey: "Id",
+									Tag:             tagutil.Tag{"sql": {"id"}},
+									IsExported:      true,
+									Mode:            mode_default,
+								}, {
+									Name:            "Name",
+									Type:            TypeInfo{Kind: TypeKindString},
+									ColIdent:        ColIdent{Name: "name"},
+									FilterColumnKey: "Name",
+									Tag:             tagutil.Tag{"sql": {"name"}},
+									IsExported:      true,
+									Mode:            mode_default,
+								}},
+								SoftDelete: &SoftDeleteDirective{ColIdent{Name: "deleted_at"}},
+								IsSlice:    true,
+								IsPointer:  true,
+							},
+							TagItems: []JoinTagItem{
+								&JoinConditionTagItem{
+									LHSColIdent: ColIdent{Name: "a_id", Qualifier: "b"},
+									RHSColIdent: ColIdent{Name: "id", Qualifier: "a"},
+									Predicate:   IsEQ,
+								},
+							},
+							SoftDelete: &SoftDeleteDirective{ColIdent{Name: "deleted_at", Qualifier: "b"}},
+						},
+						Tag:        tagutil.Tag{"sql": {"relation_b:b", "many", "b.a_id = a.id"}},
+						IsExported: true,
+						Mode:       mode_select,
+					}},
+					IsSlice:   true,
+					IsPointer: true,
+				},
+			},
+		},
+	}, {
+		Name: "SelectAnalysisTestOK_CompositeFields",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_CompositeFields",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type: RelType{
+					Base: TypeInfo{
+						Name:     "compositeRecord",
+						Kind:     TypeKindStruct,
+						PkgPath:  "path/to/test",
+						PkgName:  "testdata",
+						PkgLocal: "testdata",
+					},
+					Fields: []*FieldInfo{{
+						Name: "Address",
+						Type: TypeInfo{
+							Kind: TypeKindPtr,
+							Elem: &TypeInfo{
+								Name:     "compositeAddress",
+								Kind:     TypeKindStruct,
+								PkgPath:  "path/to/test",
+								PkgName:  "testdata",
+								PkgLocal: "testdata",
+							},
+						},
+						ColIdent:        ColIdent{Name: "address"},
+						FilterColumnKey: "Address",
+						Tag:             tagutil.Tag{"sql": {"address"}},
+						IsExported:      true,
+						Mode:            mode_default,
+						Composite: []*CompositeField{
+							{Name: "Street", Type: TypeInfo{Kind: TypeKindString}, Attr: "street"},
+							{Name: "Zip", Type: TypeInfo{Kind: TypeKindPtr, Elem: &TypeInfo{Kind: TypeKindInt}}, Attr: "zip"},
+						},
+					}, {
+						Name: "Addresses",
+						Type: TypeInfo{
+							Kind: TypeKindSlice,
+							Elem: &TypeInfo{
+								Name:     "compositeAddress",
+								Kind:     TypeKindStruct,
+								PkgPath:  "path/to/test",
+								PkgName:  "testdata",
+								PkgLocal: "testdata",
+							},
+						},
+						ColIdent:        ColIdent{Name: "addresses"},
+						FilterColumnKey: "Addresses",
+						Tag:             tagutil.Tag{"sql": {"addresses"}},
+						IsExported:      true,
+						Mode:            mode_default,
+						Composite: []*CompositeField{
+							{Name: "Street", Type: TypeInfo{Kind: TypeKindString}, Attr: "street"},
+							{Name: "Zip", Type: TypeInfo{Kind: TypeKindPtr, Elem: &TypeInfo{Kind: TypeKindInt}}, Attr: "zip"},
+						},
+					}, {
+						Name: "CreatedAt",
+						Type: TypeInfo{
+							Name:              "Time",
+							Kind:              TypeKindStruct,
+							PkgPath:           "time",
+							PkgName:           "time",
+							PkgLocal:          "time",
+							IsImported:        true,
+							IsJSONMarshaler:   true,
+							IsJSONUnmarshaler: true,
+						},
+						ColIdent:        ColIdent{Name: "created_at"},
+						FilterColumnKey: "CreatedAt",
+						Tag:             tagutil.Tag{"sql": {"created_at"}},
+						IsExported:      true,
+						Mode:            mode_default,
+					}},
+					IsSlice:   true,
+					IsPointer: true,
+				},
+			},
+		},
+	}, {
+		Name: "SelectAnalysisTestOK_CustomOperators",
+		want: &QueryStruct{
+			TypeName: "SelectAnalysisTestOK_CustomOperators",
+			Kind:     QueryKindSelect,
+			Rel: &RelField{
+				FieldName: "Rel",
+				Id:        RelIdent{Name: "relation_a", Alias: "a"},
+				Type:      reltypeTs,
+			},
+			Join: &JoinStruct{
+				FieldName: "Join",
+				Directives: []*JoinDirective{{
+					JoinType: JoinTypeLeft,
+					RelIdent: RelIdent{Name: "relation_b", Alias: "b"},
+					TagItems: []JoinTagItem{
+						&JoinConditionTagItem{
+							LHSColIdent: ColIdent{Qualifier: "b", Name: "tags"},
+							RHSColIdent: ColIdent{Qualifier: "a", Name: "tags"},
+							Predicate:   IsOperator,
+							Operator:    "&&",
+						},
+					},
+				}},
+			},
+			Where: &WhereStruct{FieldName: "Where", Items: []WhereItem{
+				&WhereStructField{
+					Name:      "Tags",
+					Type:      TypeInfo{Kind: TypeKindSlice, Elem: &TypeInfo{Kind: TypeKindString}},
+					ColIdent:  ColIdent{Qualifier: "a", Name: "tags"},
+					Predicate: IsOperator,
+					Operator:  "@>",
+				},
+				&WhereBoolTag{BoolAnd},
+				&WhereStructField{
+					Name:      "Name",
+					Type:      TypeInfo{Kind: TypeKindString},
+					ColIdent:  ColIdent{Qualifier: "a", Name: "name"},
+					Predicate: IsOperator,
+					Operator:  "%",
+				},
+				&WhereBoolTag{BoolAnd},
+				&WhereColumnDirective{
+					LHSColIdent: ColIdent{Qualifier: "a", Name: "name"},
+					RHSLiteral:  "'foo%'",
+					Predicate:   IsOperator,
+					Operator:    "~~*",
+				},
+				&WhereBoolTag{BoolAnd},
+				&WhereColumnDirective{
+					LHSColIdent: ColIdent{Qualifier: "a", Name: "num"},
+					RHSLiteral:  "-1",
+					Predicate:   IsEQ,
+				},
+			}},
+			OrderBy: &OrderByDirective{Items: []OrderByTagItem{{
+				ColIdent:  ColIdent{Qualifier: "a", Name: "location"},
+				Direction: OrderDesc,
+				Nulls:     NullsFirst,
+				Operator:  "<->",
+				Param:     &OrderByParamField{Name: "Point", Type: TypeInfo{Kind: TypeKindString}},
+			}, {
+				ColIdent: ColIdent{Qualifier: "a", Name: "id"},
+			}}},
+		},
 	}}
 
 	for _, tt := range tests {
@@ -215,6 +215,22 @@ const (
 	OverridingUser                         // OVERRIDING USER VALUE
 )
 
+// AutoNowKind indicates the kinds of queries for which
+// a field tagged with the "autonow" option is set to now.
+type AutoNowKind uint8
+
+const (
+	AutoNowInsert AutoNowKind = 1 << iota // set on INSERT
+	AutoNowUpdate                         // set on UPDATE
+
+	AutoNowAlways = AutoNowInsert | AutoNowUpdate // set on INSERT and UPDATE
+)
+
+// Has reports whether or not k includes v.
+func (k AutoNowKind) Has(v AutoNowKind) bool {
+	return (k & v) != 0
+}
+
 // boolean operation
 type Boolean uint8
 
@@ -281,6 +297,8 @@ const (
 	NotSimilar // IS NOT SIMILAR TO
 	_pattern_pred_end
 
+	IsOperator // custom binary operator, e.g. <->, @>, %
+
 	_seq_pred_start
 	IsIn  // IN
 	NotIn // NOT IN
@@ -390,8 +408,11 @@ func (p Predicate) IsArray() bool { return _seq_pred_start < p && p < _seq_pred_
 // IsUnary reports whether or not the predicate represents a unary comparison.
 func (p Predicate) IsUnary() bool { return p.IsNull() || p.IsBoolean() }
 
+// IsOperator reports whether or not the predicate represents a custom binary operator.
+func (p Predicate) IsOperator() bool { return p == IsOperator }
+
 // CanQuantify reports whether or not the predicate can be used together with a quantifier.
-func (p Predicate) CanQuantify() bool { return p.IsBinary() || p.IsPatternMatch() }
+func (p Predicate) CanQuantify() bool { return p.IsBinary() || p.IsPatternMatch() || p.IsOperator() }
 
 // IsOneOf reports whether or not p is one of the provided predicates.
 func (p Predicate) IsOneOf(pp ...Predicate) bool {
@@ -203,6 +203,8 @@ const (
 	errBadIterTypeInterface
 	errBadIterTypeFunc
 	errBadRelType
+	errBadManyFieldType
+	errIllegalManyChildField
 	errIllegalQueryField
 	errIllegalStructDirective
 	errIllegalIteratorField
@@ -227,7 +229,10 @@ const (
 	errBadBoolTagValue
 	errBadUIntegerTagValue
 	errBadNullsOrderTagValue
+	errBadOrderByTagValue
 	errBadOverrideTagValue
+	errBadAutoNowTagValue
+	errBadWindowTagValue
 	errBadDirectiveBooleanExpr
 	errBadBetweenPredicate
 	errBadJoinConditionLHS
@@ -236,6 +241,7 @@ const (
 	errIllegalUnaryPredicate
 	errIllegalFieldQuantifier
 	errIllegalPredicateQuantifier
+	errIllegalOperatorModifier
 	errUnknownColumnQualifier
 	errColumnFieldUnknown
 )
@@ -287,6 +293,19 @@ var error_template_string = `
         - A valid {{Wu "iterator func"}} type.
 {{ end }}
 
+{{ define "` + errBadManyFieldType.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Bad \"many\" field type."}}
+    Cannot use {{R .FieldTypeShort}} (kind {{R .FieldTypeKind}}) as the type of the {{Wb .FieldName}} field in {{Wb .TargetName}}.
+    {{Wb "FIX:"}} change the field type to be a {{Ci "slice of named structs"}} or a {{Ci "slice of pointers to named structs"}}.
+{{ end }}
+
+{{ define "` + errIllegalManyChildField.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Illegal field in \"many\" element type."}}
+    The {{R .FieldDefinition}} field {{Wu "CANNOT"}} be declared in the element type of a "{{Wi "many"}}" field.
+    {{Wb "HINT:"}} the element type of a "{{Wi "many"}}" field MAY contain only column and expression fields, ` +
+	`nested struct, window function, and other "{{Wi "many"}}" fields are {{Wu "NOT"}} supported.
+{{ end }}
+
 {{ define "` + errIllegalQueryField.name() + `" -}}
 {{Wb .FileAndLine}}: {{Y "Illegal " .FieldKind "."}}
     The {{Wb .TargetXxx}} {{.TargetKind}} types {{Wu "DO NOT"}} support the {{R .FieldDefinition}} {{.FieldKind}}.
@@ -482,12 +501,36 @@ var error_template_string = `
     {{Wb "HINT:"}} A valid {{Wi "nulls_order"}} value MUST be either {{G "NULLSFIRST"}} or {{G "NULLSLAST"}} (case insensitive).
 {{ end }}
 
+{{ define "` + errBadOrderByTagValue.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Bad order_by_item value in tag."}}
+    The "sql" tag value {{R .TagError}} in {{R .FieldDefinition}} from {{Wb .TargetName}} is an invalid {{Wi "order_by_item"}} value.
+    {{Wb "HINT:"}} A valid {{Wi "order_by_item"}} value MUST have the format {{G "[-]column_ident[:nulls_order]"}} or {{G "[-]column_ident operator $FieldName[:nulls_order]"}}
+          where {{Wi "operator"}} is a binary operator, e.g. {{G "<->"}}, and {{Wi "FieldName"}} is the name of an accessible field of {{Wb .TargetName}}.
+          The second format is supported only by plain {{Wi "select"}} query types.
+{{ end }}
+
 {{ define "` + errBadOverrideTagValue.name() + `" -}}
 {{Wb .FileAndLine}}: {{Y "Bad overriding_kind value in tag."}}
     The "sql" tag value {{R .TagError}} in {{R .FieldDefinition}} from {{Wb .TargetName}} is an invalid {{Wi "overriding_kind"}} value.
     {{Wb "HINT:"}} A valid {{Wi "overriding_kind"}} value MUST be either {{G "USER"}} or {{G "SYSTEM"}} (case insensitive).
 {{ end }}
 
+{{ define "` + errBadAutoNowTagValue.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Bad autonow value in tag."}}
+    The "sql" tag option {{R .TagError}} in {{R .FieldDefinition}} from {{Wb .TargetName}} is an invalid {{Wi "autonow"}} value.
+    {{Wb "HINT:"}} A valid {{Wi "autonow"}} value MUST have the format {{G "autonow[=kind[:func]]"}} where {{Wi "kind"}} is either {{G "insert"}} or {{G "update"}},
+          and {{Wi "func"}} is one of {{G "now"}}, {{G "clock_timestamp"}}, {{G "statement_timestamp"}}, or {{G "transaction_timestamp"}}.
+{{ end }}
+
+{{ define "` + errBadWindowTagValue.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Bad window function in tag."}}
+    The "sql" tag value {{R .TagError}} in {{R .FieldDefinition}} from {{Wb .TargetName}} is an invalid {{Wi "window function"}} value.
+    {{Wb "HINT:"}} A valid {{Wi "window function"}} tag MUST have the format {{G "@name[(column_ident)][,over[:key=value[;key=value...]]]"}}
+          where {{Wi "name"}} is one of {{G "row_number"}}, {{G "rank"}}, {{G "dense_rank"}}, {{G "percent_rank"}}, {{G "cume_dist"}}, {{G "count"}},
+          {{G "lag"}}, {{G "lead"}}, {{G "first_value"}}, or {{G "last_value"}} (the last four require a column argument),
+          and {{Wi "key"}} is either {{G "partition"}} or {{G "order"}}.
+{{ end }}
+
 {{ define "` + errBadDirectiveBooleanExpr.name() + `" -}}
 {{Wb .FileAndLine}}: {{Y "Bad boolean_expression in directive."}}
     The expression "{{R .TagError}}" in {{R .FieldDefinition}} from {{W .TargetName}}{{if .BlockName}}.{{Wb .BlockName}}{{end}} ` +
@@ -557,6 +600,12 @@ var error_template_string = `
     {{end -}}
 {{ end }}
 
+{{ define "` + errIllegalOperatorModifier.name() + `" -}}
+{{Wb .FileAndLine}}: {{Y "Illegal operator modifier."}}
+    The use of a {{Wi "modifier_function"}} together with a custom operator is illegal ({{R .TagError}} in "{{R .TagExpr}}" from the field {{R .FieldDefinition}} in {{W .TargetName}}).
+    {{Wb "FIX:"}} Remove the {{Wi "modifier_function"}} {{R .TagError}} from the tag, or change the operator {{R .TagExprPredicate}} to one of the predefined predicates.
+{{ end }}
+
 {{ define "` + errUnknownColumnQualifier.name() + `" -}}
 {{Wb .FileAndLine}}: {{Y "Unknown column qualifier."}}
     The column qualifier "{{R .TagError}}" in {{Wb .TargetName}} field {{R .FieldDefinition}} references an unknown, as of yet unspecified relation.
@@ -36,6 +36,8 @@ type (
 		Offset *OffsetField
 		// Info on the "rowsaffected" field of the query struct type, or nil.
 		RowsAffected *RowsAffectedField
+		// Info on the "totalcount" field of the query struct type, or nil.
+		TotalCount *TotalCountField
 		// Info on the gosql.Force directive field of the query struct type, or nil.
 		Force *ForceDirective
 		// Info on the gosql.Optional directive field of the query struct type, or nil.
@@ -54,6 +56,12 @@ type (
 		Context *ContextField
 		// Info on the gosql.All directive field of the query struct type, or nil.
 		All *AllDirective
+		// Info on the gosql.WithDeleted directive field of the query struct type, or nil.
+		WithDeleted *WithDeletedDirective
+		// The soft-delete policy of the target relation, declared either by the
+		// gosql.SoftDelete directive of the relation's type or by the config,
+		// or nil. Always nil if the WithDeleted directive is present.
+		SoftDelete *SoftDeleteDirective
 	}
 
 	// FilterStruct represents the result of the analysis of a target "filter" struct type.
@@ -128,6 +136,8 @@ type (
 		IterMethod string
 		// Indicates whether or not the type implements the gosql.AfterScanner interface.
 		IsAfterScanner bool
+		// Info on the gosql.SoftDelete directive field of the type, or nil.
+		SoftDelete *SoftDeleteDirective
 		// fields holds the information on the type's struct fields.
 		Fields []*FieldInfo
 
@@ -198,8 +208,24 @@ type (
 		IsExported bool
 		// The field's parsed tag.
 		Tag tagutil.Tag
-		// The column identifier parsed from the field's `sql` tag.
+		// The column identifier parsed from the field's `sql` tag. If the
+		// field is an expression field then the identifier's name holds
+		// the alias under which the expression's result is selected.
 		ColIdent ColIdent
+		// If set, holds the SQL expression, as parsed from the field's `sql`
+		// tag, whose result is read into the field instead of a column's value.
+		Expr string
+		// If set, holds the window function, as parsed from the field's `sql`
+		// tag, whose result is read into the field instead of a column's value.
+		Window *WindowFunc
+		// If set, holds the child relation, as parsed from a field whose
+		// `sql` tag has the "many" option, whose records are read into the field.
+		Many *ManyRelation
+		// If the field's type is a struct, or a slice or array of structs,
+		// that does not implement the sql.Scanner and driver.Valuer interfaces
+		// then Composite will hold the struct's fields that have an `sql` tag,
+		// these are mapped to the attributes of a composite type column.
+		Composite []*CompositeField
 		// If set, holds key to be used in the filter-column-key map
 		// that's produced by the generator.
 		FilterColumnKey string
@@ -215,6 +241,12 @@ type (
 		UseCoalesce bool
 		// Will hold the "alternative" value as parsed from the "coalesce" option of the field's `sql` tag.
 		CoalesceValue string
+		// If set, indicates that the "autonow" option was used in the field's `sql`
+		// tag and holds the kinds of queries for which the column should be set.
+		AutoNow AutoNowKind
+		// The name of the SQL function that should be used to produce the
+		// "autonow" value, as parsed from the field's `sql` tag.
+		AutoNowFunc string
 	}
 
 	// FieldSelectorNode represents a single node in a nested field's "selector".
@@ -245,6 +277,18 @@ type (
 		// TODO documentation
 		Mode FieldMode
 	}
+
+	// CompositeField is the result of analyzing a field of a struct type
+	// that represents a composite type's row value.
+	CompositeField struct {
+		// Name of the struct field.
+		Name string
+		// Info about the field's type.
+		Type TypeInfo
+		// The name of the composite type's attribute as specified
+		// in the field's `sql` tag.
+		Attr string
+	}
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -280,6 +324,9 @@ type (
 		ColIdent ColIdent
 		// The predicate type parsed from the `sql` tag, or 0.
 		Predicate Predicate
+		// The name of the custom operator parsed from the `sql`
+		// tag if the Predicate is IsOperator, otherwise empty.
+		Operator string
 		// The quantifier parsed from the `sql` tag, or 0.
 		Quantifier Quantifier
 		// The name of the function parsed from the `sql` tag, or empty.
@@ -298,6 +345,9 @@ type (
 		RHSLiteral string
 		// The predicate type parsed from the `sql` tag, or 0.
 		Predicate Predicate
+		// The name of the custom operator parsed from the `sql`
+		// tag if the Predicate is IsOperator, otherwise empty.
+		Operator string
 		// The quantifier parsed from the `sql` tag, or 0.
 		Quantifier Quantifier
 	}
@@ -369,6 +419,9 @@ type (
 		RelIdent RelIdent
 		// List of items parsed from the `sql` tag.
 		TagItems []JoinTagItem
+		// The soft-delete policy of the joined relation as declared
+		// by the config, or nil.
+		SoftDelete *SoftDeleteDirective
 	}
 
 	// JoinBoolTagItem is the boolean operator parsed from a join directive's `sql` tag.
@@ -449,6 +502,15 @@ type (
 		TypeKind TypeKind
 	}
 
+	// TotalCountField is the result of the analyzing a query struct's
+	// "totalcount" (case insensitive) field.
+	TotalCountField struct {
+		// Name of the field (case preserved).
+		Name string
+		// The kind of the field's type.
+		TypeKind TypeKind
+	}
+
 	// ErrorHandlerField is the result of analyzing a query struct's field whose
 	// type implements the gosql.ErrorHandler or gosql.ErrorInfoHandler interface.
 	ErrorHandlerField struct {
@@ -496,6 +558,18 @@ type (
 		// empty
 	}
 
+	// WithDeletedDirective is the result of analyzing the "_ gosql.WithDeleted" directive.
+	WithDeletedDirective struct {
+		// empty
+	}
+
+	// SoftDeleteDirective is the result of analyzing the "_ gosql.SoftDelete" directive,
+	// or of a matching entry in the config's soft_delete_relations list.
+	SoftDeleteDirective struct {
+		// The column identifier as parsed from the `sql` tag of the directive.
+		ColIdent
+	}
+
 	// IndexDirective is the result of analyzing the "_ gosql.Index" directive.
 	IndexDirective struct {
 		// The name of the index as parsed from the `sql` tag of the directive.
@@ -576,6 +650,63 @@ type (
 		Direction OrderDirection
 		// The NULL position parsed from the `sql` tag.
 		Nulls NullsPosition
+		// The name of the operator parsed from the `sql` tag, or empty.
+		// If set, the item orders by the result of the operator applied
+		// to the column and to the value of the Param field, which is how
+		// nearest-neighbor searches are expressed, e.g. `sql:"location <-> $Point"`.
+		Operator string
+		// The field whose value is used as the operator's right operand,
+		// or nil if the Operator is empty.
+		Param *OrderByParamField
+	}
+
+	// OrderByParamField is the result of analyzing the query struct field
+	// referenced by the right operand of an OrderByTagItem's operator.
+	OrderByParamField struct {
+		// The name of the field.
+		Name string
+		// The field's type information.
+		Type TypeInfo
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Window Functions
+////////////////////////////////////////////////////////////////////////////////
+
+type (
+	// WindowFunc is the result of analyzing a field whose `sql` tag
+	// declares a window function call, e.g. `sql:"@row_number,over:order=-a.id"`.
+	WindowFunc struct {
+		// The name of the window function.
+		Name string
+		// The column identifier of the function's argument, or empty.
+		Arg ColIdent
+		// The list of columns of the window's PARTITION BY clause.
+		Partition []ColIdent
+		// The list of items of the window's ORDER BY clause.
+		OrderBy []OrderByTagItem
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Many Relations
+////////////////////////////////////////////////////////////////////////////////
+
+type (
+	// ManyRelation is the result of analyzing a field whose `sql` tag has
+	// the "many" option, e.g. `sql:"order_item:i,many,i.order_id = o.id"`.
+	ManyRelation struct {
+		// The identifier of the child relation.
+		RelIdent RelIdent
+		// Info on the field's slice type and its struct element type.
+		Type RelType
+		// List of the join conditions parsed from the `sql` tag.
+		TagItems []JoinTagItem
+		// The soft-delete policy of the child relation as declared by
+		// the gosql.SoftDelete directive of the element type or by the
+		// config, or nil.
+		SoftDelete *SoftDeleteDirective
 	}
 )
 
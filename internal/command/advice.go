@@ -0,0 +1,48 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/frk/gosql/internal/generator"
+	"github.com/frk/gosql/internal/postgres"
+)
+
+// adviceReport is the content of the index advisor's report file.
+type adviceReport struct {
+	Advice []*postgres.Advice `json:"advice"`
+}
+
+// explainQueries has the server explain the queries generated for the given
+// targets and adds the resulting advice to that of the corresponding target.
+func (cmd *Command) explainQueries(db *postgres.DB, targInfos []*postgres.TargetInfo) error {
+	queries, _, err := generator.Queries(targInfos, cmd.Config)
+	if err != nil {
+		return err
+	}
+	for _, q := range queries {
+		advice, err := postgres.Explain(db, q.Info, q.SQL, cmd.IndexAdvisorMinRows.Value)
+		if err != nil {
+			return err
+		}
+		q.Info.Advice = append(q.Info.Advice, advice...)
+	}
+	return nil
+}
+
+// writeAdviceReport writes the advice of the targets of the given files,
+// in the order of the files and targets, to the index advisor's report file.
+func (cmd *Command) writeAdviceReport(outFiles []*outFile) error {
+	report := adviceReport{Advice: []*postgres.Advice{}}
+	for _, out := range outFiles {
+		for _, info := range out.targInfos {
+			report.Advice = append(report.Advice, info.Advice...)
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cmd.IndexAdvisorReport.Value, append(data, '\n'), 0644)
+}
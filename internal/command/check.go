@@ -0,0 +1,181 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/frk/gosql/internal/generator"
+	"github.com/frk/gosql/internal/search"
+)
+
+// ErrStale is returned by Run, in check mode, if it found any stale output files.
+var ErrStale = errors.New("stale output files found")
+
+// checkOutFiles compares the code generated for the given files with the current
+// content of the files and writes a unified diff of each file that differs to w.
+// The orphaned output files, i.e. the generated files whose input files no longer
+// exist or no longer contain any target types, are reported as removed. If any of
+// the files are stale ErrStale is returned after the diffs are written.
+func (cmd *Command) checkOutFiles(w io.Writer, pkgs []*search.Package, outFiles []*outFile) error {
+	var stale int
+	for _, out := range outFiles {
+		src, err := format.Source(out.buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		oldName := "a/" + cmd.relPath(out.path)
+		cur, err := os.ReadFile(out.path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			oldName = "/dev/null"
+		} else if bytes.Equal(cur, src) {
+			continue
+		}
+
+		stale += 1
+		if err := writeUnifiedDiff(w, oldName, "b/"+cmd.relPath(out.path), cur, src); err != nil {
+			return err
+		}
+	}
+
+	orphans, err := cmd.findOrphanFiles(pkgs)
+	if err != nil {
+		return err
+	}
+	for _, path := range orphans {
+		cur, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		stale += 1
+		if err := writeUnifiedDiff(w, "a/"+cmd.relPath(path), "/dev/null", cur, nil); err != nil {
+			return err
+		}
+	}
+
+	if stale > 0 {
+		if _, err := fmt.Fprintf(w, "%d stale file(s) found\n", stale); err != nil {
+			return err
+		}
+		return ErrStale
+	}
+	return nil
+}
+
+// findOrphanFiles returns the paths of the output files that are orphaned, i.e.
+// the generated files, named like output files, whose input files either no longer
+// exist or were searched and found to contain no target types. The output files of
+// input files that were not searched, e.g. because they are excluded by the input
+// file filter or by build constraints, are kept, as are the generated files that
+// are not query output files, e.g. the enums and models files. The directories are
+// walked like the tool's package search walks them, i.e. the subdirectories are
+// walked only in recursive mode, the "vendor", "testdata", and the "." and "_"
+// prefixed directories are skipped, and so are the directories of nested modules.
+func (cmd *Command) findOrphanFiles(pkgs []*search.Package) (orphans []string, err error) {
+	prefix, suffix, _ := strings.Cut(cmd.OutputFileNameFormat.Value, "%s")
+	if !strings.HasSuffix(suffix, ".go") {
+		suffix = suffix + ".go"
+	}
+
+	outputs, searched := make(map[string]bool), make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			outputs[cmd.outFilePath(file.Path)] = true
+		}
+		for _, path := range pkg.Searched {
+			searched[path] = true
+		}
+	}
+
+	root := cmd.WorkingDirectory.Value
+	filter := cmd.FileFilterFunc()
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if !cmd.Recursive.Value || name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if len(name) <= len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) ||
+			!strings.HasSuffix(name, suffix) || outputs[path] {
+			return nil
+		}
+		in := filepath.Join(filepath.Dir(path), name[len(prefix):len(name)-len(suffix)]+".go")
+		if filter != nil && !filter(in) {
+			return nil
+		}
+		if !searched[in] {
+			// keep the output of an input file that exists but was not searched
+			if _, err := os.Stat(in); err == nil {
+				return nil
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if generator.IsGenerated(src) && isQueryOutput(src) {
+			orphans = append(orphans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
+
+// isQueryOutput reports whether or not the given generated source is that of
+// a query output file. Unlike the enums and models files, which start with the
+// same preamble, the query output files do not declare any types.
+func isQueryOutput(src []byte) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+			return false
+		}
+	}
+	return true
+}
+
+// relPath returns the given path relative to the working directory,
+// or the path itself if it cannot be made relative to it.
+func (cmd *Command) relPath(path string) string {
+	if rel, err := filepath.Rel(cmd.WorkingDirectory.Value, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
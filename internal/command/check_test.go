@@ -0,0 +1,125 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frk/compare"
+	"github.com/frk/gosql/internal/config"
+	"github.com/frk/gosql/internal/search"
+)
+
+const testPreamble = `// DO NOT EDIT. This file was generated by "github.com/frk/gosql".` + "\n\n"
+
+// testOrphanFiles are the files of a module that contains both orphaned
+// output files and generated files that must not be considered orphaned.
+var testOrphanFiles = map[string]string{
+	"go.mod": "module example.com/orphans\n\ngo 1.21\n",
+
+	// the input file contains target types, the output file is current
+	"query.go":       "package orphans\n\ntype SelectUser struct{}\n",
+	"query_gosql.go": testPreamble + "package orphans\n\nfunc (*SelectUser) Exec() {}\n",
+	// the input file no longer contains any target types
+	"notargets.go":       "package orphans\n\ntype user struct{}\n",
+	"notargets_gosql.go": testPreamble + "package orphans\n\nfunc (*user) Exec() {}\n",
+	// the input file no longer exists
+	"missing_gosql.go": testPreamble + "package orphans\n",
+	// the input file is excluded by a build constraint
+	"tagged.go":       "//go:build never\n\npackage orphans\n\ntype SelectTagged struct{}\n",
+	"tagged_gosql.go": testPreamble + "//go:build never\n\npackage orphans\n\nfunc (*SelectTagged) Exec() {}\n",
+	// the input file is excluded by a GOOS suffix
+	"query_plan9.go":       "package orphans\n\ntype SelectPlan9 struct{}\n",
+	"query_plan9_gosql.go": testPreamble + "package orphans\n\nfunc (*SelectPlan9) Exec() {}\n",
+	// the enums and models files are not query output files
+	"enums_gosql.go":      testPreamble + "package orphans\n\ntype Color string\n",
+	"sub/models_gosql.go": testPreamble + "package sub\n\ntype User struct{}\n",
+	// the file is not generated
+	"handwritten_gosql.go": "package orphans\n",
+	// the input file of the subdirectory no longer exists
+	"sub/gone_gosql.go": testPreamble + "package sub\n",
+	// the files of a nested module are not walked
+	"nested/go.mod":        "module example.com/orphans/nested\n\ngo 1.21\n",
+	"nested/gone_gosql.go": testPreamble + "package nested\n",
+}
+
+// testOrphanCommand writes the files to a temporary directory and returns
+// a command that works in that directory and the packages found in it.
+func testOrphanCommand(t *testing.T, files map[string]string, recursive bool) (*Command, []*search.Package) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := &Command{config.DefaultConfig}
+	cmd.WorkingDirectory.Value = dir
+	cmd.Recursive.Value = recursive
+
+	pkgs, err := search.Search(dir, recursive, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmd, pkgs
+}
+
+func TestCheckOutFilesOrphans(t *testing.T) {
+	cmd, pkgs := testOrphanCommand(t, testOrphanFiles, true)
+
+	// the orphaned files are reported regardless of cleanup
+	buf := new(bytes.Buffer)
+	if err := cmd.checkOutFiles(buf, pkgs, nil); !errors.Is(err, ErrStale) {
+		t.Fatalf("got error %v, want %v", err, ErrStale)
+	}
+	want := "--- a/missing_gosql.go\n+++ /dev/null\n" +
+		"@@ -1,3 +0,0 @@\n" +
+		"-// DO NOT EDIT. This file was generated by \"github.com/frk/gosql\".\n" +
+		"-\n" +
+		"-package orphans\n" +
+		"--- a/notargets_gosql.go\n+++ /dev/null\n" +
+		"@@ -1,5 +0,0 @@\n" +
+		"-// DO NOT EDIT. This file was generated by \"github.com/frk/gosql\".\n" +
+		"-\n" +
+		"-package orphans\n" +
+		"-\n" +
+		"-func (*user) Exec() {}\n" +
+		"--- a/sub/gone_gosql.go\n+++ /dev/null\n" +
+		"@@ -1,3 +0,0 @@\n" +
+		"-// DO NOT EDIT. This file was generated by \"github.com/frk/gosql\".\n" +
+		"-\n" +
+		"-package sub\n" +
+		"3 stale file(s) found\n"
+	if err := compare.Compare(buf.String(), want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckOutFilesNoOrphans(t *testing.T) {
+	files := map[string]string{
+		"go.mod":         testOrphanFiles["go.mod"],
+		"query.go":       testOrphanFiles["query.go"],
+		"query_gosql.go": testOrphanFiles["query_gosql.go"],
+		"enums_gosql.go": testOrphanFiles["enums_gosql.go"],
+		// not in recursive mode the subdirectories are not walked
+		"sub/gone_gosql.go": testOrphanFiles["sub/gone_gosql.go"],
+	}
+	cmd, pkgs := testOrphanCommand(t, files, false)
+
+	buf := new(bytes.Buffer)
+	if err := cmd.checkOutFiles(buf, pkgs, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := compare.Compare(buf.String(), ""); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,12 +2,15 @@ package command
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/format"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/frk/gosql/internal/analysis"
 	"github.com/frk/gosql/internal/config"
@@ -44,11 +47,15 @@ func New(cfg config.Config) (*Command, error) {
 }
 
 func (cmd *Command) Run() error {
-	db, err := postgres.Open(cmd.DatabaseDSN.Value)
+	dbs, err := cmd.openDBs()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
+	defer func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+	}()
 
 	// 1. search for query types
 	pkgs, err := search.Search(cmd.WorkingDirectory.Value, cmd.Recursive.Value, cmd.FileFilterFunc())
@@ -60,50 +67,233 @@ func (cmd *Command) Run() error {
 		return err
 	}
 
-	result := make([][]*outFile, len(pkgs))
-	for i, pkg := range pkgs {
-		outFiles := make([]*outFile, len(pkg.Files))
-
-		for j, file := range pkg.Files {
+	var outFiles []*outFile
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
 			out := new(outFile)
 			out.path = cmd.outFilePath(file.Path)
-			out.targInfos = make([]*postgres.TargetInfo, len(file.Matches))
-
-			for k, match := range file.Matches {
-				// 2. analyze
-				anInfo, err := analysis.Run(pkg.Fset, match.Named, match.Pos, cmd.Config)
-				if err != nil {
-					return err
-				}
-
-				// 3. type check
-				targInfo, err := postgres.Check(db, anInfo.Struct, anInfo)
-				if err != nil {
-					return err
-				}
-
-				out.targInfos[k] = targInfo
-			}
+			out.file = file
+			outFiles = append(outFiles, out)
+		}
+	}
 
-			// 4. generate
-			if err := generator.Write(&out.buf, pkg.Name, out.targInfos, cmd.Config); err != nil {
+	// 2-6. analyze, type check, generate, verify, and explain
+	if err := cmd.processOutFiles(dbs, outFiles); err != nil {
+		return err
+	}
+
+	// 7. write to file(s), or, in check mode, compare with the file(s)
+	var checkErr error
+	if cmd.CheckOutput.Value {
+		checkErr = cmd.checkOutFiles(os.Stdout, pkgs, outFiles)
+		if checkErr != nil && !errors.Is(checkErr, ErrStale) {
+			return checkErr
+		}
+	} else {
+		for _, out := range outFiles {
+			if err := cmd.writeOutFile(out); err != nil {
+				return err
+			}
+		}
+		if cmd.Cleanup.Value {
+			if err := cmd.removeOrphanFiles(os.Stdout, pkgs); err != nil {
 				return err
 			}
+		}
+		if cmd.IndexAdvisor.Value {
+			if err := cmd.writeAdviceReport(outFiles); err != nil {
+				return err
+			}
+		}
+	}
 
-			outFiles[j] = out
+	for _, db := range dbs {
+		if err := db.WriteCache(); err != nil {
+			return err
 		}
-		result[i] = outFiles
 	}
+	return checkErr
+}
 
-	// 5. write to file(s)
-	for _, outFiles := range result {
-		for _, out := range outFiles {
-			if err := cmd.writeOutFile(out); err != nil {
+// processOutFiles processes the given files using a bounded pool of workers.
+// The errors of all the files are returned together in the order of the files,
+// regardless of which worker processed the file and when.
+func (cmd *Command) processOutFiles(dbs map[string]*postgres.DB, outFiles []*outFile) error {
+	jobs := cmd.Jobs.Value
+	if jobs < 1 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	if jobs > len(outFiles) {
+		jobs = len(outFiles)
+	}
+
+	errs := make([]error, len(outFiles))
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = cmd.processOutFile(dbs, outFiles[i])
+			}
+		}()
+	}
+	for i := range outFiles {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// processOutFile analyzes and type checks the matches of the given file and
+// then generates, verifies, and explains, the file's code. The errors of the individual
+// matches are returned together, the code is generated only if there are none.
+func (cmd *Command) processOutFile(dbs map[string]*postgres.DB, out *outFile) error {
+	pkg := out.file.Package
+	out.targInfos = make([]*postgres.TargetInfo, len(out.file.Matches))
+	out.targDBs = make([]*postgres.DB, len(out.file.Matches))
+
+	var errs []error
+	for k, match := range out.file.Matches {
+		db, err := cmd.matchDB(dbs, out.file, match)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// 2. analyze
+		anInfo, err := analysis.Run(pkg.Fset, match.Named, match.Pos, cmd.Config)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// 3. type check
+		targInfo, err := postgres.Check(db, anInfo.Struct, anInfo)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		out.targInfos[k] = targInfo
+		out.targDBs[k] = db
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// 4. generate
+	if err := generator.Write(&out.buf, pkg.Name, out.targInfos, cmd.Config); err != nil {
+		return err
+	}
+
+	// 5-6. verify and explain, against the database of each target
+	for _, group := range out.groupByDB() {
+		if cmd.VerifyQueries.Value {
+			if err := cmd.verifyQueries(group.db, group.targInfos); err != nil {
 				return err
 			}
 		}
+		if cmd.IndexAdvisor.Value && cmd.IndexAdvisorExplain.Value {
+			if err := cmd.explainQueries(group.db, group.targInfos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// openDB opens the database specified by the given DSN, if the catalog cache
+// is enabled the database's catalog is cached in the user's cache dir.
+func (cmd *Command) openDB(dsn string) (*postgres.DB, error) {
+	if !cmd.CatalogCache.Value {
+		return postgres.Open(dsn)
 	}
 
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return postgres.Open(dsn)
+	}
+	return postgres.OpenCached(dsn, filepath.Join(dir, "gosql"))
+}
+
+// openDBs opens the default database and each of the config's named databases.
+// The returned map holds the named databases under their names and the default
+// database under the empty name.
+func (cmd *Command) openDBs() (dbs map[string]*postgres.DB, err error) {
+	dbs = make(map[string]*postgres.DB, len(cmd.Databases)+1)
+	defer func() {
+		if err != nil {
+			for _, db := range dbs {
+				db.Close()
+			}
+		}
+	}()
+
+	db, err := cmd.openDB(cmd.DatabaseDSN.Value)
+	if err != nil {
+		return nil, err
+	}
+	dbs[""] = db
+	for _, conf := range cmd.Databases {
+		db, err := cmd.openDB(conf.DSN.Value)
+		if err != nil {
+			return nil, err
+		}
+		db.ConfigName = conf.Name.Value
+		dbs[conf.Name.Value] = db
+	}
+	opts, err := cmd.checkOptions()
+	if err != nil {
+		return nil, err
+	}
+	for _, db := range dbs {
+		db.Options = opts
+	}
+	return dbs, nil
+}
+
+// matchDB returns the database against which the given match of the given file
+// will be type checked. The type's "gosql:db" directive takes precedence over
+// the config's databases, the default database is used if neither applies.
+func (cmd *Command) matchDB(dbs map[string]*postgres.DB, file *search.File, match *search.Match) (*postgres.DB, error) {
+	if len(match.DB) > 0 {
+		if db, ok := dbs[match.DB]; ok {
+			return db, nil
+		}
+		pos := file.Package.Fset.Position(match.Pos)
+		return nil, fmt.Errorf("%s: unknown database %q in the gosql:db directive of type %s",
+			pos, match.DB, match.Named.Obj().Name())
+	}
+	for _, conf := range cmd.Databases {
+		if conf.Match(file.Package.Path, file.Path) {
+			return dbs[conf.Name.Value], nil
+		}
+	}
+	return dbs[""], nil
+}
+
+// verifyQueries prepares the queries generated for the given
+// targets to let the database server check them for errors.
+// The targets whose queries cannot be verified are reported.
+func (cmd *Command) verifyQueries(db *postgres.DB, targInfos []*postgres.TargetInfo) error {
+	queries, skipped, err := generator.Queries(targInfos, cmd.Config)
+	if err != nil {
+		return err
+	}
+	for _, info := range skipped {
+		pos := info.Info.FileSet.Position(info.Info.TypeNamePos)
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: query not verified, its VALUES list is built at runtime\n",
+			cmd.relPath(pos.Filename), pos.Line, info.Info.TypeName)
+	}
+	for _, q := range queries {
+		if err := postgres.Verify(db, q.Info, q.SQL, q.Params); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -122,12 +312,43 @@ func (cmd *Command) outFilePath(inFilePath string) string {
 type outFile struct {
 	// absolute path of the output file
 	path string
+	// the input file
+	file *search.File
 	// the type checked targets
 	targInfos []*postgres.TargetInfo
+	// the databases against which the targets were type checked
+	targDBs []*postgres.DB
 	// the generated code
 	buf bytes.Buffer
 }
 
+// dbGroup is a group of the targets of a file that were
+// type checked against the same database.
+type dbGroup struct {
+	db        *postgres.DB
+	targInfos []*postgres.TargetInfo
+}
+
+// groupByDB groups the file's targets by the database against which they were
+// type checked, the groups are in the order of the first target of each group.
+func (out *outFile) groupByDB() (groups []*dbGroup) {
+	for i, info := range out.targInfos {
+		var group *dbGroup
+		for _, g := range groups {
+			if g.db == out.targDBs[i] {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &dbGroup{db: out.targDBs[i]}
+			groups = append(groups, group)
+		}
+		group.targInfos = append(group.targInfos, info)
+	}
+	return groups
+}
+
 func (cmd *Command) writeOutFile(out *outFile) (err error) {
 	f, err := os.Create(out.path)
 	if err != nil {
@@ -153,3 +374,26 @@ func (cmd *Command) writeOutFile(out *outFile) (err error) {
 
 	return f.Sync()
 }
+
+// removeOrphanFiles removes the orphaned output files of the given packages, i.e.
+// the generated files whose input files no longer exist, or no longer contain any
+// target types. In dry-run mode the files are only listed to w.
+func (cmd *Command) removeOrphanFiles(w io.Writer, pkgs []*search.Package) error {
+	orphans, err := cmd.findOrphanFiles(pkgs)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range orphans {
+		if cmd.CleanupDryRun.Value {
+			if _, err := fmt.Fprintf(w, "would remove %s\n", cmd.relPath(path)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
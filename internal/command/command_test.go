@@ -0,0 +1,71 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/frk/compare"
+)
+
+func TestRemoveOrphanFiles(t *testing.T) {
+	orphans := []string{"missing_gosql.go", "notargets_gosql.go", "sub/gone_gosql.go"}
+
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   string
+	}{{
+		name:   "remove",
+		dryRun: false,
+		want:   "",
+	}, {
+		name:   "dry-run",
+		dryRun: true,
+		want: "would remove missing_gosql.go\n" +
+			"would remove notargets_gosql.go\n" +
+			"would remove sub/gone_gosql.go\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, pkgs := testOrphanCommand(t, testOrphanFiles, true)
+			cmd.Cleanup.Value = true
+			cmd.CleanupDryRun.Value = tt.dryRun
+
+			buf := new(bytes.Buffer)
+			if err := cmd.removeOrphanFiles(buf, pkgs); err != nil {
+				t.Fatal(err)
+			}
+			if err := compare.Compare(buf.String(), tt.want); err != nil {
+				t.Error(err)
+			}
+
+			// the orphaned files are removed, unless in dry-run
+			// mode, and all the other files are kept
+			var want []string
+			for name := range testOrphanFiles {
+				if i := sort.SearchStrings(orphans, name); tt.dryRun || i == len(orphans) || orphans[i] != name {
+					want = append(want, name)
+				}
+			}
+			sort.Strings(want)
+
+			var got []string
+			err := filepath.WalkDir(cmd.WorkingDirectory.Value, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					got = append(got, cmd.relPath(path))
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(got)
+			if err := compare.Compare(got, want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
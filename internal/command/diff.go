@@ -0,0 +1,186 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines
+// that surround the changes of a diff's hunk.
+const diffContext = 3
+
+// diffLine is a line of a diff, the kind is one of ' ', '-', or '+'.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// writeUnifiedDiff writes the unified diff of a and b to w. The diff's headers
+// use the given names of a and b, if a and b are equal nothing is written.
+func writeUnifiedDiff(w io.Writer, aName, bName string, a, b []byte) error {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	// the number of lines of a and b that precede each line of the diff
+	apos, bpos := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, l := range lines {
+		apos[i+1], bpos[i+1] = apos[i], bpos[i]
+		if l.kind != '+' {
+			apos[i+1] += 1
+		}
+		if l.kind != '-' {
+			bpos[i+1] += 1
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(lines); i++ {
+		if lines[i].kind == ' ' {
+			continue
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+		}
+
+		// the hunk extends to the last change that is separated from
+		// the previous change by no more than twice the context
+		last := i
+		for j := i + 1; j < len(lines) && j-last <= 2*diffContext; j++ {
+			if lines[j].kind != ' ' {
+				last = j
+			}
+		}
+		start, end := max(i-diffContext, 0), min(last+diffContext+1, len(lines))
+
+		astart, alen := apos[start]+1, apos[end]-apos[start]
+		if alen == 0 {
+			astart -= 1
+		}
+		bstart, blen := bpos[start]+1, bpos[end]-bpos[start]
+		if blen == 0 {
+			bstart -= 1
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", astart, alen, bstart, blen)
+		for _, l := range lines[start:end] {
+			sb.WriteByte(l.kind)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+		i = end - 1
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// diffMaxEdits is the maximum number of edits for which diffLines looks for
+// the shortest edit script, it bounds the memory used by the search.
+const diffMaxEdits = 1000
+
+// diffLines returns the lines of the shortest edit script that turns a into b,
+// as found by Myers' O(ND) algorithm, after the common prefix and suffix of a
+// and b are trimmed. If the script needs more than diffMaxEdits edits, all of
+// the remaining lines of a are removed and all of those of b are added instead.
+func diffLines(a, b []string) []diffLine {
+	var pre, suf int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre += 1
+	}
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf += 1
+	}
+	am, bm := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, text := range a[:pre] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	if mid := myersDiff(am, bm); mid != nil {
+		lines = append(lines, mid...)
+	} else {
+		for _, text := range am {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range bm {
+			lines = append(lines, diffLine{'+', text})
+		}
+	}
+	for _, text := range a[len(a)-suf:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+// myersDiff returns the lines of the shortest edit script that turns a into b,
+// or nil if the script needs more than diffMaxEdits edits. The deletions of a
+// change are returned before its insertions.
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+
+	// trace[d][d+k] is the furthest x reached on diagonal k=x-y with d edits
+	var trace [][]int
+	for d := 0; ; d++ {
+		if d > diffMaxEdits {
+			return nil
+		}
+		v := make([]int, 2*d+1)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if d > 0 {
+				if prev := trace[d-1]; k == -d || (k != d && prev[d-1+k-1] < prev[d-1+k+1]) {
+					x = prev[d-1+k+1] // an insertion, from diagonal k+1
+				} else {
+					x = prev[d-1+k-1] + 1 // a deletion, from diagonal k-1
+				}
+			}
+			for y := x - k; x < n && y < m && a[x] == b[y]; y++ {
+				x += 1
+			}
+			v[d+k] = x
+		}
+		trace = append(trace, v)
+		if k := n - m; k >= -d && k <= d && (d-k)%2 == 0 && v[d+k] >= n {
+			break
+		}
+	}
+
+	// walk the trace back from (n, m) to (0, 0)
+	lines := make([]diffLine, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		k, prev := x-y, trace[d-1]
+		if k == -d || (k != d && prev[d-1+k-1] < prev[d-1+k+1]) {
+			px := prev[d-1+k+1]
+			for x > px {
+				x, y = x-1, y-1
+				lines = append(lines, diffLine{' ', a[x]})
+			}
+			y -= 1
+			lines = append(lines, diffLine{'+', b[y]})
+		} else {
+			px := prev[d-1+k-1]
+			for x > px+1 {
+				x, y = x-1, y-1
+				lines = append(lines, diffLine{' ', a[x]})
+			}
+			x -= 1
+			lines = append(lines, diffLine{'-', a[x]})
+		}
+	}
+	for ; x > 0; x-- {
+		lines = append(lines, diffLine{' ', a[x-1]})
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// splitLines splits the given text into lines without their line terminators.
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+}
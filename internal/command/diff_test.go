@@ -0,0 +1,123 @@
+package command
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/frk/compare"
+)
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{{
+		a:    "a\nb\nc\n",
+		b:    "a\nb\nc\n",
+		want: "",
+	}, {
+		a: "a\nb\nc\n",
+		b: "a\nx\nc\n",
+		want: "--- a/f.go\n+++ b/f.go\n" +
+			"@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+	}, {
+		a: "",
+		b: "a\nb\n",
+		want: "--- a/f.go\n+++ b/f.go\n" +
+			"@@ -0,0 +1,2 @@\n+a\n+b\n",
+	}, {
+		a: "a\nb\n",
+		b: "",
+		want: "--- a/f.go\n+++ b/f.go\n" +
+			"@@ -1,2 +0,0 @@\n-a\n-b\n",
+	}, {
+		a: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		b: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+		want: "--- a/f.go\n+++ b/f.go\n" +
+			"@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n" +
+			"@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+	}}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := writeUnifiedDiff(buf, "a/f.go", "b/f.go", []byte(tt.a), []byte(tt.b)); err != nil {
+			t.Error(err)
+			continue
+		}
+		if err := compare.Compare(buf.String(), tt.want); err != nil {
+			t.Errorf("%q -> %q: %v", tt.a, tt.b, err)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	// a and b of the given length whose lines all differ from each other
+	distinct := func(n int, prefix string) (lines []string) {
+		for i := 0; i < n; i++ {
+			lines = append(lines, prefix+strconv.Itoa(i))
+		}
+		return lines
+	}
+	// the edit script that removes all of a and adds all of b
+	replace := func(a, b []string) (lines []diffLine) {
+		for _, text := range a {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{'+', text})
+		}
+		return lines
+	}
+
+	tests := []struct {
+		a, b []string
+		want []diffLine
+	}{{
+		a:    nil,
+		b:    nil,
+		want: []diffLine{},
+	}, {
+		a:    []string{"a", "b"},
+		b:    []string{"a", "b"},
+		want: []diffLine{{' ', "a"}, {' ', "b"}},
+	}, {
+		a:    []string{"a", "b", "c"},
+		b:    []string{"a", "c"},
+		want: []diffLine{{' ', "a"}, {'-', "b"}, {' ', "c"}},
+	}, {
+		a:    []string{"a", "c"},
+		b:    []string{"a", "b", "c"},
+		want: []diffLine{{' ', "a"}, {'+', "b"}, {' ', "c"}},
+	}, {
+		a:    []string{"x", "y"},
+		b:    []string{"p", "q"},
+		want: []diffLine{{'-', "x"}, {'-', "y"}, {'+', "p"}, {'+', "q"}},
+	}, {
+		a: []string{"a", "b", "c", "a", "b", "b", "a"},
+		b: []string{"c", "b", "a", "b", "a", "c"},
+		want: []diffLine{{'-', "a"}, {'-', "b"}, {' ', "c"}, {'+', "b"}, {' ', "a"},
+			{' ', "b"}, {'-', "b"}, {' ', "a"}, {'+', "c"}},
+	}, {
+		a:    []string{"a", "x", "b", "y", "c"},
+		b:    []string{"a", "b", "c"},
+		want: []diffLine{{' ', "a"}, {'-', "x"}, {' ', "b"}, {'-', "y"}, {' ', "c"}},
+	}, {
+		// the search for the shortest edit script gives up above diffMaxEdits
+		a:    distinct(diffMaxEdits, "a"),
+		b:    distinct(diffMaxEdits, "b"),
+		want: replace(distinct(diffMaxEdits, "a"), distinct(diffMaxEdits, "b")),
+	}, {
+		a: append(append([]string{"p"}, distinct(diffMaxEdits, "a")...), "s"),
+		b: append(append([]string{"p"}, distinct(diffMaxEdits, "b")...), "s"),
+		want: append(append([]diffLine{{' ', "p"}},
+			replace(distinct(diffMaxEdits, "a"), distinct(diffMaxEdits, "b"))...), diffLine{' ', "s"}),
+	}}
+
+	for i, tt := range tests {
+		got := diffLines(tt.a, tt.b)
+		if err := compare.Compare(got, tt.want); err != nil {
+			t.Errorf("#%d: %v", i, err)
+		}
+	}
+}
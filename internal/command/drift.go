@@ -0,0 +1,162 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/postgres"
+	"github.com/frk/gosql/internal/postgres/oid"
+	"github.com/frk/gosql/internal/search"
+)
+
+// ErrDrift is returned by RunDrift if it found any drift.
+var ErrDrift = errors.New("drift found")
+
+// driftReport is the JSON form of the drift command's report.
+type driftReport struct {
+	Drift []*postgres.Drift `json:"drift"`
+}
+
+// driftRel holds the columns of a relation that are
+// mapped to the fields of at least one struct type.
+type driftRel struct {
+	db     *postgres.DB
+	rel    *postgres.Relation
+	mapped map[string]bool
+}
+
+// RunDrift compares the relation types of the query types with the relations
+// of the database and writes a report of the differences to stdout. If there
+// are any differences ErrDrift is returned after the report is written.
+func (cmd *Command) RunDrift() error {
+	dbs, err := cmd.openDBs()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+	}()
+
+	pkgs, err := search.Search(cmd.WorkingDirectory.Value, cmd.Recursive.Value, cmd.FileFilterFunc())
+	if err != nil {
+		return err
+	}
+	if err := analysis.AnalyzeFilterValueConverters(cmd.Config); err != nil {
+		return err
+	}
+
+	report := driftReport{Drift: []*postgres.Drift{}}
+	seen := make(map[string]bool)
+	add := func(d *postgres.Drift) {
+		// the same struct type may be the relation type of many targets
+		key := fmt.Sprintf("%s:%s:%s:%s:%s:%d", d.Kind, d.Database, d.Relation, d.Column, d.File, d.Line)
+		if !seen[key] {
+			seen[key] = true
+			report.Drift = append(report.Drift, d)
+		}
+	}
+
+	type relKey struct {
+		db  *postgres.DB
+		oid oid.OID
+	}
+	var rels []*driftRel
+	relMap := make(map[relKey]*driftRel)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, match := range file.Matches {
+				db, err := cmd.matchDB(dbs, file, match)
+				if err != nil {
+					return err
+				}
+				anInfo, err := analysis.Run(pkg.Fset, match.Named, match.Pos, cmd.Config)
+				if err != nil {
+					return err
+				}
+				info, err := postgres.CheckDrift(db, anInfo.Struct, anInfo)
+				if err != nil {
+					return err
+				}
+
+				for _, d := range info.Drift {
+					add(d)
+				}
+				if info.Relation == nil || info.Columns == nil {
+					continue
+				}
+
+				key := relKey{db, info.Relation.OID}
+				r, ok := relMap[key]
+				if !ok {
+					r = &driftRel{db: db, rel: info.Relation, mapped: make(map[string]bool)}
+					relMap[key] = r
+					rels = append(rels, r)
+				}
+				for _, name := range info.Columns {
+					r.mapped[name] = true
+				}
+			}
+		}
+	}
+	for _, r := range rels {
+		for _, d := range postgres.CheckUnmappedColumns(r.db, r.rel, r.mapped) {
+			add(d)
+		}
+	}
+
+	if !cmd.DriftSuggest.Value {
+		for _, d := range report.Drift {
+			d.Suggestions = nil
+		}
+	}
+	if cmd.DriftJSON.Value {
+		data, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	} else if err := writeDriftText(os.Stdout, report.Drift); err != nil {
+		return err
+	}
+
+	for _, db := range dbs {
+		if err := db.WriteCache(); err != nil {
+			return err
+		}
+	}
+	if len(report.Drift) > 0 {
+		return ErrDrift
+	}
+	return nil
+}
+
+// writeDriftText writes the human-readable form of the given drift to w.
+func writeDriftText(w io.Writer, drift []*postgres.Drift) error {
+	var b strings.Builder
+	for _, d := range drift {
+		if len(d.File) > 0 {
+			fmt.Fprintf(&b, "%s:%d: ", d.File, d.Line)
+		}
+		if len(d.Database) > 0 {
+			fmt.Fprintf(&b, "%s: ", d.Database)
+		}
+		fmt.Fprintf(&b, "%s: %s (%s)\n", d.Relation, d.Message, d.Kind)
+		for _, s := range d.Suggestions {
+			for _, line := range strings.Split(s, "\n") {
+				fmt.Fprintf(&b, "\t%s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "%d drift(s) found\n", len(drift))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,85 @@
+package command
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"github.com/frk/gosql/internal/config"
+	"github.com/frk/gosql/internal/generator"
+	"github.com/frk/gosql/internal/postgres"
+	"github.com/frk/gosql/internal/search"
+)
+
+// RunEnums generates a Go type for each of the enum types
+// of the database and writes them to the enums output file.
+func (cmd *Command) RunEnums() error {
+	db, err := cmd.openDB(cmd.DatabaseDSN.Value)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	out := new(outFile)
+	if out.path, err = filepath.Abs(cmd.EnumsOutputFile.Value); err != nil {
+		return err
+	}
+
+	pkgName := cmd.EnumsPackageName.Value
+	if len(pkgName) == 0 {
+		pkgName = enumsPackageName(filepath.Dir(out.path))
+	}
+
+	if err := generator.WriteEnums(&out.buf, pkgName, db.Enums()); err != nil {
+		return err
+	}
+	if err := cmd.writeOutFile(out); err != nil {
+		return err
+	}
+	return db.WriteCache()
+}
+
+// enumsPackageName returns the name of the package declared by the Go
+// files in the given directory, if there are no such files, the name
+// of the directory is returned instead.
+func enumsPackageName(dir string) string {
+	if _, err := os.Stat(dir); err == nil {
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, dir, nil, parser.PackageClauseOnly)
+		if err == nil {
+			for name := range pkgs {
+				if len(pkgs) == 1 || name != "main" {
+					return name
+				}
+			}
+		}
+	}
+	return filepath.Base(dir)
+}
+
+// checkOptions returns the type checker options based on the command's config.
+// If strict enums are enabled the package path of the enums output file's
+// directory is resolved so that the generated enum types can be identified.
+func (cmd *Command) checkOptions() (postgres.CheckOptions, error) {
+	opts := postgres.CheckOptions{StrictEnums: cmd.StrictEnums.Value, StrictNulls: cmd.StrictNulls.Value}
+	opts.RuntimeRole = cmd.RuntimeRole.Value
+	opts.AdviseIndexes = cmd.IndexAdvisor.Value
+	opts.DefaultSchemas = cmd.DefaultSchemas
+	if len(cmd.EnumTypes) > 0 {
+		opts.EnumTypes = make(map[string]config.ObjectIdent, len(cmd.EnumTypes))
+		for _, et := range cmd.EnumTypes {
+			opts.EnumTypes[et.Enum.Value] = et.Type
+		}
+	}
+	if opts.StrictEnums {
+		dir, err := filepath.Abs(filepath.Dir(cmd.EnumsOutputFile.Value))
+		if err != nil {
+			return opts, err
+		}
+		if opts.EnumsPkgPath, err = search.PackagePath(dir); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+
+	"github.com/frk/gosql/internal/generator"
+)
+
+// RunModels generates a Go struct type for each of the tables and views
+// of the database that match the configured patterns and writes them to
+// the models output file. The file is left untouched if the newly generated
+// code is identical to the file's current content.
+func (cmd *Command) RunModels() error {
+	db, err := cmd.openDB(cmd.DatabaseDSN.Value)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	out := new(outFile)
+	if out.path, err = filepath.Abs(cmd.ModelsOutputFile.Value); err != nil {
+		return err
+	}
+
+	pkgName := cmd.ModelsPackageName.Value
+	if len(pkgName) == 0 {
+		pkgName = enumsPackageName(filepath.Dir(out.path))
+	}
+
+	models, err := db.Models(cmd.ModelsRelations.Value)
+	if err != nil {
+		return err
+	}
+	if err := generator.WriteModels(&out.buf, pkgName, models, cmd.Config); err != nil {
+		return err
+	}
+
+	if cur, err := os.ReadFile(out.path); err == nil {
+		if src, err := format.Source(out.buf.Bytes()); err == nil && bytes.Equal(src, cur) {
+			return db.WriteCache()
+		}
+	}
+	if err := cmd.writeOutFile(out); err != nil {
+		return err
+	}
+	return db.WriteCache()
+}
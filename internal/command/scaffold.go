@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/frk/gosql/internal/generator"
+)
+
+// RunScaffold generates the query types for the common operations on the
+// configured table and writes them to the scaffold output file. The file
+// is meant to be edited by hand and is therefore never overwritten.
+func (cmd *Command) RunScaffold() error {
+	if len(cmd.ScaffoldTable.Value) == 0 {
+		return fmt.Errorf("missing table, use the -table flag to specify one")
+	}
+
+	db, err := cmd.openDB(cmd.DatabaseDSN.Value)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	model, err := db.Model(cmd.ScaffoldTable.Value)
+	if err != nil {
+		return err
+	}
+
+	outPath := cmd.ScaffoldOutputFile.Value
+	if len(outPath) == 0 {
+		outPath = filepath.Join(filepath.Dir(cmd.ModelsOutputFile.Value), "%s_queries.go")
+	}
+	outPath = strings.Replace(outPath, "%s", model.Relation.Name, 1)
+
+	out := new(outFile)
+	if out.path, err = filepath.Abs(outPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(out.path); err == nil {
+		return fmt.Errorf("scaffold file already exists: %q", out.path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	pkgName := cmd.ModelsPackageName.Value
+	if len(pkgName) == 0 {
+		pkgName = enumsPackageName(filepath.Dir(out.path))
+	}
+
+	if err := generator.WriteScaffold(&out.buf, pkgName, model, cmd.Config); err != nil {
+		return err
+	}
+	if err := cmd.writeOutFile(out); err != nil {
+		return err
+	}
+	return db.WriteCache()
+}
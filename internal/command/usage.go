@@ -10,13 +10,25 @@ func PrintUsage() {
 }
 
 const usage = `usage: gosql [-db] [-wd] [-r] [-f] [-rx] [-o] [-qid] [-argtype]
-	[-fcktag] [-fckbase] [-fcksep] [-with-ctx] [--config]
+	[-fcktag] [-fckbase] [-fcksep] [-with-ctx] [-cache] [-verify] [-j] [-check] [-cleanup]
+	[-dry-run] [-strict-enums] [-strict-nulls] [-runtime-role] [-advise] [-advise-explain]
+	[-advise-min-rows] [-advise-o] [--config]
+       gosql enums [-db] [-cache] [-enums-o] [-enums-pkg] [--config]
+       gosql models [-db] [-cache] [-models-rel] [-models-o] [-models-pkg] [-models-null]
+	[-models-name] [-models-singular] [--config]
+       gosql scaffold -table [-db] [-cache] [-scaffold-o] [-models-o] [-models-pkg]
+	[-models-name] [-models-singular] [--config]
+       gosql drift [-db] [-wd] [-r] [-f] [-rx] [-cache] [-drift-suggest] [-drift-json] [--config]
 
 gosql generates SQL queries based .... (todo: write doc)
 
 
 The -db flag specifies the connection string of the database that the tool should
 used for type checking. This value is required.
+Additional databases can be named by the "databases" option of the config file,
+a query type is then type checked against the database named by the type's
+"//gosql:db <name>" comment, or against the database whose packages or file
+regexps match the type's package or file, or else against the -db database.
 
 
 The -wd flag specifies the directory whose files the tool will process. When used
@@ -79,4 +91,164 @@ represented by it must implement the following interface:
     }
 If left unspecified, the type gosql.Conn will be used by default.
 
+
+The -cache flag instructs the tool to cache the database's catalog information in
+the user's cache directory, which speeds up subsequent runs against the same database.
+The cache is invalidated once the cached database objects are modified.
+The cache is enabled by default, use -cache=false to disable it.
+
+
+The -verify flag instructs the tool to have the database server prepare each of
+the generated queries, inside a transaction that is rolled back, before writing the
+output files. The server's errors, and the parameter types it infers that do not match
+the Go types of the corresponding fields, are reported as type checking errors. Queries
+with a filter are verified with an empty filter, and those with slice arguments with a
+single element per slice. Queries that insert or update a slice of records are not
+verified, they are reported as skipped.
+The verification is enabled by default, use -verify=false to disable it.
+
+
+The -j flag specifies the maximum number of files that the tool will analyze, type check,
+and generate concurrently. The output files, and the order in which errors are reported,
+do not depend on this value. If left unspecified, the number of CPUs will be used by default.
+
+
+The -check flag instructs the tool to compare the generated code with the current
+content of the output files instead of writing it to them. A unified diff of each
+output file that is stale, or that is orphaned because its input file no longer
+exists or no longer contains any target types, is written to stdout and the tool
+exits with status 1. Nothing is written to the output files. If left unspecified,
+the value false will be used by default.
+
+
+The -cleanup flag instructs the tool to remove, after writing the output files, the
+orphaned output files, i.e. the generated files, named according to the -o flag's format,
+whose input files no longer exist or were searched and no longer contain any target types.
+The output files of input files that were not searched, e.g. because of the -f and -rx
+flags or of build constraints, and the files generated by "gosql enums" and "gosql models",
+are never removed. The -dry-run flag instructs the tool to only list the orphaned files
+on stdout instead of removing them. If left unspecified, the value false will be used
+by default for both flags.
+
+
+The -strict-enums flag instructs the type checker to require fields that are mapped
+to enum columns to be of the Go type generated for that enum by the "gosql enums"
+command, or of the Go type declared for it in the "enum_types" config setting.
+
+
+The -strict-nulls flag instructs the type checker to reject fields that read NULLable
+columns unless the field's type can hold NULL, e.g. a pointer or a type that implements
+sql.Scanner, or the field's tag has the "coalesce" or "nn" option. The NULLability of
+a view's columns is inferred from the columns of the view's base tables.
+
+
+The -runtime-role flag specifies the role with which the application executes the generated
+queries, if it differs from the role used by the tool. The type checker then reports an error
+for each column and relation that a query selects, inserts, updates, or deletes, including
+the RETURNING columns and the joined relations, on which the role lacks the privilege.
+
+
+The -advise flag enables the index advisor. The advisor notes each column that a query
+filters, joins, or sorts by, and that is not the leading key column of any of the table's
+indexes, as well as each column filtered by a function, e.g. "@lower", for which the table
+has no matching expression index. The notes do not cause the tool to fail, they are
+written to a JSON report file.
+
+
+The -advise-explain flag instructs the index advisor to also have the database server
+EXPLAIN the generic plan of each generated query whose SQL is fully known at generation
+time, and to note each sequential scan on a table whose estimated number of rows is at
+least the value of the -advise-min-rows flag, which is 10000 by default.
+
+
+The -advise-o flag specifies the file to which the index advisor will write its report.
+If left unspecified, the report will be written to "gosql_advice.json".
+
+
+The enums subcommand generates a Go string type for each enum type of the database,
+together with constants for the enum's labels, and the Valid, Scan, and Value methods.
+
+
+The -enums-o flag specifies the file to which the enums subcommand will write the
+generated code. If left unspecified, the file "enums_gosql.go" will be used by default.
+
+
+The -enums-pkg flag specifies the package name of the code generated by the enums
+subcommand. If left unspecified, the package name declared in the output file's
+directory, or the name of that directory, will be used by default.
+
+
+The models subcommand generates a Go struct type for each table and view of the
+database, with a field for each of the relation's columns, and writes them to a file
+whose content should not be edited by hand. Methods of the generated types can be
+declared in other files of the same package. The file is left untouched if its
+content would not change.
+
+
+The -models-rel flag specifies a "[schema.]name" pattern of the tables and views for
+which the models subcommand will generate struct types. A pattern without a schema
+matches only the relations in the search_path. The flag can be used more than once
+to specify multiple patterns. If left unspecified, all the relations in the search_path
+will be used.
+
+
+The -models-o flag specifies the file to which the models subcommand will write the
+generated code. If left unspecified, the file "models_gosql.go" will be used by default.
+
+
+The -models-pkg flag specifies the package name of the code generated by the models
+subcommand. If left unspecified, the package name declared in the output file's
+directory, or the name of that directory, will be used by default.
+
+
+The -models-null flag specifies how the fields of columns that can be NULL are
+generated, "pointer" uses a pointer type, "sqlnull" uses a database/sql Null type
+where available, and "coalesce" adds the coalesce option to the field's tag. If
+left unspecified, "pointer" will be used by default.
+
+
+The -models-name flag specifies the format of the generated types' names, the "%s"
+verb is replaced by the Go name of the relation. If left unspecified, "%s" will be
+used by default.
+
+
+The -models-singular flag instructs the models subcommand to use the singular form
+of the relation's name, e.g. "user_addresses" => "UserAddress". If left unspecified,
+true will be used by default.
+
+
+The scaffold subcommand generates the query types for the common operations on
+a single table: selecting a record by its primary key, selecting a filtered list
+of records, inserting, updating, and deleting a record, and an upsert that uses
+the table's unique constraint. The query types use the struct type generated for
+the table by the models subcommand. The generated file is meant to be edited and
+is then processed like any other input file.
+
+
+The -table flag specifies the "[schema.]name" identifier of the table for which the
+scaffold subcommand will generate query types.
+
+
+The -scaffold-o flag specifies the file to which the scaffold subcommand will write
+the generated code, the "%s" verb is replaced by the table's name. The subcommand
+does not overwrite an existing file. If left unspecified, the file "<table>_queries.go"
+in the directory of the -models-o file will be used by default.
+
+
+The drift subcommand compares the relation types of the input files' query and
+filter types with the relations of the database, without generating any code. It
+reports the fields whose columns were dropped, the fields whose types are no longer
+compatible with their columns, the fields that cannot hold the NULL of their columns,
+the relations that were dropped, and the columns that are not mapped to any field.
+The subcommand exits with status 1 if it found any drift.
+
+
+The -drift-suggest flag instructs the drift subcommand to include, with each reported
+drift, the suggested changes to the struct field, or to the database, that would
+resolve it. If left unspecified, the value false will be used by default.
+
+
+The -drift-json flag instructs the drift subcommand to write its report as JSON
+instead of text. If left unspecified, the value false will be used by default.
+
 ` //`
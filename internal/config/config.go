@@ -9,6 +9,7 @@ import (
 	"go/token"
 	"go/types"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -46,6 +47,12 @@ type Config struct {
 	// The connection string of the database that will be used for type checking.
 	// This value is required.
 	DatabaseDSN String `json:"database_dsn"`
+	// A list of additional, named, databases. A query type is type checked
+	// against the database named by the type's "gosql:db" directive, e.g.
+	// "//gosql:db analytics", or else against the first database whose package
+	// paths or file regexps match the type's package or file, or else against
+	// the database specified by DatabaseDSN.
+	Databases []Database `json:"databases"`
 	// If set to true, the generator will quote postgres identifiers like
 	// column names, table names, etc.
 	QuoteIdentifiers Bool `json:"quote_identifiers"`
@@ -99,6 +106,181 @@ type Config struct {
 	// filter value converting functions to specific types.
 	FilterValueConverters []FilterValueConverter `json:"filter_value_converters"`
 
+	// A list of relations whose records are "soft-deleted" by setting the
+	// associated column instead of being removed from the table. This is
+	// equivalent to declaring the gosql.SoftDelete directive in the relation's
+	// Go type but it can also be applied to relations referenced only by joins,
+	// or by queries that have no relation type, like SelectCount.
+	SoftDeleteRelations []SoftDeleteRelation `json:"soft_delete_relations"`
+
+	// If set to true, the database's catalog information, together with the
+	// information on the relations referenced by the processed types, will
+	// be cached in the user's cache directory. The cached information is
+	// invalidated once the corresponding database objects are modified.
+	//
+	// If not provided, `true` will be used by default.
+	CatalogCache Bool `json:"catalog_cache"`
+	// If set to true, each generated query will be prepared by the database
+	// server, inside a transaction that is rolled back, before the output
+	// files are written. This catches errors the type checker misses and also
+	// compares the types the server infers for the query's parameters to the
+	// Go types of the corresponding fields. Queries with a filter are prepared
+	// with an empty filter and those with slice arguments with one element
+	// per slice; queries that insert or update a slice of records are skipped
+	// and reported.
+	//
+	// If not provided, `true` will be used by default.
+	VerifyQueries Bool `json:"verify_queries"`
+	// The maximum number of files that will be analyzed, type checked,
+	// and generated concurrently. The output is the same regardless of
+	// the number of workers.
+	//
+	// If not provided, or if less than 1, the number of logical CPUs
+	// usable by the current process will be used by default.
+	Jobs Int `json:"jobs"`
+	// If set to true, the generated code will not be written to the output
+	// files, instead it will be compared with the current content of those
+	// files and a diff of each file that is stale will be reported. The output
+	// files whose input files no longer exist, or no longer contain any target
+	// types, are reported as stale as well.
+	//
+	// If not provided, `false` will be used by default.
+	CheckOutput Bool `json:"check_output"`
+	// If set to true, the output files whose input files no longer exist, or
+	// no longer contain any target types, will be removed after the output
+	// files are written.
+	//
+	// If not provided, `false` will be used by default.
+	Cleanup Bool `json:"cleanup"`
+	// If set to true, the output files that would be removed by Cleanup
+	// will be listed instead of removed.
+	//
+	// If not provided, `false` will be used by default.
+	CleanupDryRun Bool `json:"cleanup_dry_run"`
+
+	// If set to true, the type checker will require fields that are mapped
+	// to enum columns to be of the Go type generated for that enum by the
+	// "gosql enums" command, or of the type declared for it in EnumTypes.
+	//
+	// If not provided, `false` will be used by default.
+	StrictEnums Bool `json:"strict_enums"`
+	// If set to true, the type checker will reject fields that read NULLable
+	// columns unless the field's type can hold NULL, e.g. a pointer or an
+	// sql.Scanner, or the field's tag has the "coalesce" or "nn" option.
+	//
+	// If not provided, `false` will be used by default.
+	StrictNulls Bool `json:"strict_nulls"`
+	// The name of the role with which the application executes the generated
+	// queries. If set, the type checker will require the role to have the
+	// privileges to SELECT, INSERT, UPDATE, or DELETE the columns and relations
+	// referenced by each query, including the RETURNING columns and the joined
+	// relations.
+	RuntimeRole String `json:"runtime_role"`
+	// If set to true, the type checker will note each column that a query's
+	// where struct, join conditions, or gosql.OrderBy directive filters, joins,
+	// or sorts by, and that is not the leading key column of any index, as well
+	// as each column that is filtered by a function call, e.g. "@lower", for
+	// which there is no expression index. The notes do not cause the tool
+	// to fail, they are written to the IndexAdvisorReport file.
+	//
+	// If not provided, `false` will be used by default.
+	IndexAdvisor Bool `json:"index_advisor"`
+	// If set to true, together with IndexAdvisor, the tool will also have
+	// the database server EXPLAIN the generic plan of each generated query
+	// whose SQL string is fully known at generation time, and it will note
+	// each sequential scan on a table with at least IndexAdvisorMinRows rows.
+	//
+	// If not provided, `false` will be used by default.
+	IndexAdvisorExplain Bool `json:"index_advisor_explain"`
+	// The estimated number of rows that a table must have for a sequential
+	// scan on that table to be noted by the index advisor.
+	//
+	// If not provided, `10000` will be used by default.
+	IndexAdvisorMinRows Int `json:"index_advisor_min_rows"`
+	// The name of the JSON file to which the index advisor's notes will be
+	// written. A relative path is resolved against the working directory.
+	//
+	// If not provided, the name "gosql_advice.json" will be used by default.
+	IndexAdvisorReport String `json:"index_advisor_report"`
+	// A list of enum types mapped to the Go types that represent them, used
+	// by the type checker in strict enums mode for enums whose Go type was
+	// not generated by the "gosql enums" command.
+	EnumTypes []EnumType `json:"enum_types"`
+	// The name of the file to which the "gosql enums" command will write
+	// the generated enum types. The file's directory is also used to determine
+	// the package name of the generated code, unless EnumsPackageName is set.
+	//
+	// If not provided, the name "enums_gosql.go" will be used by default.
+	EnumsOutputFile String `json:"enums_output_file"`
+	// The package name to be used for the code generated by the "gosql enums" command.
+	EnumsPackageName String `json:"enums_package_name"`
+	// A list of "[schema.]name" patterns of the tables and views for which
+	// the "gosql models" command will generate struct types. The schema and
+	// the name are matched as by path.Match, a pattern without a schema
+	// matches only the relations that are visible in the search_path.
+	//
+	// If not provided, all the relations in the search_path will be used.
+	ModelsRelations StringSlice `json:"models_relations"`
+	// The name of the file to which the "gosql models" command will write
+	// the generated struct types. The file's directory is also used to determine
+	// the package name of the generated code, unless ModelsPackageName is set.
+	//
+	// If not provided, the name "models_gosql.go" will be used by default.
+	ModelsOutputFile String `json:"models_output_file"`
+	// The package name to be used for the code generated by the "gosql models" command.
+	ModelsPackageName String `json:"models_package_name"`
+	// Specifies how the "gosql models" command represents the columns that
+	// can be NULL. The value "pointer" makes the field's type a pointer type,
+	// the value "sqlnull" uses the database/sql package's Null types where
+	// one is available, and a pointer type otherwise, and the value "coalesce"
+	// keeps the field's type and adds the "coalesce" option to its tag.
+	//
+	// The fields of slice, map, and interface types are never modified.
+	//
+	// If not provided, "pointer" will be used by default.
+	ModelsNULLable String `json:"models_nullable"`
+	// The format used by the "gosql models" command to produce the name of
+	// the struct type from the relation's name, the format must contain
+	// exactly one "%s" verb, a format without one is used as a suffix.
+	//
+	// If not provided, the format "%s" will be used by default.
+	ModelsTypeNameFormat String `json:"models_type_name_format"`
+	// If set, the "gosql models" command will use the singular form
+	// of the relation's name to produce the name of the struct type,
+	// e.g. "user_addresses" => "UserAddress".
+	//
+	// If not provided, `true` will be used by default.
+	ModelsSingular Bool `json:"models_singular"`
+	// The "[schema.]name" identifier of the table for which the "gosql scaffold"
+	// command will generate the query types, an identifier without a schema is
+	// resolved against the search_path.
+	ScaffoldTable String `json:"scaffold_table"`
+	// The name of the file to which the "gosql scaffold" command will write the
+	// generated query types, the "%s" verb, if present, is replaced by the table's
+	// name. The command does not overwrite an existing file.
+	//
+	// If not provided, the file "<table>_queries.go" in the directory of
+	// the ModelsOutputFile will be used by default.
+	ScaffoldOutputFile String `json:"scaffold_output_file"`
+	// If set, the "gosql drift" command will include, with each drift
+	// it reports, the ALTER TABLE statements, or the struct field patches,
+	// that would resolve the drift.
+	//
+	// If not provided, `false` will be used by default.
+	DriftSuggest Bool `json:"drift_suggest"`
+	// If set, the "gosql drift" command will write its report in JSON.
+	//
+	// If not provided, `false` will be used by default.
+	DriftJSON Bool `json:"drift_json"`
+	// A list of Go packages, or input file paths, mapped to the schemas
+	// against which the relation identifiers that are not qualified with a
+	// schema name will be resolved by the type checker, instead of against
+	// the database's search_path. The generated queries of the matching files
+	// will reference those relations by their schema-qualified names.
+	//
+	// The first entry that matches an input file is used.
+	DefaultSchemas []DefaultSchema `json:"default_schemas"`
+
 	// holds the compiled expressions of the InputFileRegexps slice.
 	compiledInputFileRegexps []*regexp.Regexp
 }
@@ -110,6 +292,99 @@ type FilterValueConverter struct {
 	Func ObjectIdent `json:"func"`
 }
 
+type SoftDeleteRelation struct {
+	// The relation identifier of the soft-deleted relation,
+	// the format is: [schema_name.]relation_name
+	Relation String `json:"relation"`
+	// The name of the column that is used to mark records as deleted.
+	Column String `json:"column"`
+}
+
+type EnumType struct {
+	// The name of the enum type as stored in "pg_type.typname".
+	Enum String `json:"enum"`
+	// The Go type that should be used for the enum type.
+	Type ObjectIdent `json:"type"`
+}
+
+type Database struct {
+	// The name of the database, used by the "gosql:db" directive
+	// and by error messages to refer to the database.
+	Name String `json:"name"`
+	// The connection string of the database.
+	DSN String `json:"dsn"`
+	// List of import paths of the Go packages whose query types will be
+	// type checked against the database. Each path can be a pattern as
+	// accepted by path.Match, and if it ends with "/..." it also matches
+	// the packages in the subdirectories of that path.
+	Packages StringSlice `json:"packages"`
+	// List of regular expressions to match the input files whose query
+	// types will be type checked against the database.
+	FileRegexps StringSlice `json:"file_regexps"`
+
+	// holds the compiled expressions of the FileRegexps slice.
+	compiledFileRegexps []*regexp.Regexp
+}
+
+// Match reports whether or not the database applies to the input
+// file at the given path which belongs to the package at pkgPath.
+func (db Database) Match(pkgPath, filePath string) bool {
+	for _, pattern := range db.Packages.Value {
+		if matchPattern(pattern, pkgPath, "/", path.Match) {
+			return true
+		}
+	}
+	for _, rx := range db.compiledFileRegexps {
+		if rx.MatchString(filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+type DefaultSchema struct {
+	// The import path of the Go package to which the schema applies. The path
+	// can be a pattern as accepted by path.Match, and if it ends with "/..."
+	// it also matches the packages in the subdirectories of that path.
+	Package String `json:"package"`
+	// The path of the input files to which the schema applies. The path can be
+	// a pattern as accepted by filepath.Match, and if it ends with "/..." it
+	// matches all the files in that directory and its subdirectories.
+	// A relative path is resolved against the working directory.
+	Path String `json:"path"`
+	// The name of the schema.
+	Schema String `json:"schema"`
+}
+
+// Match reports whether or not the default schema entry applies to the
+// input file at the given path which belongs to the package at pkgPath.
+func (ds DefaultSchema) Match(pkgPath, filePath string) bool {
+	if len(ds.Package.Value) > 0 {
+		return matchPattern(ds.Package.Value, pkgPath, "/", path.Match)
+	}
+	if len(ds.Path.Value) > 0 {
+		return matchPattern(ds.Path.Value, filePath, string(filepath.Separator), filepath.Match)
+	}
+	return false
+}
+
+// matchPattern reports whether or not the given name matches the pattern. If
+// the pattern ends with sep+"..." the rest of the pattern is matched against
+// the name and against each of the name's parent paths.
+func matchPattern(pattern, name, sep string, match func(pattern, name string) (bool, error)) bool {
+	pattern, subtree := strings.CutSuffix(pattern, sep+"...")
+	for {
+		if ok, _ := match(pattern, name); ok {
+			return true
+		}
+		i := strings.LastIndex(name, sep)
+		if !subtree || i <= 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
 var DefaultConfig = Config{
 	WorkingDirectory:         String{Value: "."},
 	Recursive:                Bool{Value: false},
@@ -123,6 +398,30 @@ var DefaultConfig = Config{
 	FilterColumnKeySeparator: String{Value: "."},
 	MethodName:               String{Value: "Exec"},
 	MethodWithContext:        Bool{Value: false},
+	CatalogCache:             Bool{Value: true},
+	VerifyQueries:            Bool{Value: true},
+	Jobs:                     Int{Value: 0},
+	CheckOutput:              Bool{Value: false},
+	Cleanup:                  Bool{Value: false},
+	CleanupDryRun:            Bool{Value: false},
+	StrictEnums:              Bool{Value: false},
+	StrictNulls:              Bool{Value: false},
+	RuntimeRole:              String{Value: ""},
+	IndexAdvisor:             Bool{Value: false},
+	IndexAdvisorExplain:      Bool{Value: false},
+	IndexAdvisorMinRows:      Int{Value: 10000},
+	IndexAdvisorReport:       String{Value: "gosql_advice.json"},
+	EnumsOutputFile:          String{Value: "enums_gosql.go"},
+	EnumsPackageName:         String{Value: ""},
+	ModelsOutputFile:         String{Value: "models_gosql.go"},
+	ModelsPackageName:        String{Value: ""},
+	ModelsNULLable:           String{Value: "pointer"},
+	ModelsTypeNameFormat:     String{Value: "%s"},
+	ModelsSingular:           Bool{Value: true},
+	ScaffoldTable:            String{Value: ""},
+	ScaffoldOutputFile:       String{Value: ""},
+	DriftSuggest:             Bool{Value: false},
+	DriftJSON:                Bool{Value: false},
 	MethodArgumentType: GoType{
 		Name:    "Conn",
 		PkgPath: "github.com/frk/gosql",
@@ -130,8 +429,19 @@ var DefaultConfig = Config{
 	},
 }
 
+// The name of the subcommand with which the tool was invoked, or empty
+// if the tool was invoked without one.
+//
+// NOTE This value is set by the ParseFlags method.
+var Subcommand string
+
 // ParseFlags unmarshals the cli flags into the receiver.
 func (c *Config) ParseFlags(printUsage func()) {
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		Subcommand, args = args[0], args[1:]
+	}
+
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.Usage = printUsage
 	fs.Var(&c.WorkingDirectory, "wd", "")
@@ -146,8 +456,33 @@ func (c *Config) ParseFlags(printUsage func()) {
 	fs.Var(&c.FilterColumnKeySeparator, "fcksep", "")
 	fs.Var(&c.MethodWithContext, "with-ctx", "")
 	fs.Var(&c.MethodArgumentType, "argtype", "")
+	fs.Var(&c.CatalogCache, "cache", "")
+	fs.Var(&c.VerifyQueries, "verify", "")
+	fs.Var(&c.Jobs, "j", "")
+	fs.Var(&c.CheckOutput, "check", "")
+	fs.Var(&c.Cleanup, "cleanup", "")
+	fs.Var(&c.CleanupDryRun, "dry-run", "")
+	fs.Var(&c.StrictEnums, "strict-enums", "")
+	fs.Var(&c.StrictNulls, "strict-nulls", "")
+	fs.Var(&c.RuntimeRole, "runtime-role", "")
+	fs.Var(&c.IndexAdvisor, "advise", "")
+	fs.Var(&c.IndexAdvisorExplain, "advise-explain", "")
+	fs.Var(&c.IndexAdvisorMinRows, "advise-min-rows", "")
+	fs.Var(&c.IndexAdvisorReport, "advise-o", "")
+	fs.Var(&c.EnumsOutputFile, "enums-o", "")
+	fs.Var(&c.EnumsPackageName, "enums-pkg", "")
+	fs.Var(&c.ModelsRelations, "models-rel", "")
+	fs.Var(&c.ModelsOutputFile, "models-o", "")
+	fs.Var(&c.ModelsPackageName, "models-pkg", "")
+	fs.Var(&c.ModelsNULLable, "models-null", "")
+	fs.Var(&c.ModelsTypeNameFormat, "models-name", "")
+	fs.Var(&c.ModelsSingular, "models-singular", "")
+	fs.Var(&c.ScaffoldTable, "table", "")
+	fs.Var(&c.ScaffoldOutputFile, "scaffold-o", "")
+	fs.Var(&c.DriftSuggest, "drift-suggest", "")
+	fs.Var(&c.DriftJSON, "drift-json", "")
 	fs.StringVar(&ConfigFile, "config", "", "The filepath to a specific configuration file")
-	_ = fs.Parse(os.Args[1:])
+	_ = fs.Parse(args)
 }
 
 // The filepath to the config file which will be used by ParseFile to load the configuration.
@@ -238,13 +573,33 @@ func (c *Config) Validate() (err error) {
 	if len(c.DatabaseDSN.Value) == 0 {
 		return fmt.Errorf("missing database connection string")
 	}
-	db, err := sql.Open("postgres", c.DatabaseDSN.Value)
-	if err != nil {
-		return fmt.Errorf("error opening database: %q -- %v", c.DatabaseDSN.Value, err)
-	} else {
-		defer db.Close()
-		if err := db.Ping(); err != nil {
-			return fmt.Errorf("error connecting to database: %q -- %v", c.DatabaseDSN.Value, err)
+	if err := pingDatabase(c.DatabaseDSN.Value); err != nil {
+		return err
+	}
+
+	// check that each of the named databases has a unique name, and
+	// that its dsn can be used to initialize connections
+	names := make(map[string]bool, len(c.Databases))
+	for i, db := range c.Databases {
+		if len(db.Name.Value) == 0 || names[db.Name.Value] {
+			return fmt.Errorf("bad database name: %q", db.Name.Value)
+		}
+		names[db.Name.Value] = true
+
+		if len(db.DSN.Value) == 0 {
+			return fmt.Errorf("missing connection string of database: %q", db.Name.Value)
+		}
+		if err := pingDatabase(db.DSN.Value); err != nil {
+			return err
+		}
+
+		c.Databases[i].compiledFileRegexps = make([]*regexp.Regexp, len(db.FileRegexps.Value))
+		for j, expr := range db.FileRegexps.Value {
+			rx, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("error compiling regular expression: %q -- %v", expr, err)
+			}
+			c.Databases[i].compiledFileRegexps[j] = rx
 		}
 	}
 
@@ -292,6 +647,51 @@ func (c *Config) Validate() (err error) {
 		}
 	}
 
+	// check that each soft delete entry specifies both the relation and the column
+	for _, sd := range c.SoftDeleteRelations {
+		if len(sd.Relation.Value) == 0 || len(sd.Column.Value) == 0 {
+			return fmt.Errorf("bad soft delete relation: relation=%q column=%q", sd.Relation.Value, sd.Column.Value)
+		}
+	}
+
+	// check that each default schema entry specifies the schema and either
+	// the package or the path, and update the file paths to absolutes
+	for i, ds := range c.DefaultSchemas {
+		if len(ds.Schema.Value) == 0 || (len(ds.Package.Value) > 0) == (len(ds.Path.Value) > 0) {
+			return fmt.Errorf("bad default schema: package=%q path=%q schema=%q",
+				ds.Package.Value, ds.Path.Value, ds.Schema.Value)
+		}
+		if len(ds.Path.Value) > 0 {
+			abs, err := filepath.Abs(ds.Path.Value)
+			if err != nil {
+				return fmt.Errorf("error resolving absolute path of default schema: %q -- %v", ds.Path.Value, err)
+			}
+			c.DefaultSchemas[i].Path.Value = abs
+		}
+	}
+
+	// check that each enum type entry specifies both the enum and the Go type
+	for _, et := range c.EnumTypes {
+		if len(et.Enum.Value) == 0 || !et.Type.IsSet {
+			return fmt.Errorf("bad enum type: enum=%q type=%q", et.Enum.Value, et.Type)
+		}
+	}
+
+	// check the models configuration for errors
+	switch c.ModelsNULLable.Value {
+	case "pointer", "sqlnull", "coalesce":
+	default:
+		return fmt.Errorf("bad models nullable mode: %q", c.ModelsNULLable.Value)
+	}
+	if n := strings.Count(c.ModelsTypeNameFormat.Value, "%"); n == 0 {
+		c.ModelsTypeNameFormat.Value = "%s" + c.ModelsTypeNameFormat.Value
+	} else if n > 1 || (n == 1 && !strings.Contains(c.ModelsTypeNameFormat.Value, "%s")) {
+		return fmt.Errorf("bad models type name format: %q", c.ModelsTypeNameFormat.Value)
+	}
+	if n := strings.Count(c.ScaffoldOutputFile.Value, "%"); n > 1 || (n == 1 && !strings.Contains(c.ScaffoldOutputFile.Value, "%s")) {
+		return fmt.Errorf("bad scaffold output file: %q", c.ScaffoldOutputFile.Value)
+	}
+
 	//
 	// TODO: needs to confirm the validity of the types & functions
 	//
@@ -303,6 +703,19 @@ func (c *Config) Validate() (err error) {
 	return nil
 }
 
+// pingDatabase checks that the given dsn can be used to initialize connections.
+func pingDatabase(dsn string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("error opening database: %q -- %v", dsn, err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("error connecting to database: %q -- %v", dsn, err)
+	}
+	return nil
+}
+
 // examineDir reports if the directory at the given path is the root directory
 // of a git project and if it is, it will also report the name of the gosql config
 // file (either ".gosql" or ".gosql.json") if such a file exists in that root
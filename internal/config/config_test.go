@@ -0,0 +1,81 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestDatabaseMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages []string
+		regexps  []string
+		pkgPath  string
+		filePath string
+		want     bool
+	}{{
+		name:     "package exact",
+		packages: []string{"example.com/app/reports"},
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries.go",
+		want:     true,
+	}, {
+		name:     "package glob",
+		packages: []string{"example.com/app/*"},
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries.go",
+		want:     true,
+	}, {
+		name:     "package glob does not match subpackage",
+		packages: []string{"example.com/app/*"},
+		pkgPath:  "example.com/app/reports/daily",
+		filePath: "/src/app/reports/daily/queries.go",
+		want:     false,
+	}, {
+		name:     "package subtree",
+		packages: []string{"example.com/app/..."},
+		pkgPath:  "example.com/app/reports/daily",
+		filePath: "/src/app/reports/daily/queries.go",
+		want:     true,
+	}, {
+		name:     "package subtree does not match prefix",
+		packages: []string{"example.com/app/..."},
+		pkgPath:  "example.com/application",
+		filePath: "/src/application/queries.go",
+		want:     false,
+	}, {
+		name:     "file regexp",
+		regexps:  []string{`_analytics\.go$`},
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries_analytics.go",
+		want:     true,
+	}, {
+		name:     "file regexp after package mismatch",
+		packages: []string{"example.com/other"},
+		regexps:  []string{`/reports/`},
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries.go",
+		want:     true,
+	}, {
+		name:     "no match",
+		packages: []string{"example.com/other/..."},
+		regexps:  []string{`_analytics\.go$`},
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries.go",
+		want:     false,
+	}, {
+		name:     "no patterns",
+		pkgPath:  "example.com/app/reports",
+		filePath: "/src/app/reports/queries.go",
+		want:     false,
+	}}
+	for _, tt := range tests {
+		db := Database{Packages: StringSlice{Value: tt.packages}}
+		for _, expr := range tt.regexps {
+			db.compiledFileRegexps = append(db.compiledFileRegexps, regexp.MustCompile(expr))
+		}
+		if got := db.Match(tt.pkgPath, tt.filePath); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
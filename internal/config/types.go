@@ -103,6 +103,54 @@ func (b *Bool) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Int implements both the flag.Value and the json.Unmarshal interfaces
+// enforcing priority of flags over json, meaning that json.Unmarshal will
+// not override the value if it was previously set by flag.Var.
+type Int struct {
+	Value int
+	IsSet bool
+}
+
+// Get implements the flag.Getter interface.
+func (i Int) Get() interface{} {
+	return i.Value
+}
+
+// String implements the flag.Value interface.
+func (i Int) String() string {
+	return strconv.Itoa(i.Value)
+}
+
+// Set implements the flag.Value interface.
+func (i *Int) Set(value string) error {
+	if len(value) > 0 {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		i.Value = v
+		i.IsSet = true
+	}
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (i *Int) UnmarshalJSON(data []byte) error {
+	if !i.IsSet {
+		if len(data) == 0 || string(data) == `null` {
+			return nil
+		}
+
+		var value int
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		i.Value = value
+		i.IsSet = true
+	}
+	return nil
+}
+
 // StringSlice implements both the flag.Value and the json.Unmarshal interfaces
 // enforcing priority of flags over json, meaning that json.Unmarshal will
 // not override the value if it was previously set by flag.Var.
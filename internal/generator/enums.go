@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/frk/gosql/internal/postgres"
+
+	GO "github.com/frk/ast/golang"
+)
+
+// WriteEnums generates a Go string type for each of the given enum types
+// together with a constant for each of the enum's labels and the Valid,
+// Scan, and Value methods, and writes the result to f. An error is returned
+// if any two of the generated types and constants would have the same name.
+func WriteEnums(f io.Writer, pkgName string, enums []*postgres.Type) error {
+	if err := checkEnumIdents(enums); err != nil {
+		return err
+	}
+
+	file := new(file)
+	for _, typ := range enums {
+		buildEnumCode(file, typ)
+	}
+
+	file.PkgName = pkgName
+	file.Preamble = GO.LineComment{filePreamble}
+	if len(file.impset) > 0 {
+		file.Imports = []GO.ImportDeclNode{newImportDecl(file)}
+	}
+	return GO.Write(file, f)
+}
+
+// checkEnumIdents checks that the names of the Go types and constants generated
+// for the given enums are unique. Different enum names, or labels, can map to the
+// same identifier since their non-alphanumeric characters are dropped, e.g. both
+// "in-progress" and "in_progress" map to "InProgress".
+func checkEnumIdents(enums []*postgres.Type) error {
+	idents := make(map[string]string)
+	add := func(ident, desc string) error {
+		if other, ok := idents[ident]; ok {
+			return fmt.Errorf("the Go identifier %q of %s conflicts with that of %s", ident, desc, other)
+		}
+		idents[ident] = desc
+		return nil
+	}
+
+	for _, typ := range enums {
+		if err := add(typ.EnumGoName(), fmt.Sprintf("enum type %q", typ.Name)); err != nil {
+			return err
+		}
+		for _, label := range typ.EnumLabels {
+			desc := fmt.Sprintf("label %q of enum type %q", label, typ.Name)
+			if err := add(typ.EnumLabelGoName(label), desc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildEnumCode builds the declarations of the Go type
+// of the given enum type and appends them to the file.
+func buildEnumCode(f *file, typ *postgres.Type) {
+	var (
+		name   = typ.EnumGoName()
+		recv   = GO.Ident{"e"}
+		consts = make(GO.ExprList, len(typ.EnumLabels))
+	)
+
+	typedecl := GO.TypeDecl{}
+	typedecl.Doc = GO.LineComment{" " + name + " represents the labels of the \"" + typ.Name + "\" enum type."}
+	typedecl.Spec = GO.TypeSpec{Name: GO.Ident{name}, Type: GO.Ident{"string"}}
+	f.Decls = append(f.Decls, typedecl)
+
+	if len(typ.EnumLabels) > 0 {
+		specs := make(GO.ValueSpecList, len(typ.EnumLabels))
+		for i, label := range typ.EnumLabels {
+			id := GO.Ident{typ.EnumLabelGoName(label)}
+			specs[i] = GO.ValueSpec{Names: id, Type: GO.Ident{name}, Values: GO.ValueLit(strconv.Quote(label))}
+			consts[i] = id
+		}
+		f.Decls = append(f.Decls, GO.ConstDecl{Spec: specs})
+	}
+
+	f.Decls = append(f.Decls, makeEnumValidMethod(typ, recv, consts))
+	f.Decls = append(f.Decls, makeEnumScanMethod(f, typ, recv))
+	f.Decls = append(f.Decls, makeEnumValueMethod(f, typ, recv))
+}
+
+// makeEnumValidMethod produces the Valid method of the given enum's Go type.
+func makeEnumValidMethod(typ *postgres.Type, recv GO.Ident, consts GO.ExprList) GO.MethodDecl {
+	name := typ.EnumGoName()
+
+	method := GO.MethodDecl{}
+	method.Doc = GO.LineComment{" Valid reports whether or not " + recv.Name + " is a label of the \"" + typ.Name + "\" enum type."}
+	method.Recv.Name = recv
+	method.Recv.Type = GO.Ident{name}
+	method.Name.Name = "Valid"
+	method.Type.Results = GO.ParamList{{Type: GO.Ident{"bool"}}}
+
+	if len(consts) > 0 {
+		method.Body.List = append(method.Body.List, GO.SwitchStmt{X: recv, Cases: []GO.CaseClause{{
+			List: consts,
+			Body: []GO.StmtNode{GO.ReturnStmt{GO.Ident{"true"}}},
+		}}})
+	}
+	method.Body.List = append(method.Body.List, GO.ReturnStmt{GO.Ident{"false"}})
+	return method
+}
+
+// makeEnumScanMethod produces the Scan method of the given enum's Go type.
+// The method returns an error if the scanned value is not a valid label.
+func makeEnumScanMethod(f *file, typ *postgres.Type, recv GO.Ident) GO.MethodDecl {
+	var (
+		name   = typ.EnumGoName()
+		src    = GO.Ident{"src"}
+		val    = GO.Ident{"v"}
+		nilVal = GO.Ident{"nil"}
+		fmtimp = addimport(f, "fmt", "fmt")
+		errorf = GO.QualifiedIdent{fmtimp.name, "Errorf"}
+	)
+
+	assign := func(x GO.ExprNode) GO.StmtNode {
+		return GO.AssignStmt{Token: GO.Assign, Lhs: GO.PointerIndirectionExpr{recv}, Rhs: x}
+	}
+
+	method := GO.MethodDecl{}
+	method.Doc = GO.LineComment{" Scan implements the sql.Scanner interface."}
+	method.Recv.Name = recv
+	method.Recv.Type = GO.PointerRecvType{name}
+	method.Name.Name = "Scan"
+	method.Type.Params = GO.ParamList{{Names: src, Type: GO.Ident{"interface{}"}}}
+	method.Type.Results = GO.ParamList{{Type: GO.Ident{"error"}}}
+	method.Body.List = []GO.StmtNode{
+		GO.TypeSwitchStmt{
+			Guard: GO.TypeSwitchGuard{Name: val, X: src},
+			Cases: []GO.TypeCaseClause{{
+				List: GO.SliceType{GO.Ident{"byte"}},
+				Body: []GO.StmtNode{assign(GO.CallExpr{Fun: GO.Ident{name}, Args: GO.ArgsList{List: val}})},
+			}, {
+				List: GO.Ident{"string"},
+				Body: []GO.StmtNode{assign(GO.CallExpr{Fun: GO.Ident{name}, Args: GO.ArgsList{List: val}})},
+			}, {
+				List: nilVal,
+				Body: []GO.StmtNode{assign(GO.StringLit("")), GO.ReturnStmt{nilVal}},
+			}, {
+				Body: []GO.StmtNode{GO.ReturnStmt{GO.CallExpr{Fun: errorf, Args: GO.ArgsList{
+					List: GO.ExprList{GO.StringLit("cannot scan %T into " + name), src}}}}},
+			}},
+		},
+		GO.IfStmt{
+			Cond: GO.UnaryExpr{Op: GO.UnaryNot, X: GO.CallExpr{Fun: GO.SelectorExpr{X: recv, Sel: GO.Ident{"Valid"}}}},
+			Body: GO.BlockStmt{[]GO.StmtNode{GO.ReturnStmt{GO.CallExpr{Fun: errorf, Args: GO.ArgsList{
+				List: GO.ExprList{GO.StringLit("invalid " + name + " label: %q"), GO.PointerIndirectionExpr{recv}}}}}}},
+		},
+		GO.ReturnStmt{nilVal},
+	}
+	return method
+}
+
+// makeEnumValueMethod produces the Value method of the given enum's Go type.
+// The method returns an error if the receiver is not a valid label.
+func makeEnumValueMethod(f *file, typ *postgres.Type, recv GO.Ident) GO.MethodDecl {
+	var (
+		name   = typ.EnumGoName()
+		nilVal = GO.Ident{"nil"}
+		fmtimp = addimport(f, "fmt", "fmt")
+		drvimp = addimport(f, "database/sql/driver", "driver")
+		errorf = GO.QualifiedIdent{fmtimp.name, "Errorf"}
+	)
+
+	method := GO.MethodDecl{}
+	method.Doc = GO.LineComment{" Value implements the driver.Valuer interface."}
+	method.Recv.Name = recv
+	method.Recv.Type = GO.Ident{name}
+	method.Name.Name = "Value"
+	method.Type.Results = GO.ParamList{{Type: GO.QualifiedIdent{drvimp.name, "Value"}}, {Type: GO.Ident{"error"}}}
+	method.Body.List = []GO.StmtNode{
+		GO.IfStmt{
+			Cond: GO.UnaryExpr{Op: GO.UnaryNot, X: GO.CallExpr{Fun: GO.SelectorExpr{X: recv, Sel: GO.Ident{"Valid"}}}},
+			Body: GO.BlockStmt{[]GO.StmtNode{GO.ReturnStmt{GO.ExprList{nilVal, GO.CallExpr{Fun: errorf, Args: GO.ArgsList{
+				List: GO.ExprList{GO.StringLit("invalid " + name + " label: %q"), GO.CallExpr{Fun: GO.Ident{"string"}, Args: GO.ArgsList{List: recv}}}}}}}}},
+		},
+		GO.ReturnStmt{GO.ExprList{GO.CallExpr{Fun: GO.Ident{"string"}, Args: GO.ArgsList{List: recv}}, nilVal}},
+	}
+	return method
+}
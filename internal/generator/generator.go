@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"bytes"
 	"io"
 	"log"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/frk/gosql/internal/postgres"
 	"github.com/frk/gosql/internal/postgres/oid"
 
+	"github.com/frk/ast"
 	GO "github.com/frk/ast/golang"
 	SQL "github.com/frk/ast/sqlang"
 )
@@ -28,6 +30,12 @@ const (
 	filePreamble = ` DO NOT EDIT. This file was generated by "github.com/frk/gosql".`
 )
 
+// IsGenerated reports whether or not the given source starts with the preamble
+// of the files that are generated by Write, WriteEnums, and WriteModels.
+func IsGenerated(src []byte) bool {
+	return bytes.HasPrefix(src, []byte("//"+filePreamble+"\n"))
+}
+
 func Write(f io.Writer, pkgName string, targets []*postgres.TargetInfo, cfg config.Config) error {
 	var (
 		file        = new(file)
@@ -57,6 +65,139 @@ func Write(f io.Writer, pkgName string, targets []*postgres.TargetInfo, cfg conf
 	return GO.Write(file, f)
 }
 
+// Query holds the SQL string of a query generated for a
+// target type together with the info on the query's parameters.
+type Query struct {
+	// The target for which the query was generated.
+	Info *postgres.TargetInfo
+	// The SQL string of the query.
+	SQL string
+	// The query's parameters, Params[i] is referenced in the SQL string by $(i+1).
+	Params []*postgres.QueryParam
+}
+
+// Queries returns the queries generated for the given targets. The SQL string of
+// a query with a filter is completed with an empty filter and each of the value
+// lists of a query with slice arguments is represented by a single parameter.
+// The targets whose queries insert or update a slice of records, and whose SQL
+// string therefore cannot be completed without the records, are returned as
+// skipped. The targets of filter types are omitted from the result.
+func Queries(targets []*postgres.TargetInfo, cfg config.Config) (queries []*Query, skipped []*postgres.TargetInfo, err error) {
+	fmtColIdent := makeColIdentFormatter(cfg)
+
+	for _, info := range targets {
+		qs, ok := info.Struct.(*analysis.QueryStruct)
+		if !ok {
+			continue
+		}
+
+		g := new(generator)
+		g.cfg = cfg
+		g.info = info
+		g.file = new(file)
+		g.fmtColIdent = fmtColIdent
+		g.queryRecv = GO.Ident{"q"}
+		g.inputPKeys = inputPKeys(info)
+		buildQueryCode(g, qs)
+		if qs.IsInsertOrUpdateSlice() {
+			skipped = append(skipped, info)
+			continue
+		}
+
+		var sb strings.Builder
+		if err := SQL.Write(g.sqlMainNode, &sb); err != nil {
+			return nil, nil, err
+		}
+		if qs.Filter != nil {
+			if g.info.SoftDelete != nil {
+				sb.WriteString(" WHERE ")
+				sb.WriteString(strings.TrimSpace(SQL.MustToString(makeSQLSoftDeleteCondition(g))))
+			}
+			if g.closeFilter {
+				sb.WriteString(")")
+			}
+			if g.sqlTailNode != nil {
+				sb.WriteString(" ")
+				if err := SQL.Write(g.sqlTailNode, &sb); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+
+		q := &Query{Info: info, SQL: sb.String()}
+		q.Params = make([]*postgres.QueryParam, g.paramNum+len(g.inputSliceConds))
+		for ptr, num := range g.paramSet {
+			if !isInputSliceCond(g, ptr) {
+				q.Params[num-1] = makeQueryParam(g, ptr)
+			}
+		}
+		for i, cond := range g.inputSliceConds {
+			// the parameters of the slices follow the static ones
+			num := g.paramNum + i + 1
+			q.SQL = strings.Replace(q.SQL, SQL.MustToString(makeInValueList(i+1)), "$"+strconv.Itoa(num), 1)
+			q.Params[num-1] = makeSliceQueryParam(cond)
+		}
+		for i, p := range q.Params {
+			if p == nil {
+				q.Params[i] = &postgres.QueryParam{}
+			}
+		}
+		queries = append(queries, q)
+	}
+	return queries, skipped, nil
+}
+
+// isInputSliceCond reports whether or not ptr denotes the conditional
+// of one of the slice arguments of the generator's query.
+func isInputSliceCond(g *generator, ptr analysis.FieldPtr) bool {
+	for _, cond := range g.inputSliceConds {
+		if ptr == analysis.FieldPtr(cond) {
+			return true
+		}
+	}
+	return false
+}
+
+// makeSliceQueryParam returns the info on the query parameter that
+// represents an element of the slice argument of the given conditional.
+func makeSliceQueryParam(cond *postgres.FieldConditional) *postgres.QueryParam {
+	p := &postgres.QueryParam{Field: cond.Field}
+	typ := &cond.FieldType
+	if typ.Kind == analysis.TypeKindPtr {
+		typ = typ.Elem
+	}
+	if typ.IsSequence() {
+		p.Type = *typ.Elem
+	}
+	return p
+}
+
+// makeQueryParam returns the info on the query parameter
+// whose value is provided by the field denoted by ptr.
+func makeQueryParam(g *generator, ptr analysis.FieldPtr) *postgres.QueryParam {
+	p := &postgres.QueryParam{Field: ptr}
+	switch v := ptr.(type) {
+	case *analysis.FieldInfo:
+		p.Type = v.Type
+		for _, fws := range [][]*postgres.FieldWrite{g.info.Writes, g.info.PKeys} {
+			for _, fw := range fws {
+				if fw.Field == v {
+					p.Valuer = fw.Valuer
+				}
+			}
+		}
+	case *postgres.FieldConditional:
+		p.Field = v.Field
+		p.Type = v.FieldType
+		p.Valuer = v.Valuer
+	case *postgres.FieldOrder:
+		p.Field = v.Field
+		p.Type = v.Field.Type
+		p.Valuer = v.Valuer
+	}
+	return p
+}
+
 // The impspec type holds info that is used to generate a GO.ImportSpec node.
 type impspec struct {
 	// The package path of the import spec.
@@ -108,6 +249,8 @@ type generator struct {
 	// List of selector expressions for fields that containin slices that
 	// will be used as input for [NOT] IN clauses.
 	inputSliceArgs []GO.SelectorExpr
+	// The conditionals of the inputSliceArgs, in the same order.
+	inputSliceConds []*postgres.FieldConditional
 	// The list of target columns to be set by the sql query (INSERT|UPDATE).
 	inputCols      SQL.NameGroup
 	inputColsPKeys SQL.NameGroup
@@ -144,6 +287,9 @@ type generator struct {
 	whereClause SQL.WhereClause
 	// The ORDER BY clause for the sqlString (SELECT).
 	orderClause SQL.OrderClause
+	// The ORDER BY clause for the sqlString (SELECT) if it contains operator
+	// expressions, which the SQL.OrderClause cannot represent, otherwise empty.
+	orderExprClause sqlOrderClause
 	// The LIMIT clause for the sqlString (SELECT).
 	limitClause SQL.LimitClause
 	// The OFFSET clause for the sqlString (SELECT).
@@ -260,6 +406,8 @@ func buildQueryInput(g *generator, qs *analysis.QueryStruct) {
 	// prepare input for the WHERE clause
 	buildQueryInputWhereStruct(g, qs)
 	buildQueryInputPKeyFields(g, qs)
+	// prepare input for the ORDER BY clause
+	buildQueryInputOrderByParams(g, qs)
 	// prepare input for the LIMIT clause
 	buildQueryInputLimitField(g, qs)
 	// prepare input for the OFFSET clause
@@ -307,6 +455,11 @@ func buildQueryInputTargetValues(g *generator, qs *analysis.QueryStruct) {
 			col.Qual = "x"
 			g.updateCols = append(g.updateCols, col)
 
+			if fw.Field.AutoNow > 0 {
+				g.inputVals = append(g.inputVals, makeAutoNowCallExpr(fw.Field))
+				continue
+			}
+
 			var expr SQL.ValueExpr
 			expr = makeParamSpec(g, fw.Field)
 			if fw.NeedsNULLIF() {
@@ -321,6 +474,11 @@ func buildQueryInputTargetValues(g *generator, qs *analysis.QueryStruct) {
 			continue
 		}
 
+		if fw.Field.AutoNow > 0 {
+			g.inputVals = append(g.inputVals, makeAutoNowCallExpr(fw.Field))
+			continue
+		}
+
 		var expr SQL.ValueExpr
 		expr = makeParamSpec(g, fw.Field)
 		if fw.NeedsNULLIF() {
@@ -344,7 +502,7 @@ func buildQueryInputSourceFields(g *generator, qs *analysis.QueryStruct) {
 	}
 
 	for _, fw := range g.info.Writes {
-		if canUseDefault(qs, fw.Field) {
+		if canUseDefault(qs, fw.Field) || fw.Field.AutoNow > 0 {
 			continue
 		}
 
@@ -353,7 +511,7 @@ func buildQueryInputSourceFields(g *generator, qs *analysis.QueryStruct) {
 			fx = GO.SelectorExpr{X: fx, Sel: GO.Ident{node.Name}}
 		}
 		fx = GO.SelectorExpr{X: fx, Sel: GO.Ident{fw.Field.Name}}
-		fx = addConverterCallExpr(g, fx, fw.Valuer)
+		fx = addConverterCallExpr(g, fx, fw.Valuer, fw.CompositeFields...)
 		g.inputArgs = append(g.inputArgs, fx)
 	}
 }
@@ -419,6 +577,15 @@ func buildQueryInputPKeyFields(g *generator, qs *analysis.QueryStruct) {
 	}
 }
 
+// buildQueryInputOrderByParams builds the input for the operator expressions of an ORDER BY clause.
+func buildQueryInputOrderByParams(g *generator, qs *analysis.QueryStruct) {
+	for _, order := range g.info.Orders {
+		expr := GO.ExprNode(GO.SelectorExpr{X: g.queryRecv, Sel: GO.Ident{order.Field.Name}})
+		expr = addConverterCallExpr(g, expr, order.Valuer)
+		g.inputArgs = append(g.inputArgs, expr)
+	}
+}
+
 // buildQueryInputLimitField
 func buildQueryInputLimitField(g *generator, qs *analysis.QueryStruct) {
 	if qs.Limit == nil || len(qs.Limit.Name) == 0 {
@@ -567,9 +734,14 @@ func buildQueryOutputTargetFields(g *generator, qs *analysis.QueryStruct) {
 
 		fx = GO.SelectorExpr{X: fx, Sel: GO.Ident{fr.Field.Name}}
 		fx = GO.UnaryExpr{Op: GO.UnaryAmp, X: fx}
-		fx = addConverterCallExpr(g, fx, fr.Scanner)
+		fx = addConverterCallExpr(g, fx, fr.Scanner, fr.CompositeFields...)
 		g.outputArgs = append(g.outputArgs, fx)
 	}
+
+	if qs.TotalCount != nil {
+		fx := GO.SelectorExpr{X: g.queryRecv, Sel: GO.Ident{qs.TotalCount.Name}}
+		g.outputArgs = append(g.outputArgs, GO.UnaryExpr{Op: GO.UnaryAmp, X: fx})
+	}
 }
 
 // buildQueryOutputSourceColumns builds the list of columns to be returned by the query.
@@ -579,6 +751,19 @@ func buildQueryOutputSourceColumns(g *generator, qs *analysis.QueryStruct) {
 	}
 
 	for _, fr := range g.info.Reads {
+		if len(fr.Field.Expr) > 0 {
+			g.outputVals = append(g.outputVals, makeExprFieldRef(fr))
+			continue
+		}
+		if fr.Field.Window != nil {
+			g.outputVals = append(g.outputVals, makeWindowFieldRef(fr))
+			continue
+		}
+		if fr.Field.Many != nil {
+			g.outputVals = append(g.outputVals, makeManyFieldRef(g, fr))
+			continue
+		}
+
 		var expr SQL.ValueExpr
 		expr = makeColRef(fr.ColIdent)
 		if fr.NeedsCOALESCE() {
@@ -586,6 +771,11 @@ func buildQueryOutputSourceColumns(g *generator, qs *analysis.QueryStruct) {
 		}
 		g.outputVals = append(g.outputVals, expr)
 	}
+
+	if qs.TotalCount != nil {
+		g.outputVals = append(g.outputVals, SQL.Literal{`count(*) OVER () AS ` +
+			SQL.MustToString(SQL.Name("total_count"))})
+	}
 }
 
 // buildQueryLimitOffsetFallback
@@ -616,6 +806,15 @@ func buildQueryLimitOffsetFallback(g *generator, qs *analysis.QueryStruct) {
 		g.queryLimitAndOffsetFallback = append(g.queryLimitAndOffsetFallback, ifzero)
 	}
 
+	// The total count is read from each of the result's rows, it is reset
+	// beforehand so that it does not retain a stale value if there are none.
+	if tc := qs.TotalCount; tc != nil {
+		assign := GO.AssignStmt{Token: GO.Assign}
+		assign.Lhs = GO.SelectorExpr{X: g.queryRecv, Sel: GO.Ident{tc.Name}}
+		assign.Rhs = GO.IntLit(0)
+		g.queryLimitAndOffsetFallback = append(g.queryLimitAndOffsetFallback, assign)
+	}
+
 	if len(g.queryLimitAndOffsetFallback) > 0 {
 		g.queryLimitAndOffsetFallback = append(g.queryLimitAndOffsetFallback, GO.NL{})
 	} else {
@@ -623,16 +822,124 @@ func buildQueryLimitOffsetFallback(g *generator, qs *analysis.QueryStruct) {
 	}
 }
 
-// addConverterCallExpr
-func addConverterCallExpr(g *generator, x GO.ExprNode, converter string) GO.ExprNode {
+// addConverterCallExpr wraps the given expression in a call to the named
+// pgsql converter. Any additional string args are passed to the converter
+// after the expression, e.g. the struct field names of a composite value.
+func addConverterCallExpr(g *generator, x GO.ExprNode, converter string, args ...string) GO.ExprNode {
 	if len(converter) > 0 {
 		addimport(g.file, pgsqlPkgPath, pgsqlPkgName)
 		sel := GO.SelectorExpr{X: GO.Ident{pgsqlPkgName}, Sel: GO.Ident{converter}}
-		return GO.CallExpr{Fun: sel, Args: GO.ArgsList{List: x}}
+		if len(args) == 0 {
+			return GO.CallExpr{Fun: sel, Args: GO.ArgsList{List: x}}
+		}
+
+		list := GO.ExprList{x}
+		for _, arg := range args {
+			list = append(list, GO.ValueLit(strconv.Quote(arg)))
+		}
+		return GO.CallExpr{Fun: sel, Args: GO.ArgsList{List: list}}
 	}
 	return x
 }
 
+// makeExprFieldRef produces the select list item of an expression field,
+// that is the field's SQL expression followed by the alias of its result.
+func makeExprFieldRef(fr *postgres.FieldRead) SQL.ValueExpr {
+	return SQL.Literal{fr.Field.Expr + ` AS ` + SQL.MustToString(SQL.Name(fr.ColIdent.Name))}
+}
+
+// makeWindowFieldRef produces the select list item of a window function field,
+// that is the function call with its OVER clause followed by the alias of its result.
+func makeWindowFieldRef(fr *postgres.FieldRead) SQL.ValueExpr {
+	win := fr.Field.Window
+
+	call := win.Name + "()"
+	if !win.Arg.IsEmpty() {
+		call = win.Name + "(" + SQL.MustToString(makeColRef(win.Arg)) + ")"
+	} else if win.Name == "count" {
+		call = "count(*)"
+	}
+
+	var over []string
+	if len(win.Partition) > 0 {
+		list := make([]string, len(win.Partition))
+		for i, cid := range win.Partition {
+			list[i] = SQL.MustToString(makeColRef(cid))
+		}
+		over = append(over, "PARTITION BY "+strings.Join(list, ", "))
+	}
+	if len(win.OrderBy) > 0 {
+		list := make([]string, len(win.OrderBy))
+		for i, item := range win.OrderBy {
+			order := SQL.OrderBy{}
+			order.Column = makeColRef(item.ColIdent)
+			order.Desc = (item.Direction == analysis.OrderDesc)
+			order.NullsFirst = (item.Nulls == analysis.NullsFirst)
+			list[i] = SQL.MustToString(order)
+		}
+		over = append(over, "ORDER BY "+strings.Join(list, ", "))
+	}
+
+	return SQL.Literal{call + ` OVER (` + strings.Join(over, " ") + `) AS ` +
+		SQL.MustToString(SQL.Name(fr.ColIdent.Name))}
+}
+
+// makeManyFieldRef produces the select list item of a "many" field, that is
+// a subquery that aggregates the matching records of the child relation into
+// a json array, followed by the alias of its result. Each record is built as
+// a json object whose keys match the names that encoding/json uses to decode
+// the fields of the element type.
+func makeManyFieldRef(g *generator, fr *postgres.FieldRead) SQL.ValueExpr {
+	many := fr.Field.Many
+
+	qual := many.RelIdent.Alias
+	if len(qual) == 0 {
+		qual = many.RelIdent.Name
+	}
+
+	var args []string
+	for _, cf := range many.Type.Fields {
+		key := cf.Name
+		if name := strings.Split(cf.Tag.First("json"), ",")[0]; name == "-" {
+			continue
+		} else if len(name) > 0 {
+			key = name
+		}
+
+		val := cf.Expr
+		if len(val) == 0 {
+			cid := cf.ColIdent
+			if len(cid.Qualifier) == 0 {
+				cid.Qualifier = qual
+			}
+			val = SQL.MustToString(makeColRef(cid))
+		}
+		args = append(args, `'`+strings.Replace(key, `'`, `''`, -1)+`', `+val)
+	}
+
+	sub := `SELECT COALESCE(json_agg(json_build_object(` + strings.Join(args, ", ") +
+		`)), '[]') FROM ` + SQL.MustToString(makeRelIdent(g, many.RelIdent))
+
+	if joins := g.info.ManyJoins[fr.Field]; len(joins) > 0 {
+		conds := make([]postgres.WhereConditional, len(joins))
+		for i := 0; i < len(joins); i++ {
+			conds[i] = joins[i]
+		}
+
+		searchCond, _ := makeSQLBoolValueExprList(g, conds, GO.SelectorExpr{}, false)
+		searchCond.ListStyle = false
+		sub += ` WHERE ` + strings.TrimSpace(SQL.MustToString(searchCond))
+	}
+
+	return SQL.Literal{`(` + sub + `) AS ` + SQL.MustToString(SQL.Name(fr.ColIdent.Name))}
+}
+
+// makeAutoNowCallExpr produces the function call expression that
+// is used to set the column of a field with the "autonow" option.
+func makeAutoNowCallExpr(f *analysis.FieldInfo) SQL.ValueExpr {
+	return SQL.RoutineInvocation{Name: f.AutoNowFunc}
+}
+
 // addCoalesceCallExpr
 func addCoalesceCallExpr(x SQL.ValueExpr, val string, c *postgres.Column) SQL.ValueExpr {
 	if len(val) > 0 {
@@ -967,7 +1274,7 @@ func buildQuerySQLString(g *generator, qs *analysis.QueryStruct) {
 // buildSQLInsertStatement builds an SQL.InsertStatement.
 func buildSQLInsertStatement(g *generator, qs *analysis.QueryStruct) {
 	stmt := SQL.InsertStatement{}
-	stmt.Head.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Head.Table = makeRelIdent(g, qs.Rel.Id)
 	stmt.Head.Columns = g.inputCols
 	if qs.Override != nil {
 		stmt.Head.Overriding = sqlOverridingClause[qs.Override.Kind]
@@ -993,7 +1300,7 @@ func buildSQLInsertStatement(g *generator, qs *analysis.QueryStruct) {
 // buildSQLUpdateStatement builds an SQL.UpdateStatement.
 func buildSQLUpdateStatement(g *generator, qs *analysis.QueryStruct) {
 	stmt := SQL.UpdateStatement{}
-	stmt.Head.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Head.Table = makeRelIdent(g, qs.Rel.Id)
 	stmt.Head.Set.Targets = g.inputCols
 	if qs.IsUpdateSlice() {
 		stmt.Head.Set.Values.Exprs = g.updateCols
@@ -1001,7 +1308,7 @@ func buildSQLUpdateStatement(g *generator, qs *analysis.QueryStruct) {
 		stmt.Head.Set.Values.Exprs = g.inputVals
 	}
 	if qs.Join != nil {
-		stmt.Head.From.List = []SQL.TableExpr{makeRelIdent(qs.Join.Relation.RelIdent)}
+		stmt.Head.From.List = []SQL.TableExpr{makeRelIdent(g, qs.Join.Relation.RelIdent)}
 		stmt.Head.From.List = append(stmt.Head.From.List, g.tableExprSlice()...)
 	}
 
@@ -1011,6 +1318,7 @@ func buildSQLUpdateStatement(g *generator, qs *analysis.QueryStruct) {
 		columns := append(g.inputCols, g.inputColsPKeys...)
 		alias := SQL.ValuesListAliasPartial{Alias: "x", Columns: columns}
 		tail := SQL.UpdateTail{Where: g.whereClause}
+		tail.Returning = SQL.ReturningClause(g.outputVals)
 		g.sqlTailNode = SQL.NodeList{alias, tail}
 		g.sqlMainNode = stmt
 		return
@@ -1033,11 +1341,17 @@ func buildSQLUpdateStatement(g *generator, qs *analysis.QueryStruct) {
 func buildSQLSelectStatement(g *generator, qs *analysis.QueryStruct) {
 	stmt := SQL.SelectStatement{}
 	stmt.Columns = g.outputVals // columns
-	stmt.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Table = makeRelIdent(g, qs.Rel.Id)
 	if qs.Join != nil {
 		stmt.Join.List = append(stmt.Join.List, g.tableJoinSlice...)
 	}
 	stmt.Where = g.whereClause
+	if len(g.orderExprClause.List) > 0 {
+		// the order clause must be followed by the LIMIT and OFFSET
+		// clauses, hence those are also written outside the statement
+		g.sqlMainNode = SQL.NodeList{stmt, g.orderExprClause, g.limitClause, g.offsetClause}
+		return
+	}
 	stmt.Order = g.orderClause
 	stmt.Limit = g.limitClause
 	stmt.Offset = g.offsetClause
@@ -1046,11 +1360,16 @@ func buildSQLSelectStatement(g *generator, qs *analysis.QueryStruct) {
 
 // buildSQLDeleteStatement builds an SQL.DeleteStatement.
 func buildSQLDeleteStatement(g *generator, qs *analysis.QueryStruct) {
+	if g.info.SoftDelete != nil {
+		buildSQLSoftDeleteStatement(g, qs)
+		return
+	}
+
 	stmt := SQL.DeleteStatement{}
-	stmt.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Table = makeRelIdent(g, qs.Rel.Id)
 
 	if qs.Join != nil {
-		stmt.Using.List = []SQL.TableExpr{makeRelIdent(qs.Join.Relation.RelIdent)}
+		stmt.Using.List = []SQL.TableExpr{makeRelIdent(g, qs.Join.Relation.RelIdent)}
 		stmt.Using.List = append(stmt.Using.List, g.tableExprSlice()...)
 	}
 
@@ -1059,10 +1378,37 @@ func buildSQLDeleteStatement(g *generator, qs *analysis.QueryStruct) {
 	g.sqlMainNode = stmt
 }
 
+// buildSQLSoftDeleteStatement builds an SQL.UpdateStatement that marks
+// the records of a soft-delete relation as deleted.
+func buildSQLSoftDeleteStatement(g *generator, qs *analysis.QueryStruct) {
+	col := g.info.SoftDelete.LHSColIdent
+
+	stmt := SQL.UpdateStatement{}
+	stmt.Head.Table = makeRelIdent(g, qs.Rel.Id)
+	stmt.Head.Set.Targets = SQL.NameGroup{SQL.Name(col.Name)}
+	stmt.Head.Set.Values.Exprs = SQL.ValueExprList{SQL.Literal{"now()"}}
+	if qs.Join != nil {
+		stmt.Head.From.List = []SQL.TableExpr{makeRelIdent(g, qs.Join.Relation.RelIdent)}
+		stmt.Head.From.List = append(stmt.Head.From.List, g.tableExprSlice()...)
+	}
+
+	if qs.Filter != nil && len(g.outputVals) > 0 {
+		tail := SQL.UpdateTail{}
+		tail.Returning = SQL.ReturningClause(g.outputVals)
+		g.sqlTailNode = tail
+		g.sqlMainNode = stmt
+		return
+	}
+
+	stmt.Tail.Where = g.whereClause
+	stmt.Tail.Returning = SQL.ReturningClause(g.outputVals)
+	g.sqlMainNode = stmt
+}
+
 // buildSQLSelectCountStatement builds an SQL.SelectCountStatement.
 func buildSQLSelectCountStatement(g *generator, qs *analysis.QueryStruct) {
 	stmt := SQL.SelectCountStatement{}
-	stmt.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Table = makeRelIdent(g, qs.Rel.Id)
 
 	if qs.Join != nil {
 		stmt.Join.List = append(stmt.Join.List, g.tableJoinSlice...)
@@ -1080,7 +1426,7 @@ func buildSQLSelectCountStatement(g *generator, qs *analysis.QueryStruct) {
 // buildSQLSelectExistsStatement builds an SQL.SelectExistsStatement.
 func buildSQLSelectExistsStatement(g *generator, qs *analysis.QueryStruct) {
 	stmt := SQL.SelectExistsStatement{}
-	stmt.Table = makeRelIdent(qs.Rel.Id)
+	stmt.Table = makeRelIdent(g, qs.Rel.Id)
 	stmt.Not = (qs.Kind == analysis.QueryKindSelectNotExists)
 
 	if qs.Join != nil {
@@ -1149,6 +1495,75 @@ func buildSQLOrderClause(g *generator, qs *analysis.QueryStruct) {
 		order.NullsFirst = (item.Nulls == analysis.NullsFirst)
 		g.orderClause.List = append(g.orderClause.List, order)
 	}
+	if len(g.info.Orders) == 0 {
+		return
+	}
+
+	// The SQL.OrderBy type can only order by a column, therefore
+	// an ORDER BY clause with operator expressions is built from
+	// the same items extended with the operators and their params.
+	for _, order := range g.orderClause.List {
+		g.orderExprClause.List = append(g.orderExprClause.List, sqlOrderBy{OrderBy: order})
+	}
+	for i, item := range qs.OrderBy.Items {
+		for _, order := range g.info.Orders {
+			if order.Field == item.Param {
+				g.orderExprClause.List[i].Operator = order.Operator
+				g.orderExprClause.List[i].Param = makeParamSpec(g, order)
+			}
+		}
+	}
+}
+
+// sqlOrderClause produces an SQL ORDER BY clause whose items may order
+// by the result of an operator applied to a column and a parameter.
+type sqlOrderClause struct {
+	List []sqlOrderBy
+}
+
+func (c sqlOrderClause) Walk(w *ast.Writer) {
+	if len(c.List) == 0 {
+		return
+	}
+	w.NewLine()
+	w.Write("ORDER BY ")
+	for i, o := range c.List {
+		if i > 0 {
+			w.Write(", ")
+		}
+		o.Walk(w)
+	}
+}
+
+// sqlOrderBy is an SQL.OrderBy with an optional operator
+// whose left operand is the column.
+type sqlOrderBy struct {
+	SQL.OrderBy
+	// The operator, or empty.
+	Operator string
+	// The right operand of the operator.
+	Param SQL.ValueExpr
+}
+
+func (o sqlOrderBy) Walk(w *ast.Writer) {
+	if len(o.Operator) == 0 {
+		o.OrderBy.Walk(w)
+		return
+	}
+
+	o.Column.Walk(w)
+	w.Write(" " + o.Operator + " ")
+	o.Param.Walk(w)
+	if o.Desc {
+		w.Write(" DESC")
+	} else {
+		w.Write(" ASC")
+	}
+	if o.NullsFirst {
+		w.Write(" NULLS FIRST")
+	} else {
+		w.Write(" NULLS LAST")
+	}
 }
 
 // buildSQLOnConflictClause
@@ -1210,6 +1625,34 @@ func buildSQLWhereClause(g *generator, qs *analysis.QueryStruct) {
 		sel := GO.SelectorExpr{X: g.queryRecv, Sel: GO.Ident{qs.Where.FieldName}}
 		g.whereClause.SearchCondition, _ = makeSQLBoolValueExprList(g, g.info.Where, sel, false)
 	}
+
+	// If the query has a filter the soft-delete conditional
+	// is merged into the filter's WHERE clause at runtime.
+	if g.info.SoftDelete != nil && qs.Filter == nil {
+		cond := makeSQLSoftDeleteCondition(g)
+		if g.whereClause.SearchCondition == nil {
+			g.whereClause.SearchCondition = cond
+			return
+		}
+
+		list := SQL.BoolValueExprList{}
+		list.Initial = g.whereClause.SearchCondition
+		list.Items = []SQL.BoolOpExpr{SQL.AND{Operand: cond}}
+		if where, ok := list.Initial.(SQL.BoolValueExprList); ok && len(where.Items) > 0 {
+			where.Parenthesized = true
+			list.Initial = where
+			list.ListStyle = where.ListStyle
+		}
+		g.whereClause.SearchCondition = list
+	}
+}
+
+// makeSQLSoftDeleteCondition builds the SQL.BoolValueExpr that
+// excludes the soft-deleted records of the target relation.
+func makeSQLSoftDeleteCondition(g *generator) SQL.BoolValueExpr {
+	conds := []postgres.WhereConditional{g.info.SoftDelete}
+	list, _ := makeSQLBoolValueExprList(g, conds, GO.SelectorExpr{}, false)
+	return list.Initial
 }
 
 // buildSQLTableJoinSlice builds a slice of SQL.TableJoin values.
@@ -1229,7 +1672,7 @@ func buildSQLTableJoinSlice(g *generator, qs *analysis.QueryStruct) {
 
 		join := SQL.TableJoin{}
 		join.Type = sqlJoinType[dir.JoinType]
-		join.Rel = makeRelIdent(dir.RelIdent)
+		join.Rel = makeRelIdent(g, dir.RelIdent)
 		join.Cond.SearchCondition = searchCond
 		g.tableJoinSlice = append(g.tableJoinSlice, join)
 	}
@@ -1259,6 +1702,7 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 				lhs        SQL.ValueExpr
 				rhs        SQL.ValueExpr
 				pred       analysis.Predicate
+				oper       string
 				qua        analysis.Quantifier
 				fieldName  string
 				columnType string
@@ -1269,6 +1713,7 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 			case *postgres.FieldConditional:
 				fieldName = cond.FieldName // needed for isin/notin predicates
 				pred = cond.Predicate
+				oper = cond.Operator
 				qua = cond.Quantifier
 				lhs = makeColRef(cond.ColIdent)
 				rhs = makeParamSpec(g, cond)
@@ -1284,12 +1729,15 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 					rhsFuncCall.Args = []SQL.ValueExpr{rhs}
 					rhs = rhsFuncCall
 				}
-				if cond.Quantifier > 0 {
+				// The operand of a custom operator is not necessarily
+				// of the column's type, leave that to the server.
+				if cond.Quantifier > 0 && !cond.Predicate.IsOperator() {
 					columnType = cond.Column.Type.NameFmt
 				}
 
 			case *postgres.ColumnConditional:
 				pred = cond.Predicate
+				oper = cond.Operator
 				qua = cond.Quantifier
 				lhs = makeColRef(cond.LHSColIdent)
 				if !cond.RHSColIdent.IsEmpty() {
@@ -1345,6 +1793,8 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 				predicate.LPredicand = lhs
 				predicate.RPredicand = rhs
 				expr = predicate
+			case analysis.IsOperator:
+				expr = makeSQLOperatorPredicate(lhs, oper, rhs)
 			case analysis.IsMatch, analysis.IsMatchi, analysis.NotMatch, analysis.NotMatchi:
 				predicate := SQL.RegexPredicate{}
 				predicate.Op = sqlREGEXOP[pred]
@@ -1354,19 +1804,13 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 			case analysis.IsIn, analysis.NotIn:
 				sx := GO.SelectorExpr{X: sx, Sel: GO.Ident{fieldName}}
 				g.inputSliceArgs = append(g.inputSliceArgs, sx)
+				g.inputSliceConds = append(g.inputSliceConds, cond.(*postgres.FieldConditional))
 				g.paramNum -= 1 // ordinal param won't be used directly
 
-				num := strconv.Itoa(len(g.inputSliceArgs))
-				arg1 := GO.Ident{"len" + num}
-				arg2 := GO.Ident{"pos" + num}
-
-				call := GO.CallExpr{Fun: GO.QualifiedIdent{"gosql", "InValueList"}}
-				call.Args = GO.ArgsList{List: GO.ExprList{arg1, arg2}}
-
 				predicate := SQL.InPredicate{}
 				predicate.Not = (pred == analysis.NotIn)
 				predicate.Predicand = lhs
-				predicate.ValueList = SQL.HostValue{GO.RawStringInsertExpr{call}}
+				predicate.ValueList = makeInValueList(len(g.inputSliceArgs))
 				expr = predicate
 			case analysis.IsTrue, analysis.NotTrue, analysis.IsFalse, analysis.NotFalse, analysis.IsUnknown, analysis.NotUnknown:
 				predicate := SQL.TruthPredicate{}
@@ -1406,6 +1850,30 @@ func makeSQLBoolValueExprList(g *generator, conds []postgres.WhereConditional, s
 	return list, count
 }
 
+// makeSQLOperatorPredicate builds an SQL.BoolValueExpr that applies the named
+// operator to the given operands.
+func makeSQLOperatorPredicate(lhs SQL.ValueExpr, oper string, rhs SQL.ValueExpr) SQL.BoolValueExpr {
+	return sqlOperatorPredicate{LPredicand: lhs, Op: oper, RPredicand: rhs}
+}
+
+// sqlOperatorPredicate produces an SQL predicate that applies a custom operator
+// to two operands, the SQL.ComparisonPredicate supports only the standard
+// comparison operators.
+type sqlOperatorPredicate struct {
+	// The interface's method is unexported, the embedded nil value is
+	// never used, it only makes the type an SQL.BoolValueExpr.
+	SQL.BoolValueExpr
+	LPredicand SQL.ValueExpr
+	Op         string
+	RPredicand SQL.ValueExpr
+}
+
+func (p sqlOperatorPredicate) Walk(w *ast.Writer) {
+	p.LPredicand.Walk(w)
+	w.Write(" " + p.Op + " ")
+	p.RPredicand.Walk(w)
+}
+
 // makeSQLBetweenPredicate
 func makeSQLBetweenPredicate(g *generator, cond *postgres.BetweenConditional) SQL.BetweenPredicate {
 	predicate := SQL.BetweenPredicate{}
@@ -1528,6 +1996,10 @@ func buildQueryStringForSliceInsertOrUpdate(g *generator, qs *analysis.QueryStru
 		if val == SQL.DEFAULT {
 			defaultCount += 1
 			strConcatExprList[i] = GO.RawStringLit(sep + `DEFAULT`)
+		} else if call, ok := val.(SQL.RoutineInvocation); ok {
+			// an "autonow" function call, like DEFAULT, takes no parameter
+			defaultCount += 1
+			strConcatExprList[i] = GO.RawStringLit(sep + SQL.MustToString(call))
 		} else {
 			importGosql = true
 
@@ -1717,11 +2189,22 @@ func buildQueryStringForFilter(g *generator, qs *analysis.QueryStruct) {
 	stmtList = append(stmtList, assign)
 
 	rhsExpr := GO.ExprNode(filterStringVar)
+	if g.info.SoftDelete != nil {
+		// produce:
+		//	gosql.FilterAndWhere(filterString, `<soft_delete_condition>`)
+		imp := addimport(g.file, gosqlPkgPath, gosqlPkgName)
+		cond := strings.TrimSpace(SQL.MustToString(makeSQLSoftDeleteCondition(g)))
+
+		callExpr := GO.CallExpr{}
+		callExpr.Fun = GO.QualifiedIdent{imp.name, "FilterAndWhere"}
+		callExpr.Args = GO.ArgsList{List: GO.ExprList{filterStringVar, GO.RawStringLit(cond)}}
+		rhsExpr = callExpr
+	}
 	if g.closeFilter {
 		// produce:
 		//	filterString + `)`
 		binAdd := GO.BinaryExpr{Op: GO.BinaryAdd}
-		binAdd.X = filterStringVar
+		binAdd.X = rhsExpr
 		binAdd.Y = GO.RawStringLit(`)`)
 		rhsExpr = binAdd
 	}
@@ -2264,6 +2747,17 @@ func makeParamSpec(g *generator, ptr analysis.FieldPtr) SQL.OrdinalParameterSpec
 	return SQL.OrdinalParameterSpec{g.paramNum}
 }
 
+// makeInValueList produces the value list of the [NOT] IN predicate of
+// the num-th slice argument, the list's parameters are written at runtime.
+func makeInValueList(num int) SQL.HostValue {
+	arg1 := GO.Ident{"len" + strconv.Itoa(num)}
+	arg2 := GO.Ident{"pos" + strconv.Itoa(num)}
+
+	call := GO.CallExpr{Fun: GO.QualifiedIdent{"gosql", "InValueList"}}
+	call.Args = GO.ArgsList{List: GO.ExprList{arg1, arg2}}
+	return SQL.HostValue{GO.RawStringInsertExpr{call}}
+}
+
 // makeRelTypeNode
 func makeRelTypeNode(reltyp analysis.RelType) GO.TypeNode {
 	if reltyp.Base.IsImported {
@@ -2281,7 +2775,10 @@ func makeColRef(id analysis.ColIdent) SQL.ColumnReference {
 }
 
 // makeRelIdent
-func makeRelIdent(id analysis.RelIdent) SQL.Ident {
+func makeRelIdent(g *generator, id analysis.RelIdent) SQL.Ident {
+	if qid, ok := g.info.RelIdents[id]; ok {
+		id = qid
+	}
 	return SQL.Ident{
 		Name:  SQL.Name(id.Name),
 		Qual:  id.Qualifier,
@@ -64,6 +64,18 @@ func TestGenerator(t *testing.T) {
 			{filename: "rowsaffected"},
 			{filename: "rowsaffected_errorhandler"},
 			{filename: "rowsaffected_errorinfohandler"},
+			{filename: "softdelete_filter", withCfg: func(cfg *config.Config) {
+				cfg.SoftDeleteRelations = []config.SoftDeleteRelation{{
+					Relation: config.String{Value: "test_soft_delete"},
+					Column:   config.String{Value: "deleted_at"},
+				}}
+			}},
+			{filename: "softdelete_where", withCfg: func(cfg *config.Config) {
+				cfg.SoftDeleteRelations = []config.SoftDeleteRelation{{
+					Relation: config.String{Value: "test_soft_delete"},
+					Column:   config.String{Value: "deleted_at"},
+				}}
+			}},
 			{filename: "using_join_block_1"},
 			{filename: "using_join_block_2"},
 			{filename: "where_block_1"},
@@ -85,6 +97,7 @@ func TestGenerator(t *testing.T) {
 			{filename: "count_where"},
 			{filename: "exists_filter"},
 			{filename: "exists_where"},
+			{filename: "expr_fields"},
 			{filename: "iterator_func"},
 			{filename: "iterator_func_errorhandler"},
 			{filename: "iterator_iface"},
@@ -94,14 +107,27 @@ func TestGenerator(t *testing.T) {
 			{filename: "limit_directive"},
 			{filename: "limit_field_default"},
 			{filename: "limit_field"},
+			{filename: "many_fields"},
+			{filename: "composite_fields"},
 			{filename: "notexists_where"},
 			{filename: "notexists_filter"},
 			{filename: "offset_directive"},
 			{filename: "offset_field_default"},
 			{filename: "offset_field"},
 			{filename: "orderby_directive"},
+			{filename: "orderby_operator"},
 			{filename: "record_nested_single"},
 			{filename: "record_nested_slice"},
+			{filename: "softdelete_filter"},
+			{filename: "softdelete_join", withCfg: func(cfg *config.Config) {
+				cfg.SoftDeleteRelations = []config.SoftDeleteRelation{{
+					Relation: config.String{Value: "test_soft_delete"},
+					Column:   config.String{Value: "deleted_at"},
+				}}
+			}},
+			{filename: "softdelete_where"},
+			{filename: "softdelete_withdeleted"},
+			{filename: "totalcount_filter"},
 			{filename: "whereblock_array_comparison1"},
 			{filename: "whereblock_array_comparison2"},
 			{filename: "whereblock_array_comparison3"},
@@ -111,14 +137,18 @@ func TestGenerator(t *testing.T) {
 			{filename: "whereblock_isin3"},
 			{filename: "whereblock_modifierfunc_single"},
 			{filename: "whereblock_nested"},
+			{filename: "whereblock_operator"},
 			{filename: "whereblock_single"},
 			{filename: "whereblock_single2"},
 			{filename: "whereblock_slice"},
+			{filename: "window_fields"},
 		},
 	}, {
 		//skip:    true,
 		dirname: "insert",
 		testcases: []testcase{
+			{filename: "autonow_single"},
+			{filename: "autonow_slice"},
 			{filename: "basic_single"},
 			{filename: "basic_single2"},
 			{filename: "basic_slice"},
@@ -129,6 +159,7 @@ func TestGenerator(t *testing.T) {
 			{filename: "default_single"},
 			{filename: "default_slice"},
 			{filename: "json_single"},
+			{filename: "composite_fields"},
 			{filename: "json_slice"},
 			{filename: "onconflict_column_ignore_single_1"},
 			{filename: "onconflict_column_ignore_single_2"},
@@ -174,6 +205,8 @@ func TestGenerator(t *testing.T) {
 		dirname: "update",
 		testcases: []testcase{
 			{filename: "all_single"},
+			{filename: "autonow_single"},
+			{filename: "autonow_slice"},
 			{filename: "filter_single"},
 			{filename: "filter_result_slice"},
 			{filename: "fromblock_basic_single"},
@@ -313,3 +346,237 @@ func formatBytes(buf *bytes.Buffer) []byte {
 	}
 	return src
 }
+
+func TestWriteEnums(t *testing.T) {
+	enums := []*postgres.Type{{
+		Name:       "order_status",
+		Type:       postgres.TypeTypeEnum,
+		EnumLabels: []string{"pending", "in-progress", "shipped"},
+	}, {
+		Name:       "mood",
+		Type:       postgres.TypeTypeEnum,
+		EnumLabels: []string{"sad", "ok", "happy", ""},
+	}}
+
+	buf := new(bytes.Buffer)
+	if err := WriteEnums(buf, "testdata", enums); err != nil {
+		t.Fatal(err)
+	}
+	got := string(formatBytes(buf))
+
+	out, err := ioutil.ReadFile("../testdata/generator/enums/enums_out.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := string(out)
+
+	if err := compare.Compare(got, want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWriteEnumsConflict(t *testing.T) {
+	tests := []struct {
+		enums []*postgres.Type
+		want  string
+	}{{
+		enums: []*postgres.Type{{
+			Name:       "order_status",
+			Type:       postgres.TypeTypeEnum,
+			EnumLabels: []string{"in-progress", "in_progress"},
+		}},
+		want: `the Go identifier "OrderStatusInProgress" of label "in_progress" of enum type "order_status"` +
+			` conflicts with that of label "in-progress" of enum type "order_status"`,
+	}, {
+		enums: []*postgres.Type{{
+			Name: "order_status",
+			Type: postgres.TypeTypeEnum,
+		}, {
+			Name: "order-status",
+			Type: postgres.TypeTypeEnum,
+		}},
+		want: `the Go identifier "OrderStatus" of enum type "order-status"` +
+			` conflicts with that of enum type "order_status"`,
+	}, {
+		enums: []*postgres.Type{{
+			Name:       "mood",
+			Type:       postgres.TypeTypeEnum,
+			EnumLabels: []string{"", "empty"},
+		}},
+		want: `the Go identifier "MoodEmpty" of label "empty" of enum type "mood"` +
+			` conflicts with that of label "" of enum type "mood"`,
+	}}
+
+	for _, tt := range tests {
+		err := WriteEnums(new(bytes.Buffer), "testdata", tt.enums)
+		if err == nil {
+			t.Errorf("want error %q, got nil", tt.want)
+			continue
+		}
+		if e := compare.Compare(err.Error(), tt.want); e != nil {
+			t.Error(e)
+		}
+	}
+}
+
+func TestWriteModels(t *testing.T) {
+	users := &postgres.Relation{Name: "users", Schema: "public", RelKind: postgres.RelKindOrdinaryTable}
+	auditUsers := &postgres.Relation{Name: "users", Schema: "audit", RelKind: postgres.RelKindOrdinaryTable}
+	addresses := &postgres.Relation{Name: "user_addresses", Schema: "public", RelKind: postgres.RelKindOrdinaryTable}
+	activeUsers := &postgres.Relation{Name: "active_users", Schema: "public", RelKind: postgres.RelKindView}
+
+	field := func(name string, typ analysis.LiteralType, pk, notnull bool) *postgres.ModelField {
+		col := &postgres.Column{Name: name, IsPrimary: pk, HasNotNull: notnull}
+		return &postgres.ModelField{Column: col, Type: typ, IsNULLable: !notnull}
+	}
+
+	models := []*postgres.Model{{
+		Id:       analysis.RelIdent{Qualifier: "audit", Name: "users"},
+		Relation: auditUsers,
+		Fields: []*postgres.ModelField{
+			field("id", analysis.LiteralInt64, true, true),
+			field("user_id", analysis.LiteralInt32, false, true),
+			field("changed_at", analysis.LiteralTime, false, false),
+		},
+	}, {
+		Id:       analysis.RelIdent{Name: "active_users"},
+		Relation: activeUsers,
+		Fields: []*postgres.ModelField{
+			field("id", analysis.LiteralInt32, false, false),
+			field("email", analysis.LiteralString, false, false),
+		},
+	}, {
+		Id:       analysis.RelIdent{Name: "user_addresses"},
+		Relation: addresses,
+		Fields: []*postgres.ModelField{
+			field("id", analysis.LiteralInt64, true, true),
+			field("user_id", analysis.LiteralInt32, false, true),
+			field("street", analysis.LiteralString, false, false),
+			field("ip_addr", analysis.LiteralIP, false, false),
+			field("tags", analysis.LiteralStringSlice, false, false),
+			field("location", analysis.LiteralFloat64Array2, false, false),
+		},
+	}, {
+		Id:       analysis.RelIdent{Name: "users"},
+		Relation: users,
+		Fields: []*postgres.ModelField{
+			field("id", analysis.LiteralInt32, true, true),
+			field("email", analysis.LiteralString, false, true),
+			field("is_active", analysis.LiteralBool, false, false),
+			field("created_at", analysis.LiteralTime, false, false),
+		},
+	}}
+
+	tests := []struct {
+		nullable string
+		typefmt  string
+		filename string
+	}{
+		{nullable: "pointer", typefmt: "%s", filename: "models_pointer_out.go"},
+		{nullable: "sqlnull", typefmt: "%sRecord", filename: "models_sqlnull_out.go"},
+		{nullable: "coalesce", typefmt: "%s", filename: "models_coalesce_out.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			cfg := config.DefaultConfig
+			cfg.ModelsNULLable.Value = tt.nullable
+			cfg.ModelsTypeNameFormat.Value = tt.typefmt
+
+			buf := new(bytes.Buffer)
+			if err := WriteModels(buf, "testdata", models, cfg); err != nil {
+				t.Fatal(err)
+			}
+			got := string(formatBytes(buf))
+
+			out, err := ioutil.ReadFile("../testdata/generator/models/" + tt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := string(out)
+
+			if err := compare.Compare(got, want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestWriteScaffold(t *testing.T) {
+	field := func(rel *postgres.Relation, num int16, name string, typ analysis.LiteralType) *postgres.ModelField {
+		col := &postgres.Column{Num: num, Name: name, HasNotNull: true, Relation: rel}
+		return &postgres.ModelField{Column: col, Type: typ}
+	}
+
+	users := &postgres.Relation{Name: "users", Schema: "public", RelKind: postgres.RelKindOrdinaryTable}
+	usersModel := &postgres.Model{Id: analysis.RelIdent{Name: "users"}, Relation: users, Fields: []*postgres.ModelField{
+		field(users, 1, "id", analysis.LiteralInt32),
+		field(users, 2, "email", analysis.LiteralString),
+		field(users, 3, "full_name", analysis.LiteralString),
+		field(users, 4, "created_at", analysis.LiteralTime),
+		field(users, 5, "search", analysis.LiteralString),
+	}}
+	usersModel.Fields[0].Column.IsPrimary = true
+	usersModel.Fields[0].Column.HasDefault = true
+	usersModel.Fields[4].Column.IsGenerated = true
+	users.Constraints = []*postgres.Constraint{
+		{Name: "users_pkey", Type: postgres.ConstraintTypePKey, Key: []int64{1}},
+		{Name: "users_email_key", Type: postgres.ConstraintTypeUnique, Key: []int64{2}},
+	}
+
+	items := &postgres.Relation{Name: "order_items", Schema: "shop", RelKind: postgres.RelKindOrdinaryTable}
+	itemsModel := &postgres.Model{Id: analysis.RelIdent{Qualifier: "shop", Name: "order_items"}, Relation: items, Fields: []*postgres.ModelField{
+		field(items, 1, "order_id", analysis.LiteralInt64),
+		field(items, 2, "product_id", analysis.LiteralInt64),
+		field(items, 3, "quantity", analysis.LiteralInt32),
+	}}
+	itemsModel.Fields[0].Column.IsPrimary = true
+	itemsModel.Fields[1].Column.IsPrimary = true
+	items.Constraints = []*postgres.Constraint{
+		{Name: "order_items_pkey", Type: postgres.ConstraintTypePKey, Key: []int64{1, 2}},
+	}
+
+	tags := &postgres.Relation{Name: "tags", Schema: "public", RelKind: postgres.RelKindOrdinaryTable}
+	tagsModel := &postgres.Model{Id: analysis.RelIdent{Name: "tags"}, Relation: tags, Fields: []*postgres.ModelField{
+		field(tags, 1, "id", analysis.LiteralInt64),
+		field(tags, 2, "slug", analysis.LiteralString),
+		field(tags, 3, "label", analysis.LiteralString),
+	}}
+	tagsModel.Fields[0].Column.IsPrimary = true
+	tagsModel.Fields[0].Column.Identity = postgres.ColumnIdentityAlways
+	tags.Constraints = []*postgres.Constraint{
+		{Name: "tags_pkey", Type: postgres.ConstraintTypePKey, Key: []int64{1}},
+	}
+	tags.Indexes = []*postgres.Index{
+		{Name: "tags_pkey", IsUnique: true, IsPrimary: true, IsImmediate: true, IsReady: true, Key: []int16{1}},
+		{Name: "tags_lower_label_idx", IsUnique: true, IsImmediate: true, IsReady: true, Key: []int16{0}},
+		{Name: "tags_slug_idx", IsUnique: true, IsImmediate: true, IsReady: true, Key: []int16{2}},
+	}
+
+	tests := []struct {
+		model    *postgres.Model
+		filename string
+	}{
+		{model: usersModel, filename: "users_queries_out.go"},
+		{model: itemsModel, filename: "order_items_queries_out.go"},
+		{model: tagsModel, filename: "tags_queries_out.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := WriteScaffold(buf, "testdata", tt.model, config.DefaultConfig); err != nil {
+				t.Fatal(err)
+			}
+			got := string(formatBytes(buf))
+
+			out, err := ioutil.ReadFile("../testdata/generator/scaffold/" + tt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := string(out)
+
+			if err := compare.Compare(got, want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/config"
+	"github.com/frk/gosql/internal/postgres"
+
+	GO "github.com/frk/ast/golang"
+)
+
+// modelNULLTypes maps Go types to the database/sql
+// Null types that are used for the NULLable columns
+// of those types when the "sqlnull" mode is configured.
+var modelNULLTypes = map[analysis.LiteralType]string{
+	analysis.LiteralBool:    "NullBool",
+	analysis.LiteralByte:    "NullByte",
+	analysis.LiteralInt16:   "NullInt16",
+	analysis.LiteralInt32:   "NullInt32",
+	analysis.LiteralInt64:   "NullInt64",
+	analysis.LiteralFloat64: "NullFloat64",
+	analysis.LiteralString:  "NullString",
+	analysis.LiteralTime:    "NullTime",
+}
+
+// modelPkgPaths maps the package qualifiers of
+// the models' field types to their import paths.
+var modelPkgPaths = map[string]string{
+	"big":  "math/big",
+	"net":  "net",
+	"time": "time",
+}
+
+// WriteModels generates a Go struct type for each of the given models,
+// with a field for each of the model's columns, and writes the result to f.
+// The types are named based on cfg's ModelsTypeNameFormat and ModelsSingular
+// values, and the fields of NULLable columns are generated based on cfg's
+// ModelsNULLable value.
+func WriteModels(f io.Writer, pkgName string, models []*postgres.Model, cfg config.Config) error {
+	file := new(file)
+
+	// the schema is prepended to the names of the types of relations that
+	// have the same name and are in different schemas, e.g. "audit.users"
+	// and "public.users" produce the AuditUser and PublicUser types
+	names := make([]string, len(models))
+	count := make(map[string]int, len(models))
+	for i, m := range models {
+		names[i] = m.GoName(cfg.ModelsSingular.Value)
+		count[names[i]] += 1
+	}
+	for i, m := range models {
+		if count[names[i]] > 1 {
+			names[i] = m.SchemaGoName() + names[i]
+		}
+	}
+
+	for i, m := range models {
+		buildModelCode(file, m, modelTypeName(cfg, names[i]), cfg.ModelsNULLable.Value)
+	}
+
+	file.PkgName = pkgName
+	file.Preamble = GO.LineComment{filePreamble}
+	if len(file.impset) > 0 {
+		file.Imports = []GO.ImportDeclNode{newImportDecl(file)}
+	}
+	return GO.Write(file, f)
+}
+
+// buildModelCode builds the declaration of the Go struct
+// type of the given model and appends it to the file.
+func buildModelCode(f *file, m *postgres.Model, name string, nullable string) {
+	kind := "table"
+	if !m.Relation.IsTable() {
+		kind = "view"
+	}
+
+	fields := make(GO.FieldList, 0, len(m.Fields))
+	seen := make(map[string]bool, len(m.Fields))
+	for _, mf := range m.Fields {
+		fname := mf.GoName()
+		if seen[fname] {
+			fname += strconv.Itoa(int(mf.Column.Num))
+		}
+		seen[fname] = true
+
+		typ, tag := string(mf.Type), `sql:"`+mf.Column.Name
+		if mf.IsNULLable && !mf.IsNilable() {
+			switch nt, ok := modelNULLTypes[mf.Type]; {
+			case nullable == "coalesce":
+				tag += ",coalesce"
+			case nullable == "sqlnull" && ok:
+				typ = "sql." + nt
+			default:
+				typ = "*" + typ
+			}
+		}
+		if mf.Column.IsPrimary {
+			tag += ",pk"
+		}
+		tag += `"`
+
+		addModelImport(f, typ)
+		fields = append(fields, GO.Field{
+			Names: GO.Ident{fname},
+			Type:  GO.Ident{typ},
+			Tag:   GO.RawStringLit(tag),
+		})
+	}
+
+	typedecl := GO.TypeDecl{}
+	typedecl.Doc = GO.LineComment{" " + name + " represents a record of the \"" + m.Id.QualifiedName() + "\" " + kind + "."}
+	typedecl.Spec = GO.TypeSpec{Name: GO.Ident{name}, Type: GO.StructType{Fields: fields}}
+	f.Decls = append(f.Decls, typedecl)
+}
+
+// modelTypeName returns the name of the model's struct type
+// by formatting the given name with cfg's ModelsTypeNameFormat.
+func modelTypeName(cfg config.Config, name string) string {
+	return strings.Replace(cfg.ModelsTypeNameFormat.Value, "%s", name, 1)
+}
+
+// addModelImport adds the import of the package referenced
+// by the given field type to the file, if there is one.
+func addModelImport(f *file, typ string) {
+	typ = strings.TrimLeft(typ, "*[]0123456789")
+	if i := strings.IndexByte(typ, '.'); i > -1 {
+		if typ[:i] == "sql" {
+			addimport(f, "database/sql", "")
+		} else if path, ok := modelPkgPaths[typ[:i]]; ok {
+			addimport(f, path, "")
+		}
+	}
+}
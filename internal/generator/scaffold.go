@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"io"
+	"strings"
+
+	"github.com/frk/gosql/internal/config"
+	"github.com/frk/gosql/internal/postgres"
+
+	GO "github.com/frk/ast/golang"
+)
+
+const scaffoldPreamble = ` This file was scaffolded by "github.com/frk/gosql", it is meant to be edited.`
+
+// WriteScaffold generates the query types for the common operations on the
+// given model's relation and writes the result to f. The query types use the
+// model's struct type as generated by WriteModels, which is expected to be
+// declared in the same package. The types that look up a single record require
+// the relation to have a primary key, and the upsert requires the relation to
+// have a unique constraint, a unique index, or a primary key, the types that cannot be wired to
+// such keys are omitted.
+func WriteScaffold(f io.Writer, pkgName string, m *postgres.Model, cfg config.Config) error {
+	file := new(file)
+	addimport(file, gosqlPkgPath, gosqlPkgName)
+
+	sc := &scaffold{m: m, rel: m.Id.QualifiedName(), name: m.GoName(true)}
+	sc.typ = modelTypeName(cfg, m.GoName(cfg.ModelsSingular.Value))
+	sc.pkeys = m.PKeyFields()
+	for _, mf := range m.Fields {
+		if mf.Column.IsGenerated {
+			sc.generated = append(sc.generated, mf.Column.Name)
+		}
+	}
+
+	if len(sc.pkeys) > 0 {
+		file.Decls = append(file.Decls, sc.selectByPKey())
+	}
+	file.Decls = append(file.Decls, sc.selectList())
+	file.Decls = append(file.Decls, sc.insert())
+	if len(sc.pkeys) > 0 {
+		file.Decls = append(file.Decls, sc.updateByPKey())
+		file.Decls = append(file.Decls, sc.deleteByPKey())
+	}
+	if target := m.UpsertTarget(); target != nil {
+		file.Decls = append(file.Decls, sc.upsert(target))
+	}
+
+	file.PkgName = pkgName
+	file.Preamble = GO.LineComment{scaffoldPreamble}
+	file.Imports = []GO.ImportDeclNode{newImportDecl(file)}
+	return GO.Write(file, f)
+}
+
+// scaffold holds the state of the scaffolding of a model's query types.
+type scaffold struct {
+	m *postgres.Model
+	// The relation identifier used in the `rel` tags.
+	rel string
+	// The singular Go name of the relation, used in the query type names.
+	name string
+	// The name of the model's struct type.
+	typ string
+	// The fields of the primary key columns.
+	pkeys []*postgres.ModelField
+	// The names of the stored generated columns.
+	generated []string
+}
+
+// selectByPKey produces the type that selects a single record by its primary key.
+func (sc *scaffold) selectByPKey() GO.TypeDecl {
+	var by []string
+	for _, mf := range sc.pkeys {
+		by = append(by, mf.GoName())
+	}
+	name := "Select" + sc.name + "By" + strings.Join(by, "And")
+
+	return sc.decl(name, "selects a single record of the %s by its primary key.", GO.FieldList{
+		sc.recordField(),
+		sc.whereField(),
+	})
+}
+
+// selectList produces the type that selects a filtered list of records.
+func (sc *scaffold) selectList() GO.TypeDecl {
+	name := "Select" + sc.name + "List"
+	return sc.decl(name, "selects a filtered list of records of the %s.", GO.FieldList{
+		{Names: GO.Ident{sc.m.GoName(false)}, Type: GO.Ident{"[]*" + sc.typ}, Tag: GO.RawStringLit(`rel:"` + sc.rel + `"`)},
+		{Type: GO.Ident{"gosql.Filter"}},
+	})
+}
+
+// insert produces the type that inserts a single record and returns the
+// inserted record's columns, the primary key columns that have a default
+// value, and the generated columns, are set to their defaults.
+func (sc *scaffold) insert() GO.TypeDecl {
+	name := "Insert" + sc.name
+	fields := GO.FieldList{sc.recordField()}
+	if f, ok := sc.defaultField(true); ok {
+		fields = append(fields, f)
+	}
+	fields = append(fields, sc.returnField())
+	return sc.decl(name, "inserts a single record into the %s.", fields)
+}
+
+// updateByPKey produces the type that updates a single record by its primary key.
+func (sc *scaffold) updateByPKey() GO.TypeDecl {
+	name := "Update" + sc.name
+	fields := GO.FieldList{sc.recordField()}
+	if f, ok := sc.defaultField(false); ok {
+		fields = append(fields, f)
+	}
+	return sc.decl(name, "updates a single record of the %s by its primary key.", fields)
+}
+
+// deleteByPKey produces the type that deletes a single record by its primary key.
+func (sc *scaffold) deleteByPKey() GO.TypeDecl {
+	name := "Delete" + sc.name
+	return sc.decl(name, "deletes a single record of the %s by its primary key.", GO.FieldList{
+		{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Relation"}, Tag: GO.RawStringLit(`rel:"` + sc.rel + `"`)},
+		sc.whereField(),
+	})
+}
+
+// upsert produces the type that inserts a single record, or, if the record
+// conflicts with an existing one on the given target, updates the existing
+// record's columns that are not part of the target's key.
+func (sc *scaffold) upsert(target *postgres.UpsertTarget) GO.TypeDecl {
+	name := "InsertOrUpdate" + sc.name
+	fields := GO.FieldList{sc.recordField()}
+
+	// the defaults of the primary key would
+	// prevent a conflict on the primary key
+	if f, ok := sc.defaultField(!target.IsPrimary); ok {
+		fields = append(fields, f)
+	}
+
+	var cols []string
+	for _, mf := range sc.m.Fields {
+		if !mf.Column.IsPrimary && !mf.Column.IsGenerated && !containsModelField(target.Fields, mf) {
+			cols = append(cols, mf.Column.Name)
+		}
+	}
+	action := GO.Field{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Ignore"}}
+	if len(cols) > 0 {
+		action = GO.Field{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Update"}, Tag: GO.RawStringLit(`sql:"` + strings.Join(cols, ",") + `"`)}
+	}
+
+	conflict := GO.Field{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Constraint"}, Tag: GO.RawStringLit(`sql:"` + target.Name + `"`)}
+	if target.IsIndex {
+		conflict.Type = GO.Ident{"gosql.Index"}
+	}
+	fields = append(fields, GO.Field{Names: GO.Ident{"OnConflict"}, Type: GO.StructType{Fields: GO.FieldList{
+		conflict,
+		action,
+	}}})
+	fields = append(fields, sc.returnField())
+	return sc.decl(name, "inserts a single record into the %s, or updates the record that conflicts with it.", fields)
+}
+
+// decl produces the declaration of the named query type with the given
+// fields, doc is the remainder of the type's doc comment whose "%s" verb
+// is replaced by a description of the relation.
+func (sc *scaffold) decl(name, doc string, fields GO.FieldList) GO.TypeDecl {
+	kind := "table"
+	if !sc.m.Relation.IsTable() {
+		kind = "view"
+	}
+	doc = strings.Replace(doc, "%s", "\""+sc.rel+"\" "+kind, 1)
+
+	typedecl := GO.TypeDecl{}
+	typedecl.Doc = GO.LineComment{" " + name + " " + doc}
+	typedecl.Spec = GO.TypeSpec{Name: GO.Ident{name}, Type: GO.StructType{Fields: fields}}
+	return typedecl
+}
+
+// recordField produces the field that holds a single record of the model.
+func (sc *scaffold) recordField() GO.Field {
+	return GO.Field{Names: GO.Ident{sc.name}, Type: GO.Ident{"*" + sc.typ}, Tag: GO.RawStringLit(`rel:"` + sc.rel + `"`)}
+}
+
+// whereField produces the where block that matches the primary key columns.
+func (sc *scaffold) whereField() GO.Field {
+	fields := make(GO.FieldList, len(sc.pkeys))
+	for i, mf := range sc.pkeys {
+		fields[i] = GO.Field{Names: GO.Ident{mf.GoName()}, Type: GO.Ident{string(mf.Type)}, Tag: GO.RawStringLit(`sql:"` + mf.Column.Name + `"`)}
+	}
+	return GO.Field{Names: GO.Ident{"Where"}, Type: GO.StructType{Fields: fields}}
+}
+
+// returnField produces the directive that returns all of the record's columns.
+func (sc *scaffold) returnField() GO.Field {
+	return GO.Field{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Return"}, Tag: GO.RawStringLit(`sql:"*"`)}
+}
+
+// defaultField produces the directive that sets the generated columns, and, if
+// pkeys is true, the primary key columns that have a default value, or are identity
+// columns, to their defaults. If there are no such columns false is returned.
+func (sc *scaffold) defaultField(pkeys bool) (GO.Field, bool) {
+	var cols []string
+	if pkeys {
+		for _, mf := range sc.pkeys {
+			if mf.Column.HasDefault || len(mf.Column.Identity) > 0 {
+				cols = append(cols, mf.Column.Name)
+			}
+		}
+	}
+	cols = append(cols, sc.generated...)
+	if len(cols) == 0 {
+		return GO.Field{}, false
+	}
+	return GO.Field{Names: GO.Ident{"_"}, Type: GO.Ident{"gosql.Default"}, Tag: GO.RawStringLit(`sql:"` + strings.Join(cols, ",") + `"`)}, true
+}
+
+// containsModelField reports whether or not the list contains the field.
+func containsModelField(list []*postgres.ModelField, mf *postgres.ModelField) bool {
+	for _, f := range list {
+		if f == mf {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,384 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/frk/gosql/internal/analysis"
+)
+
+// The name of the prepared statement used by Explain.
+const explainStmtName = "gosql_explain"
+
+// advisor accumulates the advice produced for a single target.
+type advisor struct {
+	c *checker
+	// The columns and expressions that have already been advised on,
+	// used to avoid repeating the same advice for the same target.
+	seen map[string]bool
+}
+
+// add appends a new Advice to the checker's result. The position of the
+// advice is that of the field denoted by ptr, or that of the target type
+// if ptr does not denote a field.
+func (a *advisor) add(kind AdviceKind, ptr analysis.FieldPtr, rel *Relation, cols []string, msg string) {
+	key := string(kind) + ":" + rel.Schema + "." + rel.Name + ":" + strings.Join(cols, ",")
+	if a.seen[key] {
+		return
+	}
+	a.seen[key] = true
+
+	a.c.res.Advice = append(a.c.res.Advice, newAdvice(a.c, kind, ptr, rel.Schema+"."+rel.Name, cols, msg))
+}
+
+// newAdvice returns a new Advice for the checker's target.
+func newAdvice(c *checker, kind AdviceKind, ptr analysis.FieldPtr, relname string, cols []string, msg string) *Advice {
+	adv := &Advice{Kind: kind, Relation: relname, Columns: cols, Message: msg}
+	adv.Target = c.info.PkgPath + "." + c.info.TypeName
+
+	pos := c.info.FileSet.Position(c.info.TypeNamePos)
+	if f, ok := c.info.FieldMap[ptr]; ok {
+		pos = c.info.FileSet.Position(f.Var.Pos())
+		adv.Field = f.Var.Name()
+	}
+	adv.File, adv.Line = pos.Filename, pos.Line
+	return adv
+}
+
+// adviseIndexes produces advice on the columns of the target's tables that
+// the target filters, joins, or sorts by and that are not covered by an index.
+// The advice is only as good as the columns' order in the indexes, the advisor
+// does not consider the selectivity of the columns nor the size of the tables.
+//
+// CHECKLIST:
+//
+//	✅ The columns of a table that are compared to the values of a where struct's
+//	   fields SHOULD include the leading key column of one of the table's indexes.
+//	✅ A column that is compared after being passed to a function, e.g. "@lower",
+//	   SHOULD have an expression index whose leading expression is that function call.
+//	✅ One of the two columns of a join condition SHOULD be the leading key column
+//	   of one of its table's indexes.
+//	✅ The first column of a gosql.OrderBy directive SHOULD be the leading key
+//	   column of one of its table's indexes.
+//	✅ Each column of a filter struct SHOULD be the leading key column
+//	   of one of its table's indexes.
+func adviseIndexes(c *checker) {
+	a := &advisor{c: c, seen: make(map[string]bool)}
+
+	switch s := c.res.Struct.(type) {
+	case *analysis.FilterStruct:
+		for _, f := range c.res.Filters {
+			if col := f.Column; isAdvisable(col) && !isLeadingKey(col) {
+				a.add(AdviceNoIndex, f.Field, col.Relation, []string{col.Name}, fmt.Sprintf(
+					"column %q of table %q is not the leading key column of any index",
+					col.Name, col.Relation.Name))
+			}
+		}
+	case *analysis.QueryStruct:
+		if s.Kind == analysis.QueryKindInsert {
+			return
+		}
+
+		if s.Where != nil {
+			adviseWhere(a, c.res.Where, s.Where)
+		}
+		if s.Join != nil {
+			for i, dir := range s.Join.Directives {
+				adviseJoin(a, c.res.Joins[i], dir)
+			}
+		}
+		for _, r := range c.res.Reads {
+			if conds, ok := c.res.ManyJoins[r.Field]; ok {
+				adviseJoin(a, conds, r.Field)
+			}
+		}
+		if s.OrderBy != nil && len(s.OrderBy.Items) > 0 {
+			col, _ := findColumn(c, s.OrderBy.Items[0].ColIdent)
+			if isAdvisable(col) && !isLeadingKey(col) {
+				a.add(AdviceNoIndex, s.OrderBy, col.Relation, []string{col.Name}, fmt.Sprintf(
+					"the rows are sorted by column %q of table %q which is not"+
+						" the leading key column of any index", col.Name, col.Relation.Name))
+			}
+		}
+	}
+}
+
+// adviseWhere produces advice on the columns of the given where conditionals.
+// The columns are grouped by their table, the table is considered covered if
+// any one of its filtered columns is the leading key column of an index.
+func adviseWhere(a *advisor, conds []WhereConditional, ptr analysis.FieldPtr) {
+	type group struct {
+		rel  *Relation
+		cols []*Column
+		ptr  analysis.FieldPtr
+	}
+	var groups []*group
+
+	addcol := func(col *Column, ptr analysis.FieldPtr) {
+		if !isAdvisable(col) {
+			return
+		}
+		for _, g := range groups {
+			if g.rel == col.Relation {
+				for _, c := range g.cols {
+					if c == col {
+						return
+					}
+				}
+				g.cols = append(g.cols, col)
+				return
+			}
+		}
+		groups = append(groups, &group{rel: col.Relation, cols: []*Column{col}, ptr: ptr})
+	}
+
+	var walk func(conds []WhereConditional)
+	walk = func(conds []WhereConditional) {
+		for _, cond := range conds {
+			switch cond := cond.(type) {
+			case *FieldConditional:
+				if len(cond.FuncName) > 0 {
+					adviseFuncCall(a, cond)
+				} else {
+					addcol(cond.Column, cond.Field)
+				}
+			case *ColumnConditional:
+				if cond.RHSColumn != nil {
+					adviseColumnPair(a, cond, ptr)
+				} else {
+					addcol(cond.LHSColumn, ptr)
+				}
+			case *BetweenConditional:
+				addcol(cond.Column, ptr)
+			case *NestedConditional:
+				walk(cond.Conditionals)
+			}
+		}
+	}
+	walk(conds)
+
+	for _, g := range groups {
+		covered := false
+		names := make([]string, len(g.cols))
+		for i, col := range g.cols {
+			names[i] = col.Name
+			covered = covered || isLeadingKey(col)
+		}
+		if !covered {
+			a.add(AdviceNoIndex, g.ptr, g.rel, names, fmt.Sprintf(
+				"none of the filtered columns (%s) of table %q is the leading key column of any index",
+				strings.Join(names, ", "), g.rel.Name))
+		}
+	}
+}
+
+// adviseJoin produces advice on the column pairs of the given join conditionals.
+func adviseJoin(a *advisor, conds []TableJoinConditional, ptr analysis.FieldPtr) {
+	for _, cond := range conds {
+		if cc, ok := cond.(*ColumnConditional); ok && cc.RHSColumn != nil {
+			adviseColumnPair(a, cc, ptr)
+		}
+	}
+}
+
+// adviseColumnPair produces advice on a conditional that compares two columns
+// if neither of the columns is the leading key column of an index.
+func adviseColumnPair(a *advisor, cond *ColumnConditional, ptr analysis.FieldPtr) {
+	lhs, rhs := cond.LHSColumn, cond.RHSColumn
+	if !isAdvisable(lhs) || !isAdvisable(rhs) {
+		return
+	}
+	if isLeadingKey(lhs) || isLeadingKey(rhs) {
+		return
+	}
+
+	a.add(AdviceNoIndex, ptr, lhs.Relation, []string{lhs.Name}, fmt.Sprintf(
+		"neither column %q of table %q nor column %q of table %q is the leading key column"+
+			" of any index", lhs.Name, lhs.Relation.Name, rhs.Name, rhs.Relation.Name))
+}
+
+// adviseFuncCall produces advice on a conditional that compares the result of
+// a function call on a column if there is no index on that function call.
+func adviseFuncCall(a *advisor, cond *FieldConditional) {
+	col := cond.Column
+	if !isAdvisable(col) {
+		return
+	}
+
+	want := normalizeIndexExpr(string(cond.FuncName) + "(" + col.Name + ")")
+	for _, ind := range col.Relation.Indexes {
+		if len(ind.Key) > 0 && ind.Key[0] == 0 && normalizeIndexExpr(leadingIndexExpr(ind.Expression)) == want {
+			return
+		}
+	}
+
+	a.add(AdviceNoExprIndex, cond.Field, col.Relation, []string{col.Name}, fmt.Sprintf(
+		"table %q has no index on the expression %s(%s)", col.Relation.Name, cond.FuncName, col.Name))
+}
+
+// isAdvisable reports whether or not the given column belongs to a table whose
+// indexes can be advised on. Views, and foreign tables, cannot be indexed, and
+// the columns of materialized views are left alone since these are usually small.
+func isAdvisable(col *Column) bool {
+	if col == nil || col.Relation == nil {
+		return false
+	}
+	return col.Relation.RelKind == RelKindOrdinaryTable || col.Relation.RelKind == RelKindPartitionedTable
+}
+
+// isLeadingKey reports whether or not the given column is the
+// leading key column of one of its relation's indexes.
+func isLeadingKey(col *Column) bool {
+	for _, ind := range col.Relation.Indexes {
+		if len(ind.Key) > 0 && ind.Key[0] == col.Num {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingIndexExpr returns the first element of the given index expression.
+func leadingIndexExpr(expr string) string {
+	var nested int
+	var quoted bool
+	for i, r := range expr {
+		switch {
+		case r == '"' || r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			nested += 1
+		case r == ')':
+			nested -= 1
+		case r == ',' && nested == 0:
+			return expr[:i]
+		}
+	}
+	return expr
+}
+
+var rxIndexExprCast = regexp.MustCompile(`::[\w\s"\.\[\]]+`)
+
+// normalizeIndexExpr removes the type casts, quotes, parentheses, and spaces from
+// the given expression so that a function call on a column, e.g. lower(email),
+// can be compared to the server's definition of it, e.g. lower((email)::text).
+func normalizeIndexExpr(expr string) string {
+	expr = rxIndexExprCast.ReplaceAllString(expr, "")
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '(', ')', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(expr))
+}
+
+// Explain has the server explain the generic plan of the given query, generated
+// for the given target, and returns advice on each sequential scan in the plan
+// on a table whose estimated number of rows is at least minRows. The statement
+// is prepared inside a transaction that is always rolled back.
+//
+// Generic plans can be forced only on PostgreSQL 12 and later, on earlier
+// versions Explain returns no advice.
+func Explain(db *DB, info *TargetInfo, query string, minRows int) ([]*Advice, error) {
+	if db.version < 120000 {
+		return nil, nil
+	}
+	c := &checker{db: db, info: info.Info}
+
+	stmt, err := prepareStmt(db, explainStmtName, query)
+	if err != nil {
+		return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+	}
+	defer stmt.close()
+
+	// plan_cache_mode takes effect when the statement is executed
+	if _, err := stmt.Exec(`SET LOCAL plan_cache_mode = force_generic_plan`); err != nil {
+		return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+	}
+
+	var nparams int
+	const selectNumParams = `SELECT
+		cardinality(s.parameter_types)
+	FROM pg_prepared_statements s
+	WHERE s.name = $1` //`
+	if err := stmt.QueryRow(selectNumParams, explainStmtName).Scan(&nparams); err != nil {
+		return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+	}
+
+	// The values of the parameters do not
+	// affect the generic plan, NULL will do.
+	explain := `EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE ` + explainStmtName
+	if nparams > 0 {
+		explain += `(` + strings.TrimSuffix(strings.Repeat(`NULL, `, nparams), `, `) + `)`
+	}
+
+	var data []byte
+	if err := stmt.QueryRow(explain).Scan(&data); err != nil {
+		return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+	}
+	var plans []struct{ Plan *planNode }
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+	}
+
+	var scans []*planNode
+	for _, p := range plans {
+		scans = p.Plan.appendSeqScans(scans)
+	}
+
+	const selectRelTuples = `SELECT
+		greatest(c.reltuples, 0)::int8
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1
+	AND c.relname = $2` //`
+
+	var list []*Advice
+	seen := make(map[string]bool)
+	for _, scan := range scans {
+		relname := scan.Schema + "." + scan.RelationName
+		if seen[relname] {
+			continue
+		}
+		seen[relname] = true
+
+		var rows int64
+		if err := stmt.QueryRow(selectRelTuples, scan.Schema, scan.RelationName).Scan(&rows); err != nil {
+			return nil, c.dbError(dbError{Code: errQueryExplain, Query: query, Err: err}, nil)
+		}
+		if rows < int64(minRows) {
+			continue
+		}
+
+		list = append(list, newAdvice(c, AdviceSeqScan, nil, relname, nil, fmt.Sprintf(
+			"the generic plan of the query uses a sequential scan on table %q (about %d rows)",
+			scan.RelationName, rows)))
+	}
+	return list, nil
+}
+
+// planNode is a node of a plan as output by EXPLAIN (FORMAT JSON).
+type planNode struct {
+	NodeType     string      `json:"Node Type"`
+	RelationName string      `json:"Relation Name"`
+	Schema       string      `json:"Schema"`
+	Plans        []*planNode `json:"Plans"`
+}
+
+// appendSeqScans appends the sequential scan nodes of the plan tree rooted
+// at the receiver to the given slice and returns the resulting slice.
+func (n *planNode) appendSeqScans(scans []*planNode) []*planNode {
+	if n == nil {
+		return scans
+	}
+	if n.NodeType == "Seq Scan" && len(n.RelationName) > 0 {
+		scans = append(scans, n)
+	}
+	for _, p := range n.Plans {
+		scans = p.appendSeqScans(scans)
+	}
+	return scans
+}
@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/postgres/oid"
+
+	"github.com/lib/pq"
+)
+
+// selectCatalogFingerprint selects a value that changes whenever one of the
+// catalog objects loaded by loadCatalog is created, modified, or dropped. The
+// xmin of a row is set to the id of the transaction that created the row's
+// current version, so the sum of a table's xmins changes with every insert,
+// update, and delete, and the number of rows catches the rest.
+const selectCatalogFingerprint = `SELECT concat_ws(':'
+	, current_setting('server_version_num')
+	, current_setting('search_path')
+	, current_user
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_namespace)
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_type)
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_enum)
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_operator)
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_cast)
+	, (SELECT count(*) || '/' || sum(xmin::text::bigint) FROM pg_proc)
+	, (SELECT count(*) || '/' || sum(a.xmin::text::bigint) FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid WHERE c.relkind = 'c')
+)` //`
+
+// relFingerprintExpr is an expression that evaluates to a value that changes
+// whenever the definition of the relation "c" (a pg_class row) is modified,
+// including the columns, constraints, and indexes of the relation, and, if
+// the relation is a view, the columns of the view's base relations.
+const relFingerprintExpr = `concat_ws(':'
+		, c.xmin
+		, (SELECT count(*) || '/' || sum(a.xmin::text::bigint) FROM pg_attribute a WHERE a.attrelid = c.oid)
+		, (SELECT count(*) || '/' || sum(x.xmin::text::bigint) FROM pg_constraint x WHERE x.conrelid = c.oid)
+		, (SELECT count(*) || '/' || sum(i.xmin::text::bigint) FROM pg_index i WHERE i.indrelid = c.oid)
+		, (SELECT count(*) || '/' || sum(r.xmin::text::bigint) FROM pg_rewrite r WHERE r.ev_class = c.oid)
+		, (SELECT count(*) || '/' || sum(a.xmin::text::bigint) FROM pg_rewrite r
+			JOIN pg_depend d ON (
+				d.classid = 'pg_rewrite'::regclass
+				AND d.objid = r.oid
+				AND d.refclassid = 'pg_class'::regclass
+			)
+			JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+			WHERE r.ev_class = c.oid)
+	)` //`
+
+// catalogCacheVersion is the version of the cache file's format, it must be
+// incremented whenever one of the types stored in the cache file is modified.
+const catalogCacheVersion = 2
+
+// catalogCacheFile is the on-disk representation of a Catalog. The pointers
+// from columns to their types and relations are not stored, they are restored
+// from the column's TypeOID and from the containing relation when read.
+type catalogCacheFile struct {
+	// The version of the format with which the file was written.
+	Version int
+	// The fingerprint of the catalog at the time it was loaded.
+	Fingerprint string
+	Types       []*Type
+	Operators   []*Operator
+	Casts       []*Cast
+	Procs       []*Proc
+	Relations   []*relationCacheEntry
+}
+
+// relationCacheEntry is the on-disk representation of a loaded relation.
+type relationCacheEntry struct {
+	// The identifier with which the relation was loaded.
+	Id analysis.RelIdent
+	// The fingerprint of the relation at the time it was loaded.
+	Fingerprint string
+	Relation    *Relation
+}
+
+// catalogCachePath returns the path of the given database's cache file.
+func catalogCachePath(db *DB) string {
+	sum := sha256.Sum256([]byte(db.dsn))
+	return filepath.Join(db.cacheDir, "catalog_"+hex.EncodeToString(sum[:8])+".gob")
+}
+
+// readCatalogCache reads the catalog from the given database's cache file.
+// If the file does not exist, cannot be decoded, or if its fingerprint does
+// not match the given fingerprint, or if it was written with a different
+// version of the format, readCatalogCache returns nil. Of the cached
+// relations only those that have not been modified since they were cached
+// are added to the returned catalog.
+func readCatalogCache(db *DB, fingerprint string) (*Catalog, error) {
+	f, err := os.Open(catalogCachePath(db))
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	file := new(catalogCacheFile)
+	if err := gob.NewDecoder(f).Decode(file); err != nil || file.Version != catalogCacheVersion ||
+		file.Fingerprint != fingerprint {
+		return nil, nil
+	}
+
+	cat := new(Catalog)
+	cat.Types = make(map[oid.OID]*Type, len(file.Types))
+	cat.Operators = make(map[OpKey]*Operator, len(file.Operators))
+	cat.Casts = make(map[CastKey]*Cast, len(file.Casts))
+	cat.Procs = make(map[string][]*Proc)
+	cat.Relations = make(map[analysis.RelIdent]*Relation)
+	cat.fingerprint = fingerprint
+	cat.relFingerprints = make(map[oid.OID]string)
+
+	for _, typ := range file.Types {
+		cat.Types[typ.OID] = typ
+	}
+	for _, typ := range file.Types {
+		for _, attr := range typ.Attributes {
+			attr.Type = cat.Types[attr.TypeOID]
+		}
+	}
+	for _, op := range file.Operators {
+		cat.Operators[OpKey{Name: op.Name, Left: op.Left, Right: op.Right}] = op
+	}
+	for _, cast := range file.Casts {
+		cat.Casts[CastKey{Target: cast.Target, Source: cast.Source}] = cast
+	}
+	for _, proc := range file.Procs {
+		cat.Procs[proc.Name] = append(cat.Procs[proc.Name], proc)
+	}
+
+	if len(file.Relations) == 0 {
+		return cat, nil
+	}
+
+	names := make([]string, len(file.Relations))
+	for i, entry := range file.Relations {
+		names[i] = entry.Id.QualifiedName()
+	}
+
+	// The identifiers are resolved again to catch the relations
+	// that are now shadowed by a relation in another schema.
+	const selectRelationFingerprints = `SELECT
+		x.num
+		, c.oid
+		, ` + relFingerprintExpr + `
+	FROM unnest($1::text[]) WITH ORDINALITY AS x(name, num)
+	JOIN pg_class c ON c.oid = to_regclass(x.name)` //`
+	rows, err := db.Query(selectRelationFingerprints, pq.Array(names))
+	if err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogCacheRead, Err: err})
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var num int
+		var reloid oid.OID
+		var fingerprint string
+		if err := rows.Scan(&num, &reloid, &fingerprint); err != nil {
+			return nil, db.dbError(dbError{Code: errCatalogCacheRead, Err: err})
+		}
+
+		entry := file.Relations[num-1]
+		if rel := entry.Relation; rel.OID == reloid && entry.Fingerprint == fingerprint {
+			for _, col := range rel.Columns {
+				col.Type = cat.Types[col.TypeOID]
+				col.Relation = rel
+			}
+			cat.Relations[entry.Id] = rel
+			cat.relFingerprints[rel.OID] = fingerprint
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogCacheRead, Err: err})
+	}
+	return cat, nil
+}
+
+// WriteCache writes the database's catalog, including the relations loaded
+// by the type checker, to the cache file. WriteCache is a no-op if the DB
+// was not opened with a cache directory.
+func (db *DB) WriteCache() error {
+	cat := db.catalog
+	if len(db.cacheDir) == 0 || len(cat.fingerprint) == 0 {
+		return nil
+	}
+
+	file := new(catalogCacheFile)
+	file.Version = catalogCacheVersion
+	file.Fingerprint = cat.fingerprint
+	for _, typ := range cat.Types {
+		if len(typ.Attributes) > 0 {
+			t := *typ
+			t.Attributes = make([]*Column, len(typ.Attributes))
+			for i, attr := range typ.Attributes {
+				a := *attr
+				a.Type = nil
+				t.Attributes[i] = &a
+			}
+			typ = &t
+		}
+		file.Types = append(file.Types, typ)
+	}
+	for _, op := range cat.Operators {
+		file.Operators = append(file.Operators, op)
+	}
+	for _, cast := range cat.Casts {
+		file.Casts = append(file.Casts, cast)
+	}
+	for _, procs := range cat.Procs {
+		file.Procs = append(file.Procs, procs...)
+	}
+
+	cat.RLock()
+	for rid, rel := range cat.Relations {
+		r := *rel
+		r.Columns = make([]*Column, len(rel.Columns))
+		for i, col := range rel.Columns {
+			c := *col
+			c.Type, c.Relation = nil, nil
+			r.Columns[i] = &c
+		}
+		entry := &relationCacheEntry{Id: rid, Fingerprint: cat.relFingerprints[rel.OID], Relation: &r}
+		file.Relations = append(file.Relations, entry)
+	}
+	cat.RUnlock()
+
+	if err := os.MkdirAll(db.cacheDir, 0755); err != nil {
+		return db.dbError(dbError{Code: errCatalogCacheWrite, Err: err})
+	}
+
+	// write to a temporary file first so that a concurrent
+	// reader never encounters a partially written cache file
+	f, err := os.CreateTemp(db.cacheDir, "catalog_*.tmp")
+	if err != nil {
+		return db.dbError(dbError{Code: errCatalogCacheWrite, Err: err})
+	}
+	defer os.Remove(f.Name())
+
+	if err := gob.NewEncoder(f).Encode(file); err != nil {
+		f.Close()
+		return db.dbError(dbError{Code: errCatalogCacheWrite, Err: err})
+	}
+	if err := f.Close(); err != nil {
+		return db.dbError(dbError{Code: errCatalogCacheWrite, Err: err})
+	}
+	if err := os.Rename(f.Name(), catalogCachePath(db)); err != nil {
+		return db.dbError(dbError{Code: errCatalogCacheWrite, Err: err})
+	}
+	return nil
+}
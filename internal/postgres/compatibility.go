@@ -519,6 +519,7 @@ func init_oid2literal() map[compkey]map[analysis.LiteralType]compentry {
 			analysis.LiteralUint64Slice:  {valuer: "NumericArrayFromUint64Slice", scanner: "NumericArrayToUint64Slice"},
 			analysis.LiteralFloat32Slice: {valuer: "NumericArrayFromFloat32Slice", scanner: "NumericArrayToFloat32Slice"},
 			analysis.LiteralFloat64Slice: {valuer: "NumericArrayFromFloat64Slice", scanner: "NumericArrayToFloat64Slice"},
+			analysis.LiteralStringSlice:  {valuer: "NumericArrayFromStringSlice", scanner: "NumericArrayToStringSlice"},
 			analysis.LiteralString:       {},
 			analysis.LiteralByteSlice:    {},
 		},
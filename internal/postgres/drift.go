@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/lib/pq"
+)
+
+// CheckDrift compares the fields of the given target's relation type with the
+// columns of the target's relation and returns the differences between the two.
+// Unlike Check, CheckDrift does not fail on the differences, it fails only if
+// the database cannot be queried. The fields that are mapped to expressions,
+// window functions, child relations, or to columns of other relations, are
+// not compared.
+//
+// CHECKLIST:
+//
+//	✅ The target's relation SHOULD exist in the database.
+//	✅ Each field SHOULD be mapped to a column of the target's relation.
+//	✅ The type of each field SHOULD be compatible with the type of its column.
+//	✅ The type of a field that is read from a NULLable column SHOULD be able
+//	   to hold NULL, or the field SHOULD have the coalesce or nn option.
+func CheckDrift(db *DB, ts analysis.TargetStruct, info *analysis.Info) (*DriftInfo, error) {
+	c := &checker{db: db, info: info}
+	c.relMap = make(map[string]*Relation)
+	c.res = new(TargetInfo)
+	c.res.Struct = ts
+	c.res.Info = info
+	c.schema = defaultSchema(db, info)
+
+	// the fields of a filter struct are not read nor written, and the
+	// fields of a query struct are read only by a select query or by
+	// a query with a gosql.Return directive
+	var rf *analysis.RelField
+	var reads, checks bool
+	switch s := ts.(type) {
+	case *analysis.FilterStruct:
+		rf = s.Rel
+	case *analysis.QueryStruct:
+		rf = s.Rel
+		reads = s.Kind == analysis.QueryKindSelect || s.Return != nil
+		checks = true
+	}
+
+	res := new(DriftInfo)
+	if err := loadTargetRelation(c, rf); err != nil {
+		if e, ok := err.(*dbError); ok && e.Code == errRelationUnknown {
+			res.Drift = append(res.Drift, newDrift(c, DriftDroppedRelation, rf, rf.Id.QualifiedName(), "",
+				fmt.Sprintf("relation %q does not exist", rf.Id.QualifiedName())))
+			return res, nil
+		}
+		return nil, err
+	}
+	res.Relation = c.rel
+	if rf.IsDirective {
+		return res, nil
+	}
+
+	relname := c.rel.Schema + "." + c.rel.Name
+	for _, f := range rf.Type.Fields {
+		if len(f.Expr) > 0 || f.Window != nil || f.Many != nil {
+			continue
+		}
+		if q := f.ColIdent.Qualifier; len(q) > 0 && q != rf.Id.Alias && q != rf.Id.Name {
+			continue
+		}
+
+		col := findRelColumn(c.rel, f.ColIdent.Name)
+		if col == nil {
+			d := newDrift(c, DriftDroppedColumn, f, relname, f.ColIdent.Name, fmt.Sprintf(
+				"column %q of relation %q does not exist", f.ColIdent.Name, relname))
+			if old, ok := fieldPatch(c, f, ""); ok {
+				d.Suggestions = []string{old}
+			}
+			res.Drift = append(res.Drift, d)
+			continue
+		}
+		res.Columns = append(res.Columns, col.Name)
+		if !checks {
+			continue
+		}
+
+		var err error
+		if f.IsWriteOnly() || !reads {
+			_, err = typeCheckFieldWrite(c, f)
+		} else {
+			err = typeCheckFieldReadColumn(c, f, col)
+		}
+		if err != nil {
+			e, ok := err.(*dbError)
+			if !ok {
+				return nil, err
+			}
+
+			// the other errors are not about the relation's
+			// columns and are left to be reported by Check
+			switch e.Code {
+			case errColumnFieldTypeRead, errColumnFieldTypeWrite, errColumnEnumFieldType:
+				res.Drift = append(res.Drift, newTypeDrift(c, f, col))
+			case errColumnNullableRead:
+				if reads {
+					res.Drift = append(res.Drift, newNULLDrift(c, f, col))
+				}
+			}
+			continue
+		}
+
+		if reads && !f.IsWriteOnly() && !isNULLSafeRead(f, col, fieldReadScanner(c, f)) {
+			res.Drift = append(res.Drift, newNULLDrift(c, f, col))
+		}
+	}
+	return res, nil
+}
+
+// CheckUnmappedColumns returns a Drift for each of the given relation's columns
+// whose name is not in the mapped set, i.e. for each of the columns that none
+// of the fields of the struct types mapped to the relation are mapped to. The
+// suggested patch adds a field for the column, the column itself may well be
+// in use elsewhere and so dropping it is never suggested.
+func CheckUnmappedColumns(db *DB, rel *Relation, mapped map[string]bool) []*Drift {
+	c := &checker{db: db, info: &analysis.Info{FileSet: token.NewFileSet()}}
+	relname := rel.Schema + "." + rel.Name
+
+	var drift []*Drift
+	for _, col := range rel.Columns {
+		if mapped[col.Name] {
+			continue
+		}
+
+		d := &Drift{Kind: DriftUnmappedColumn, Database: db.ConfigName, Relation: relname, Column: col.Name}
+		d.Message = fmt.Sprintf("column %q of relation %q is not mapped to any field", col.Name, relname)
+
+		mf := newModelField(c, col)
+		d.Suggestions = []string{fmt.Sprintf("+ %s %s `sql:%q`",
+			mf.GoName(), modelFieldType(mf), col.Name)}
+		drift = append(drift, d)
+	}
+	return drift
+}
+
+// newDrift returns a new Drift for the checker's target. The position of the
+// drift is that of the field denoted by ptr, or that of the target type if
+// ptr does not denote a field.
+func newDrift(c *checker, kind DriftKind, ptr analysis.FieldPtr, relname, colname, msg string) *Drift {
+	d := &Drift{Kind: kind, Database: c.db.ConfigName, Relation: relname, Column: colname, Message: msg}
+	d.Target = c.info.PkgPath + "." + c.info.TypeName
+
+	pos := c.info.FileSet.Position(c.info.TypeNamePos)
+	if f, ok := c.info.FieldMap[ptr]; ok {
+		pos = c.info.FileSet.Position(f.Var.Pos())
+		d.Field = f.Var.Name()
+	}
+	d.File, d.Line = pos.Filename, pos.Line
+	return d
+}
+
+// newTypeDrift returns the Drift of a field whose type is not
+// compatible with the type of the given column. The suggested
+// patch changes the field's type to the column's model type.
+func newTypeDrift(c *checker, f *analysis.FieldInfo, col *Column) *Drift {
+	relname := col.Relation.Schema + "." + col.Relation.Name
+	d := newDrift(c, DriftTypeMismatch, f, relname, col.Name, fmt.Sprintf(
+		"field type %s is not compatible with type %s of column %q",
+		fieldTypeString(c, f), col.Type.NameFmt, col.Name))
+
+	if old, ok := fieldPatch(c, f, ""); ok {
+		patch, _ := fieldPatch(c, f, modelFieldType(newModelField(c, col)))
+		d.Suggestions = []string{old + "\n" + patch}
+	}
+	return d
+}
+
+// newNULLDrift returns the Drift of a field whose type cannot hold the NULL
+// of the given column. The suggested patch changes the field's type to a
+// pointer type, alternatively the column can be made NOT NULL.
+func newNULLDrift(c *checker, f *analysis.FieldInfo, col *Column) *Drift {
+	relname := col.Relation.Schema + "." + col.Relation.Name
+	d := newDrift(c, DriftNULLMismatch, f, relname, col.Name, fmt.Sprintf(
+		"column %q is NULLable but field type %s cannot hold NULL", col.Name, fieldTypeString(c, f)))
+
+	if old, ok := fieldPatch(c, f, ""); ok {
+		patch, _ := fieldPatch(c, f, "*")
+		d.Suggestions = append(d.Suggestions, old+"\n"+patch)
+	}
+	if col.Relation.IsTable() {
+		d.Suggestions = append(d.Suggestions, fmt.Sprintf("ALTER TABLE %s.%s ALTER COLUMN %s SET NOT NULL;",
+			pq.QuoteIdentifier(col.Relation.Schema), pq.QuoteIdentifier(col.Relation.Name),
+			pq.QuoteIdentifier(col.Name)))
+	}
+	return d
+}
+
+// fieldPatch returns the declaration of the given field in the form of a line
+// of a patch. If typ is empty the line removes the field's declaration, if typ
+// is "*" the line adds the field declared with a pointer to its type, otherwise
+// the line adds the field declared with typ. If the field's declaration is not
+// known false is returned.
+func fieldPatch(c *checker, f *analysis.FieldInfo, typ string) (string, bool) {
+	fv, ok := c.info.FieldMap[f]
+	if !ok {
+		return "", false
+	}
+
+	sign := "+"
+	switch typ {
+	case "":
+		sign, typ = "-", fieldTypeString(c, f)
+	case "*":
+		typ = "*" + fieldTypeString(c, f)
+	}
+	return fmt.Sprintf("%s %s %s `%s`", sign, fv.Var.Name(), typ, fv.Tag), true
+}
+
+// fieldTypeString returns the Go type of the given field as it would
+// be declared in the field's package, e.g. "time.Time" or "[]string".
+func fieldTypeString(c *checker, f *analysis.FieldInfo) string {
+	if fv, ok := c.info.FieldMap[f]; ok {
+		return types.TypeString(fv.Var.Type(), (*types.Package).Name)
+	}
+	return string(f.Type.GenericLiteral())
+}
+
+// fieldReadScanner returns the scanner that the checker
+// selected for reading the given field's column, if any.
+func fieldReadScanner(c *checker, f *analysis.FieldInfo) string {
+	for _, read := range c.res.Reads {
+		if read.Field == f {
+			return read.Scanner
+		}
+	}
+	return ""
+}
+
+// modelFieldType returns the Go type with which the "gosql models"
+// command, in its default mode, declares the given field.
+func modelFieldType(mf *ModelField) string {
+	if mf.IsNULLable && !mf.IsNilable() {
+		return "*" + string(mf.Type)
+	}
+	return string(mf.Type)
+}
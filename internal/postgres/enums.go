@@ -20,6 +20,57 @@ const (
 	RelKindPartitionedIndex RelKind = "I"
 )
 
+// relation events, a bitmask of the commands that a relation supports
+// as reported by pg_relation_is_updatable, i.e. (1 << CmdType)
+type RelEvents int
+
+const (
+	RelEventUpdate RelEvents = 1 << 2
+	RelEventInsert RelEvents = 1 << 3
+	RelEventDelete RelEvents = 1 << 4
+)
+
+// advice kinds
+type AdviceKind string
+
+const (
+	// A column used for filtering, joining, or sorting is not
+	// the leading key column of any of the relation's indexes.
+	AdviceNoIndex AdviceKind = "no_index"
+	// A column is filtered by the result of a function call, but
+	// the relation has no index on that function call's result.
+	AdviceNoExprIndex AdviceKind = "no_expression_index"
+	// The generic plan of a query uses a sequential scan on
+	// a relation with more rows than the configured minimum.
+	AdviceSeqScan AdviceKind = "seq_scan"
+)
+
+// drift kinds
+type DriftKind string
+
+const (
+	// A column of a relation is mapped to no field of
+	// the struct types that are mapped to the relation.
+	DriftUnmappedColumn DriftKind = "unmapped_column"
+	// A field is mapped to a column that the relation does not have.
+	DriftDroppedColumn DriftKind = "dropped_column"
+	// A field is mapped to a relation that the database does not have.
+	DriftDroppedRelation DriftKind = "dropped_relation"
+	// The type of a field is not compatible with the type of its column.
+	DriftTypeMismatch DriftKind = "type_mismatch"
+	// A field is mapped to a NULLable column, but its type cannot hold NULL.
+	DriftNULLMismatch DriftKind = "null_mismatch"
+)
+
+// column identity kinds
+type ColumnIdentity string
+
+const (
+	ColumnIdentityNone      ColumnIdentity = ""
+	ColumnIdentityAlways    ColumnIdentity = "a"
+	ColumnIdentityByDefault ColumnIdentity = "d"
+)
+
 // postgres type types
 type TypeType string
 
@@ -28,9 +28,17 @@ const (
 	errCatalogCastScan      // TODO
 	errCatalogProcedureGet  // TODO
 	errCatalogProcedureScan // TODO
+	errCatalogEnumGet       // TODO
+	errCatalogEnumScan      // TODO
+	errCatalogAttributeGet  // TODO
+	errCatalogAttributeScan // TODO
+	errCatalogCacheRead
+	errCatalogCacheWrite
+	errCatalogRelationGet
 
 	// relation errors
 	errRelationUnknown
+	errRelationAmbiguous
 	errRelationScan              // TODO
 	errRelationColumnGet         // TODO
 	errRelationColumnScan        // TODO
@@ -39,13 +47,25 @@ const (
 	errRelationConstraintScan    // TODO
 	errRelationIndexGet          // TODO
 	errRelationIndexScan         // TODO
+	errRelationMaterializedViewWrite
+	errRelationForeignTableCommand
 
 	// column errors
 	errColumnUnknown
 	errColumnDefaultUnset
 	errColumnTextSearchType
+	errColumnSoftDeleteNotNull
+	errColumnAutoNowType
+	errColumnGeneratedForce
+	errColumnIdentityOverride
+	errColumnIdentityUpdate
+	errColumnExprInvalid
+	errColumnEnumFieldType
+	errColumnCompositeAttrUnknown
+	errColumnCompositeAttrType
 	errColumnFieldTypeWrite
 	errColumnFieldTypeRead
+	errColumnNullableRead
 	errColumnFieldComparison
 	errColumnQualifierUnknown
 	errColumnComparison
@@ -60,6 +80,12 @@ const (
 	errBetweenFieldComparison
 	// procedure errors
 	errProcedureUnknown
+	// operator errors
+	errOperatorUnknown
+	errOperatorResultBool
+	// join errors
+	errJoinForeignKeyNone
+	errJoinForeignKeyAmbiguous
 	// on conflict errors
 	errOnConflictIndexUnknown
 	errOnConflictIndexNotUnique
@@ -67,6 +93,15 @@ const (
 	errOnConflictIndexColumnsNotUnique
 	errOnConflictConstraintUnknown
 	errOnConflictConstraintNotUnique
+	errOnConflictForeignTable
+	// privilege errors
+	errPrivilegeGet
+	errPrivilegeMissing
+	// query verification errors
+	errQueryPrepare
+	errQueryParamType
+	// index advisor errors
+	errQueryExplain
 )
 
 type dbError struct {
@@ -80,8 +115,18 @@ type dbError struct {
 	RHSCol  colInfo
 	RHSLit  exprInfo
 	Pred    analysis.Predicate
+	Oper    string
 	Quant   analysis.Quantifier
 	Func    analysis.FuncName
+	Attr    attrInfo
+	Param   paramInfo
+	Priv    privInfo
+	Cons    []string
+	Query   string
+	// The default schema of the target's package, and
+	// the schemas in which a relation was found.
+	Schema  string
+	Schemas []string
 	Err     error `cmp:"+"`
 }
 
@@ -91,6 +136,11 @@ func (e *dbError) Error() string {
 	if err := error_templates.ExecuteTemplate(sb, e.Code.name(), *e); err != nil {
 		panic(err)
 	}
+	if len(e.DB.ConfigName) > 0 {
+		if err := error_templates.ExecuteTemplate(sb, "database_config_name", *e); err != nil {
+			panic(err)
+		}
+	}
 	return sb.String()
 }
 
@@ -112,10 +162,14 @@ func (d dbError) PredString() string {
 }
 
 func (d dbError) PredQuant() string {
+	pred := d.Pred.String()
+	if d.Pred.IsOperator() {
+		pred = d.Oper
+	}
 	if d.Quant > 0 {
-		return d.Pred.String() + d.Quant.String()
+		return pred + d.Quant.String()
 	}
-	return d.Pred.String()
+	return pred
 }
 
 func (d dbError) WBFieldDefinition() string {
@@ -127,11 +181,24 @@ func (d dbError) WBFieldDefinition() string {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+type paramInfo struct {
+	// The ordinal number of the parameter.
+	Num int
+	// The type of the parameter as inferred by the server.
+	Type *Type
+}
+
+func (p paramInfo) Ref() string {
+	return "$" + strconv.Itoa(p.Num)
+}
+
 type dbInfo struct {
 	DSN        string
 	Name       string
 	User       string
 	SearchPath string
+	// The name of the database in the config, if not the default one.
+	ConfigName string
 }
 
 type fileInfo struct {
@@ -227,6 +294,15 @@ func (c colInfo) IdRef() string {
 	return c.Id.Name
 }
 
+type attrInfo struct {
+	// The name of the struct field mapped to the attribute.
+	Field string
+	// The name of the attribute as specified in the struct field's tag.
+	Name string
+	// The attribute, or nil if the composite type has no such attribute.
+	*Column
+}
+
 type relInfo struct {
 	Id analysis.RelIdent
 	*Relation
@@ -244,6 +320,13 @@ type exprInfo struct {
 	Type *Type
 }
 
+type privInfo struct {
+	// The name of the runtime role.
+	Role string
+	// The name of the privilege.
+	Name string
+}
+
 type relIdent analysis.RelIdent
 
 func (id relIdent) Ref() string {
@@ -271,6 +354,10 @@ Helper templates
     - please consider opening an issue if the error persists.
 {{ end }}
 
+{{ define "database_config_name" -}}
+    - checked against the database "{{W .DB.ConfigName}}" (dsn "{{W .DB.DSN}}").
+{{ end }}
+
 {{ define "connection_info" }}
     - database: {{W .DB.Name}} (dsn "{{W .DB.DSN}}")
     - relation: {{W .Rel.Name}} (schema "{{W .Rel.Schema}}")
@@ -309,6 +396,49 @@ Catalog error templates
     {{- template "external_error_issue" . }}
 {{ end }}
 
+{{ define "` + errCatalogEnumGet.name() + `" -}}
+{{Y "Failed to retrieve pg_enum information."}}
+    An error occurred during the retrieval of the {{Wi "pg_enum"}} information from the "{{R .DB.Name}}" database's catalog.
+    {{- template "external_error_issue" . }}
+{{ end }}
+
+{{ define "` + errCatalogEnumScan.name() + `" -}}
+{{Y "Failed to scan pg_enum information."}}
+    An error occurred during the scanning of the {{Wi "pg_enum"}} information from the "{{R .DB.Name}}" database's catalog.
+    {{- template "external_error_issue" . }}
+{{ end }}
+
+{{ define "` + errCatalogAttributeGet.name() + `" -}}
+{{Y "Failed to retrieve composite type pg_attribute information."}}
+    An error occurred during the retrieval of the {{Wi "pg_attribute"}} information of composite types from the "{{R .DB.Name}}" database's catalog.
+    {{- template "external_error_issue" . }}
+{{ end }}
+
+{{ define "` + errCatalogAttributeScan.name() + `" -}}
+{{Y "Failed to scan composite type pg_attribute information."}}
+    An error occurred during the scanning of the {{Wi "pg_attribute"}} information of composite types from the "{{R .DB.Name}}" database's catalog.
+    {{- template "external_error_issue" . }}
+{{ end }}
+
+{{ define "` + errCatalogCacheRead.name() + `" -}}
+{{Y "Failed to read the catalog cache."}}
+    An error occurred during the reading of the cached catalog information of the "{{R .DB.Name}}" database.
+    - original error message: "{{Wb .Err.Error}}"
+    - removing the cache file, or disabling the cache, should resolve the issue.
+{{ end }}
+
+{{ define "` + errCatalogCacheWrite.name() + `" -}}
+{{Y "Failed to write the catalog cache."}}
+    An error occurred during the writing of the cached catalog information of the "{{R .DB.Name}}" database.
+    - original error message: "{{Wb .Err.Error}}"
+{{ end }}
+
+{{ define "` + errCatalogRelationGet.name() + `" -}}
+{{Y "Failed to retrieve pg_class information."}}
+    An error occurred during the retrieval of the {{Wi "pg_class"}} information from the "{{R .DB.Name}}" database's catalog.
+    {{- template "external_error_issue" . }}
+{{ end }}
+
 {{ define "` + errCatalogOperatorGet.name() + `" -}}
 {{Y "Failed to retrieve pg_operator information."}}
     An error occurred during the retrieval of the {{Wi "pg_operator"}} information from the "{{R .DB.Name}}" database's catalog.
@@ -353,12 +483,22 @@ Relation error templates
 {{Wb .Field.File.NameAndLine}}: {{Y "Unknown relation."}}
     The relation "{{R .Rel.Ref}}" referenced in "{{R .Field.Definition}}" does not exist.
     - database: {{W .DB.Name}} (dsn "{{W .DB.DSN}}")
-    {{if not .Rel.Id.Qualifier -}}
+    {{if .Schema -}}
+    - default schema: {{W .Schema}} (package "{{W .Target.Pkg}}")
+    {{else if not .Rel.Id.Qualifier -}}
     - search path: {{Wb .DB.SearchPath}}
     {{end -}}
     - target type: {{W .Target.Name}} (source "{{W .Target.File.NameAndLine}}")
 {{ end }}
 
+{{ define "` + errRelationAmbiguous.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Ambiguous relation."}}
+    The relation "{{R .Rel.Ref}}" referenced in "{{R .Field.Definition}}" is not in the default schema ` +
+	`"{{W .Schema}}" of the package "{{W .Target.Pkg}}" and it exists in more than one of the other schemas.
+    - schemas: {{range $i, $s := .Schemas}}{{if $i}}, {{end}}"{{W $s}}"{{end}}.
+    - qualify the relation's name with the name of its schema, e.g. {{raw "sql:\"<schema>.<relation>\""}}.
+{{ end }}
+
 {{ define "` + errRelationScan.name() + `" -}}
 {{Wb .Field.File.NameAndLine}}: {{Y "Failed to scan relation."}}
     An error occurred during the scanning of the "{{R .Rel.Ref}}" relation's information.
@@ -408,6 +548,19 @@ Relation error templates
     {{- template "external_error_issue" . }}
 {{ end }}
 
+{{ define "` + errRelationMaterializedViewWrite.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad target relation."}}
+    The relation "{{R .Rel.Ref}}" referenced in "{{R .Field.Definition}}" is a {{Wb "MATERIALIZED VIEW"}}.
+    - a materialized view is read-only, its data can only be changed with {{Wb "REFRESH MATERIALIZED VIEW"}}, ` +
+	`therefore it MUST NOT be the target of a {{W .Target.Name}} type.
+{{ end }}
+
+{{ define "` + errRelationForeignTableCommand.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad target relation."}}
+    The relation "{{R .Rel.Ref}}" referenced in "{{R .Field.Definition}}" is a {{Wb "FOREIGN TABLE"}}` +
+	` whose foreign-data wrapper "{{R .Rel.ForeignDataWrapper}}" does not support the command of the {{W .Target.Name}} type.
+{{ end }}
+
 --------------------------------------------------------------------------------
 Column error templates
 --------------------------------------------------------------------------------
@@ -430,12 +583,80 @@ Column error templates
 	` with the "{{R .Field.Name}}" field's type "{{R .Field.Type}}".
 {{ end }}
 
+{{ define "` + errColumnNullableRead.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "NULLable column not readable into field."}}
+    The "{{R .Col.Name}}" column is {{Wb "NULLable"}} but the "{{R .Field.Name}}" field's type "{{R .Field.Type}}" cannot hold {{Wb "NULL"}}.
+    - with {{W "strict_nulls"}} enabled, a field that reads a {{Wb "NULLable"}} column MUST be a pointer, ` +
+	`implement the {{Ci "sql.Scanner"}} interface, or have the "{{W "coalesce"}}" or "{{W "nn"}}" option in its tag.
+{{ end }}
+
 {{ define "` + errColumnTextSearchType.name() + `" -}}
 {{Wb .Field.File.NameAndLine}}: {{Y "Bad column type for text search."}}
     The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is of type "{{R .Col.Type.GetNameFmt}}".
     - a column referenced by a {{W .Field.TypeShort}} directive MUST be of type {{Ci "tsvector"}} to support {{wu "full text search"}}.
 {{ end }}
 
+{{ define "` + errColumnSoftDeleteNotNull.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad column for soft delete."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" has a {{Wb "NOT NULL"}} constraint.
+    - a column used to mark records as {{wu "soft-deleted"}} MUST be nullable, the records whose column is {{Wb "NULL"}} are the ones that are not deleted.
+{{ end }}
+
+{{ define "` + errColumnAutoNowType.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad column type for autonow."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is of type "{{R .Col.Type.GetNameFmt}}".
+    - a column referenced together with the "{{W "autonow"}}" option in the tag MUST be of type {{Ci "timestamptz"}}, {{Ci "timestamp"}}, or {{Ci "date"}}.
+{{ end }}
+
+{{ define "` + errColumnGeneratedForce.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad column for force."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is a {{Wb "GENERATED"}} column.
+    - a generated column's value is computed by the database and cannot be written to, ` +
+	`therefore it MUST NOT be referenced by a {{W .Field.TypeShort}} directive.
+{{ end }}
+
+{{ define "` + errColumnIdentityOverride.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad write to identity column."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is a {{Wb "GENERATED ALWAYS AS IDENTITY"}} column.
+    - to {{wu "insert"}} a value into such a column the query struct MUST have a {{Ci "gosql.Override"}} directive ` +
+	`with the "{{W "system"}}" option, or the field MUST be excluded from writes, or set to {{Wb "DEFAULT"}}.
+{{ end }}
+
+{{ define "` + errColumnIdentityUpdate.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad write to identity column."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is a {{Wb "GENERATED ALWAYS AS IDENTITY"}} column.
+    - such a column can only be {{wu "updated"}} to {{Wb "DEFAULT"}}, the field MUST be excluded from writes, ` +
+	`or set to {{Wb "DEFAULT"}}.
+{{ end }}
+
+{{ define "` + errColumnEnumFieldType.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad field type for enum column."}}
+    The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" is of the enum type "{{R .Col.Type.GetNameFmt}}".
+    - with {{W "strict_enums"}} enabled, a field mapped to an enum column MUST be of the Go type generated for that enum ` +
+	`by {{Ci "gosql enums"}}, or of the Go type declared for it in the {{W "enum_types"}} config.
+{{ end }}
+
+{{ define "` + errColumnCompositeAttrUnknown.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Unknown composite type attribute."}}
+    The field "{{R .Attr.Field}}" of "{{R .Field.Definition}}" is mapped to the attribute "{{R .Attr.Name}}" ` +
+	`but the composite type "{{R .Col.Type.GetNameFmt}}" of the column "{{R .Col.IdRef}}" has no such attribute.
+{{ end }}
+
+{{ define "` + errColumnCompositeAttrType.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad field type for composite type attribute."}}
+    The field "{{R .Attr.Field}}" of "{{R .Field.Definition}}" cannot be mapped to the attribute "{{R .Attr.Name}}" ` +
+	`of type "{{R .Attr.Type.GetNameFmt}}" of the composite type "{{R .Col.Type.GetNameFmt}}".
+    - a composite attribute's field MUST be of a type that can be converted to and from the attribute's type ` +
+	`without a {{Ci "pgsql"}} converter, or it MUST implement the {{Ci "sql.Scanner"}} and {{Ci "driver.Valuer"}} interfaces.
+{{ end }}
+
+{{ define "` + errColumnExprInvalid.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad field expression."}}
+    The SQL expression in "{{R .Field.Definition}}" could not be evaluated to determine its result type.
+    - an expression field's expression MUST be a valid SQL value expression that references only the relations loaded by the query.
+    - original error message: "{{Wb .Err.Error}}"
+{{ end }}
+
 {{ define "` + errColumnDefaultUnset.name() + `" -}}
 {{Wb .Field.File.NameAndLine}}: {{Y "Column DEFAULT not set."}}
     The column "{{R .Col.IdRef}}" referenced in "{{R .Field.Definition}}" has no {{Wb "DEFAULT"}} constraint.
@@ -549,6 +770,55 @@ Procedure error templates
     exists in the database "{{W .DB.Name}}" (search_path: {{Wb .DB.SearchPath}}).
 {{ end }}
 
+--------------------------------------------------------------------------------
+Operator error templates
+--------------------------------------------------------------------------------
+
+{{ define "` + errOperatorUnknown.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Operator not found."}}
+    {{if .RHSCol.Id.Name -}}
+    The operator "{{R .Col.Type.GetNameFmt .Oper .RHSCol.Type.GetNameFmt}}" referenced in "{{R .Field.Definition}}" does not exist.
+    - column "{{R .Col.IdRef}}" is of type "{{R .Col.Type.GetNameFmt}}".
+    - column "{{R .RHSCol.IdRef}}" is of type "{{R .RHSCol.Type.GetNameFmt}}".
+    {{else if .RHSLit.Expr -}}
+    The operator "{{R .Col.Type.GetNameFmt .Oper .RHSLit.Type.GetNameFmt}}" referenced in "{{R .Field.Definition}}" does not exist.
+    - column "{{R .Col.IdRef}}" is of type "{{R .Col.Type.GetNameFmt}}".
+    - expression "{{R .RHSLit.Expr}}" is of type "{{R .RHSLit.Type.GetNameFmt}}".
+    {{else -}}
+    The operator "{{R .Col.Type.GetNameFmt .Oper .Field.TypeShort}}" referenced by "{{R .Field.Definition}}" does not exist.
+    - column "{{R .Col.IdRef}}" is of type "{{R .Col.Type.GetNameFmt}}".
+    - field "{{R .Field.Name}}" is of type "{{R .Field.TypeShort}}".
+    {{end -}}
+    - no operator named "{{W .Oper}}" accepts these operand types, as is or implicitly cast, ` +
+	`in the database "{{W .DB.Name}}" (search_path: {{Wb .DB.SearchPath}}).
+{{ end }}
+
+{{ define "` + errOperatorResultBool.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Operator result not boolean."}}
+    The operator "{{R .Oper}}" referenced in "{{R .Field.Definition}}" does not return a boolean value.
+    - column "{{R .Col.IdRef}}" is of type "{{R .Col.Type.GetNameFmt}}".
+    - a where or join condition requires an operator whose result is of type "{{W "boolean"}}".
+{{ end }}
+
+--------------------------------------------------------------------------------
+Join error templates
+--------------------------------------------------------------------------------
+
+{{ define "` + errJoinForeignKeyNone.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "No foreign key to join on."}}
+    The join condition of "{{R .Field.Definition}}" was omitted but there is no foreign key ` +
+	`that links the relation "{{R .Rel.Ref}}" to any of the relations joined before it.
+    - add the join condition to the directive's tag, e.g. {{raw "sql:\"<relation>:<alias>,<alias>.<column> = <column_ref>\""}}.
+{{ end }}
+
+{{ define "` + errJoinForeignKeyAmbiguous.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Ambiguous foreign key to join on."}}
+    The join condition of "{{R .Field.Definition}}" was omitted but there is more than one foreign key ` +
+	`that links the relation "{{R .Rel.Ref}}" to the relations joined before it.
+    - candidate constraints: {{range $i, $c := .Cons}}{{if $i}}, {{end}}{{W $c}}{{end}}.
+    - add the join condition to the directive's tag to choose one of the candidates.
+{{ end }}
+
 --------------------------------------------------------------------------------
 On Conflict error templates
 --------------------------------------------------------------------------------
@@ -589,6 +859,65 @@ On Conflict error templates
     - directive "{{W .Field.TypeShort}}" requires a constraint of type: "{{W "unique"}}", or "{{W "primary key"}}".
 {{ end }}
 
+{{ define "` + errOnConflictForeignTable.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Bad conflict clause."}}
+    The "{{R .Field.Definition}}" field declares an {{Wb "ON CONFLICT"}} clause for the foreign table "{{R .Rel.Name}}".
+    - the {{Wb "ON CONFLICT"}} clause is not supported for foreign tables.
+{{ end }}
+
+--------------------------------------------------------------------------------
+Privilege error templates
+--------------------------------------------------------------------------------
+
+{{ define "` + errPrivilegeGet.name() + `" -}}
+{{Wb .Target.File.NameAndLine}}: {{Y "Failed to check privileges."}}
+    An error occurred during the checking of the privileges of the runtime role "{{R .Priv.Role}}" for "{{R .Target.Name}}".
+    - original error message: "{{Wb .Err.Error}}"
+    - make sure that the role exists in the "{{R .DB.Name}}" database's cluster.
+{{ end }}
+
+{{ define "` + errPrivilegeMissing.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Missing privilege."}}
+    The runtime role "{{R .Priv.Role}}" does not have the {{Wb .Priv.Name}} privilege on ` +
+	`{{if .Col.Id.Name}}the column "{{R .Col.Id.Name}}" of {{end}}the relation "{{R .Rel.Ref}}".
+    - the privilege is required by "{{R .Field.Definition}}" of the {{W .Target.Name}} type.
+{{ end }}
+
+--------------------------------------------------------------------------------
+Query verification error templates
+--------------------------------------------------------------------------------
+
+{{ define "` + errQueryPrepare.name() + `" -}}
+{{if .Field.Name}}{{Wb .Field.File.NameAndLine}}{{else}}{{Wb .Target.File.NameAndLine}}{{end}}: {{Y "Query rejected by the server."}}
+    The query generated for "{{R .Target.Name}}" could not be prepared by the server.
+    {{if .Field.Name -}}
+    - the error was reported at the parameter of the field "{{R .Field.Definition}}".
+    {{end -}}
+    - server error message: "{{Wb .Err.Error}}"
+    - query: {{W .Query}}
+    - database: {{W .DB.Name}} (dsn "{{W .DB.DSN}}")
+{{ end }}
+
+{{ define "` + errQueryParamType.name() + `" -}}
+{{Wb .Field.File.NameAndLine}}: {{Y "Query parameter not compatible with field."}}
+    The type "{{R .Param.Type.GetNameFmt}}" inferred by the server for the parameter "{{R .Param.Ref}}" ` +
+	`is not compatible with the "{{R .Field.Name}}" field's type "{{R .Field.Type}}".
+    - query: {{W .Query}}
+    - target type: {{W .Target.Name}} (source "{{W .Target.File.NameAndLine}}")
+{{ end }}
+
+--------------------------------------------------------------------------------
+Index advisor error templates
+--------------------------------------------------------------------------------
+
+{{ define "` + errQueryExplain.name() + `" -}}
+{{Wb .Target.File.NameAndLine}}: {{Y "Failed to explain query."}}
+    The plan of the query generated for "{{R .Target.Name}}" could not be obtained from the server.
+    - server error message: "{{Wb .Err.Error}}"
+    - query: {{W .Query}}
+    - database: {{W .DB.Name}} (dsn "{{W .DB.DSN}}")
+{{ end }}
+
 ` // `
 
 var error_templates = template.Must(template.New("t").Funcs(template.FuncMap{
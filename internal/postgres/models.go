@@ -0,0 +1,431 @@
+package postgres
+
+import (
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/postgres/oid"
+)
+
+// Model holds the information on a relation for which
+// the "gosql models" command generates a Go struct type.
+type Model struct {
+	// The identifier of the relation, qualified with the name of the
+	// relation's schema if the relation is not visible in the search_path.
+	Id analysis.RelIdent
+	// The relation.
+	Relation *Relation
+	// The fields of the model, one for each of the relation's columns.
+	Fields []*ModelField
+}
+
+// ModelField holds the information on a relation's column
+// for which the "gosql models" command generates a struct field.
+type ModelField struct {
+	// The column.
+	Column *Column
+	// The Go type of the field.
+	Type analysis.LiteralType
+	// Indicates whether or not the column can be NULL.
+	IsNULLable bool
+}
+
+// GoName returns the name of the Go struct type that is generated for the
+// model m by the "gosql models" command, e.g. "user_addresses" => "UserAddresses",
+// or, if singular is true, the name of the singular form of the relation's name,
+// e.g. "user_addresses" => "UserAddress".
+func (m *Model) GoName(singular bool) string {
+	name := m.Relation.Name
+	if singular {
+		name = singularize(name)
+	}
+	return modelIdent(name)
+}
+
+// SchemaGoName returns the Go name of the schema of the model's relation,
+// which is used to distinguish between the models of the relations that
+// have the same name, e.g. "audit" => "Audit".
+func (m *Model) SchemaGoName() string {
+	return modelIdent(m.Relation.Schema)
+}
+
+// GoName returns the name of the struct field that is generated for the
+// model field f by the "gosql models" command, e.g. "user_id" => "UserID".
+func (f *ModelField) GoName() string {
+	return modelIdent(f.Column.Name)
+}
+
+// IsNilable reports whether or not the field's type is a slice type, including
+// net.IP and net.HardwareAddr, a map, or an interface type, i.e. a type whose zero
+// value can be used to represent NULL.
+func (f *ModelField) IsNilable() bool {
+	s := string(f.Type)
+	return strings.HasPrefix(s, "[]") || strings.HasPrefix(s, "map[") ||
+		strings.HasPrefix(s, "interface{") || f.Type == analysis.LiteralHardwareAddr ||
+		f.Type == analysis.LiteralIP
+}
+
+// modelInitialisms is the set of the initialisms that are upper-cased
+// in their entirety when they make up a word of a model's Go name.
+var modelInitialisms = map[string]bool{
+	"API": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "UID": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// modelIdent returns the Go identifier of the given SQL identifier, like
+// goIdent, except that the initialisms are upper-cased, e.g. "user_id" => "UserID".
+// An identifier that does not start with a letter is prefixed with "X".
+func modelIdent(name string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if up := strings.ToUpper(w); modelInitialisms[up] {
+			b.WriteString(up)
+		} else {
+			b.WriteString(goIdent(w))
+		}
+	}
+
+	ident := b.String()
+	if r, _ := utf8.DecodeRuneInString(ident); !unicode.IsLetter(r) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// singularize returns the singular form of the last word of the given
+// SQL identifier, e.g. "user_addresses" => "user_address". The words
+// that are not recognized as plural are returned unchanged.
+func singularize(name string) string {
+	i := strings.LastIndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	head, word := name[:i+1], name[i+1:]
+	lower := strings.ToLower(word)
+
+	switch {
+	case len(word) <= 3:
+		// too short to tell, e.g. "bus", "gas"
+	case strings.HasSuffix(lower, "ies"):
+		y := "y"
+		if word[len(word)-1] == 'S' {
+			y = "Y"
+		}
+		word = word[:len(word)-3] + y
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"),
+		strings.HasSuffix(lower, "zzes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"),
+		strings.HasSuffix(lower, "is"):
+		// not plural, e.g. "access", "status", "analysis"
+	case strings.HasSuffix(lower, "s"):
+		word = word[:len(word)-1]
+	}
+	return head + word
+}
+
+// modelLiterals maps the types for which the compatibility table lists more
+// than one Go type to the Go type that is preferred for a model's field. The
+// types that are not in the map use the first of their compatible Go types,
+// other than string, []byte, and interface{}, that requires neither a valuer nor a scanner,
+// or else the first of those that do, or else string.
+var modelLiterals = map[oid.OID]analysis.LiteralType{
+	oid.Bit:           analysis.LiteralBool,
+	oid.BitArr:        analysis.LiteralBoolSlice,
+	oid.BPChar:        analysis.LiteralString,
+	oid.BPCharArr:     analysis.LiteralStringSlice,
+	oid.Char:          analysis.LiteralString,
+	oid.CharArr:       analysis.LiteralStringSlice,
+	oid.Bytea:         analysis.LiteralByteSlice,
+	oid.ByteaArr:      analysis.LiteralByteSliceSlice,
+	oid.Float4:        analysis.LiteralFloat32,
+	oid.Float4Arr:     analysis.LiteralFloat32Slice,
+	oid.Float8:        analysis.LiteralFloat64,
+	oid.Float8Arr:     analysis.LiteralFloat64Slice,
+	oid.Int2:          analysis.LiteralInt16,
+	oid.Int2Arr:       analysis.LiteralInt16Slice,
+	oid.Int2Vector:    analysis.LiteralInt16Slice,
+	oid.Int2VectorArr: analysis.LiteralInt16SliceSlice,
+	oid.Int4:          analysis.LiteralInt32,
+	oid.Int4Arr:       analysis.LiteralInt32Slice,
+	oid.Int4Range:     analysis.LiteralInt32Array2,
+	oid.Int4RangeArr:  analysis.LiteralInt32Array2Slice,
+	oid.Int8:          analysis.LiteralInt64,
+	oid.Int8Arr:       analysis.LiteralInt64Slice,
+	oid.Int8Range:     analysis.LiteralInt64Array2,
+	oid.Int8RangeArr:  analysis.LiteralInt64Array2Slice,
+	oid.JSON:          analysis.LiteralByteSlice,
+	oid.JSONB:         analysis.LiteralByteSlice,
+	oid.Numeric:       analysis.LiteralString,
+	oid.NumericArr:    analysis.LiteralStringSlice,
+	oid.NumRange:      analysis.LiteralFloat64Array2,
+	oid.NumRangeArr:   analysis.LiteralFloat64Array2Slice,
+	oid.TextArr:       analysis.LiteralStringSlice,
+	oid.TSQueryArr:    analysis.LiteralStringSlice,
+	oid.TSVector:      analysis.LiteralStringSlice,
+	oid.TSVectorArr:   analysis.LiteralStringSliceSlice,
+	oid.UUID:          analysis.LiteralString,
+	oid.UUIDArr:       analysis.LiteralStringSlice,
+	oid.VarCharArr:    analysis.LiteralStringSlice,
+	oid.XML:           analysis.LiteralString,
+}
+
+// Models returns a Model for each of the tables and views of the database that
+// match any of the given patterns, sorted by schema and name. A pattern is of
+// the form "[schema.]name", where both the schema and the name are matched
+// as by path.Match, a pattern without a schema matches only the relations
+// that are visible in the search_path. Partitions are not included.
+//
+// If no patterns are given, all the relations visible in the search_path are returned.
+func (db *DB) Models(patterns []string) ([]*Model, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+
+	const selectRelations = `SELECT
+		ns.nspname
+		, c.relname
+		, pg_table_is_visible(c.oid)
+	FROM pg_class c
+	JOIN pg_namespace ns ON ns.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'v', 'm', 'p', 'f')
+	AND NOT c.relispartition
+	AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+	AND ns.nspname NOT LIKE 'pg\_%'
+	ORDER BY ns.nspname, c.relname` //`
+	rows, err := db.Query(selectRelations)
+	if err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogRelationGet, Err: err})
+	}
+	defer rows.Close()
+
+	var ids []analysis.RelIdent
+	for rows.Next() {
+		var schema, name string
+		var visible bool
+		if err := rows.Scan(&schema, &name, &visible); err != nil {
+			return nil, db.dbError(dbError{Code: errCatalogRelationGet, Err: err})
+		}
+		if matchRelPatterns(patterns, schema, name, visible) {
+			id := analysis.RelIdent{Name: name}
+			if !visible {
+				id.Qualifier = schema
+			}
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogRelationGet, Err: err})
+	}
+
+	// loadRelation reports its errors through a checker
+	c := &checker{db: db, info: &analysis.Info{FileSet: token.NewFileSet()}}
+
+	models := make([]*Model, 0, len(ids))
+	for _, id := range ids {
+		m, err := loadModel(c, id)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+// Model returns the Model of the table or view identified by the
+// given "[schema.]name" identifier. An identifier without a schema
+// is resolved against the search_path.
+func (db *DB) Model(rel string) (*Model, error) {
+	id := analysis.RelIdent{Name: rel}
+	if i := strings.IndexByte(rel, '.'); i > -1 {
+		id.Qualifier, id.Name = rel[:i], rel[i+1:]
+	}
+
+	// loadRelation reports its errors through a checker
+	c := &checker{db: db, info: &analysis.Info{FileSet: token.NewFileSet()}}
+	return loadModel(c, id)
+}
+
+// loadModel loads the relation identified by id and returns its Model.
+func loadModel(c *checker, id analysis.RelIdent) (*Model, error) {
+	rel, err := loadRelation(c, c.db, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Model{Id: id, Relation: rel}
+	for _, col := range rel.Columns {
+		m.Fields = append(m.Fields, newModelField(c, col))
+	}
+	return m, nil
+}
+
+// newModelField returns the ModelField of the given column.
+func newModelField(c *checker, col *Column) *ModelField {
+	return &ModelField{Column: col, Type: modelLiteral(c, col), IsNULLable: !col.HasNotNull}
+}
+
+// PKeyFields returns the fields of the model's primary key columns,
+// or nil if the model's relation has no primary key.
+func (m *Model) PKeyFields() (fields []*ModelField) {
+	for _, f := range m.Fields {
+		if f.Column.IsPrimary {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// UpsertTarget holds the information on the unique constraint, or the
+// unique index, that is used as the conflict target of a scaffolded upsert.
+type UpsertTarget struct {
+	// The name of the constraint, or of the index.
+	Name string
+	// Indicates whether or not the target is a unique index
+	// that does not back a constraint.
+	IsIndex bool
+	// Indicates whether or not the target is the primary key.
+	IsPrimary bool
+	// The fields of the target's key columns.
+	Fields []*ModelField
+}
+
+// UpsertTarget returns the conflict target that a scaffolded upsert uses
+// for the model's relation, or nil if the relation has none. The non-deferrable
+// unique constraints are preferred over the unique indexes that have neither
+// a predicate nor an expression key, and those are preferred over the primary
+// key constraint. The first one, by name, of each kind is used.
+func (m *Model) UpsertTarget() *UpsertTarget {
+	var con, pkey *Constraint
+	names := make(map[string]bool, len(m.Relation.Constraints))
+	for _, c := range m.Relation.Constraints {
+		names[c.Name] = true
+		if c.IsDeferrable {
+			continue
+		}
+		if c.Type == ConstraintTypePKey {
+			pkey = c
+		} else if c.Type == ConstraintTypeUnique && (con == nil || c.Name < con.Name) {
+			con = c
+		}
+	}
+	if con != nil {
+		return &UpsertTarget{Name: con.Name, Fields: m.keyFields(con.Key)}
+	}
+
+	var idx *Index
+	for _, ind := range m.Relation.Indexes {
+		if !ind.IsUnique || ind.IsPrimary || !ind.IsImmediate || !ind.IsReady ||
+			len(ind.Predicate) > 0 || names[ind.Name] || hasExprKey(ind) {
+			continue
+		}
+		if idx == nil || ind.Name < idx.Name {
+			idx = ind
+		}
+	}
+	if idx != nil {
+		key := make([]int64, len(idx.Key))
+		for i, num := range idx.Key {
+			key[i] = int64(num)
+		}
+		return &UpsertTarget{Name: idx.Name, IsIndex: true, Fields: m.keyFields(key)}
+	}
+
+	if pkey != nil {
+		return &UpsertTarget{Name: pkey.Name, IsPrimary: true, Fields: m.keyFields(pkey.Key)}
+	}
+	return nil
+}
+
+// hasExprKey reports whether or not any of the index's key attributes is an expression.
+func hasExprKey(ind *Index) bool {
+	for _, num := range ind.Key {
+		if num == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFields returns the fields of the columns with the given numbers.
+func (m *Model) keyFields(key []int64) (fields []*ModelField) {
+	for _, num := range key {
+		for _, f := range m.Fields {
+			if int64(f.Column.Num) == num {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// matchRelPatterns reports whether or not the relation with the given
+// schema and name matches any of the given "[schema.]name" patterns.
+func matchRelPatterns(patterns []string, schema, name string, visible bool) bool {
+	for _, pattern := range patterns {
+		if i := strings.IndexByte(pattern, '.'); i > -1 {
+			if ok, _ := path.Match(pattern[:i], schema); !ok {
+				continue
+			}
+			pattern = pattern[i+1:]
+		} else if !visible {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// modelLiteral returns the Go type of the model field for the given column.
+// The type is chosen from the compatibility table's Go types of the column's
+// type, the columns of an enum type, or of an array of an enum type, are
+// represented by string, or []string, respectively.
+func modelLiteral(c *checker, col *Column) analysis.LiteralType {
+	typ := baseType(c, col.Type)
+	if typ.Type == TypeTypeEnum {
+		return analysis.LiteralString
+	}
+	if typ.Category == TypeCategoryArray {
+		if elem := c.db.catalog.Types[typ.Elem]; elem != nil && elem.Type == TypeTypeEnum {
+			return analysis.LiteralStringSlice
+		}
+	}
+
+	key := compkey{oid: typ.OID, typmod1: isLength1Type(c, col)}
+	entries := compatibility.oid2literal[key]
+	if lit, ok := modelLiterals[key.oid]; ok {
+		if _, ok := entries[lit]; ok {
+			return lit
+		}
+	}
+
+	var lits []analysis.LiteralType
+	for lit := range entries {
+		if lit != analysis.LiteralString && lit != analysis.LiteralByteSlice && lit != analysis.LiteralEmptyInterface {
+			lits = append(lits, lit)
+		}
+	}
+	if len(lits) == 0 {
+		return analysis.LiteralString
+	}
+	sort.Slice(lits, func(i, j int) bool {
+		ci, cj := entries[lits[i]], entries[lits[j]]
+		if ni, nj := ci != (compentry{}), cj != (compentry{}); ni != nj {
+			return !ni
+		}
+		return lits[i] < lits[j]
+	})
+	return lits[0]
+}
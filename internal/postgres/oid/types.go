@@ -3,99 +3,108 @@ package oid
 type OID uint32
 
 const (
-	Any            OID = 2276
-	Bit            OID = 1560
-	BitArr         OID = 1561
-	Bool           OID = 16
-	BoolArr        OID = 1000
-	Box            OID = 603
-	BoxArr         OID = 1020
-	BPChar         OID = 1042
-	BPCharArr      OID = 1014
-	Bytea          OID = 17
-	ByteaArr       OID = 1001
-	Char           OID = 18
-	CharArr        OID = 1002
-	CIDR           OID = 650
-	CIDRArr        OID = 651
-	Circle         OID = 718
-	CircleArr      OID = 719
-	Date           OID = 1082
-	DateArr        OID = 1182
-	DateRange      OID = 3912
-	DateRangeArr   OID = 3913
-	Float4         OID = 700
-	Float4Arr      OID = 1021
-	Float8         OID = 701
-	Float8Arr      OID = 1022
-	Inet           OID = 869
-	InetArr        OID = 1041
-	Int2           OID = 21
-	Int2Arr        OID = 1005
-	Int2Vector     OID = 22
-	Int2VectorArr  OID = 1006
-	Int4           OID = 23
-	Int4Arr        OID = 1007
-	Int4Range      OID = 3904
-	Int4RangeArr   OID = 3905
-	Int8           OID = 20
-	Int8Arr        OID = 1016
-	Int8Range      OID = 3926
-	Int8RangeArr   OID = 3927
-	Interval       OID = 1186
-	IntervalArr    OID = 1187
-	JSON           OID = 114
-	JSONArr        OID = 199
-	JSONB          OID = 3802
-	JSONBArr       OID = 3807
-	Line           OID = 628
-	LineArr        OID = 629
-	LSeg           OID = 601
-	LSegArr        OID = 1018
-	MACAddr        OID = 829
-	MACAddrArr     OID = 1040
-	MACAddr8       OID = 774
-	MACAddr8Arr    OID = 775
-	Money          OID = 790
-	MoneyArr       OID = 791
-	Numeric        OID = 1700
-	NumericArr     OID = 1231
-	NumRange       OID = 3906
-	NumRangeArr    OID = 3907
-	OIDVector      OID = 30
-	Path           OID = 602
-	PathArr        OID = 1019
-	Point          OID = 600
-	PointArr       OID = 1017
-	Polygon        OID = 604
-	PolygonArr     OID = 1027
-	Text           OID = 25
-	TextArr        OID = 1009
-	Time           OID = 1083
-	TimeArr        OID = 1183
-	Timestamp      OID = 1114
-	TimestampArr   OID = 1115
-	Timestamptz    OID = 1184
-	TimestamptzArr OID = 1185
-	Timetz         OID = 1266
-	TimetzArr      OID = 1270
-	TSQuery        OID = 3615
-	TSQueryArr     OID = 3645
-	TsRange        OID = 3908
-	TsRangeArr     OID = 3909
-	TsTzRange      OID = 3910
-	TsTzRangeArr   OID = 3911
-	TSVector       OID = 3614
-	TSVectorArr    OID = 3643
-	UUID           OID = 2950
-	UUIDArr        OID = 2951
-	Unknown        OID = 705
-	VarBit         OID = 1562
-	VarBitArr      OID = 1563
-	VarChar        OID = 1043
-	VarCharArr     OID = 1015
-	XML            OID = 142
-	XMLArr         OID = 143
+	Any                   OID = 2276
+	AnyArray              OID = 2277
+	AnyCompatible         OID = 5077
+	AnyCompatibleArray    OID = 5078
+	AnyCompatibleNonArray OID = 5079
+	AnyCompatibleRange    OID = 5080
+	AnyElement            OID = 2283
+	AnyEnum               OID = 3500
+	AnyNonArray           OID = 2776
+	AnyRange              OID = 3831
+	Bit                   OID = 1560
+	BitArr                OID = 1561
+	Bool                  OID = 16
+	BoolArr               OID = 1000
+	Box                   OID = 603
+	BoxArr                OID = 1020
+	BPChar                OID = 1042
+	BPCharArr             OID = 1014
+	Bytea                 OID = 17
+	ByteaArr              OID = 1001
+	Char                  OID = 18
+	CharArr               OID = 1002
+	CIDR                  OID = 650
+	CIDRArr               OID = 651
+	Circle                OID = 718
+	CircleArr             OID = 719
+	Date                  OID = 1082
+	DateArr               OID = 1182
+	DateRange             OID = 3912
+	DateRangeArr          OID = 3913
+	Float4                OID = 700
+	Float4Arr             OID = 1021
+	Float8                OID = 701
+	Float8Arr             OID = 1022
+	Inet                  OID = 869
+	InetArr               OID = 1041
+	Int2                  OID = 21
+	Int2Arr               OID = 1005
+	Int2Vector            OID = 22
+	Int2VectorArr         OID = 1006
+	Int4                  OID = 23
+	Int4Arr               OID = 1007
+	Int4Range             OID = 3904
+	Int4RangeArr          OID = 3905
+	Int8                  OID = 20
+	Int8Arr               OID = 1016
+	Int8Range             OID = 3926
+	Int8RangeArr          OID = 3927
+	Interval              OID = 1186
+	IntervalArr           OID = 1187
+	JSON                  OID = 114
+	JSONArr               OID = 199
+	JSONB                 OID = 3802
+	JSONBArr              OID = 3807
+	Line                  OID = 628
+	LineArr               OID = 629
+	LSeg                  OID = 601
+	LSegArr               OID = 1018
+	MACAddr               OID = 829
+	MACAddrArr            OID = 1040
+	MACAddr8              OID = 774
+	MACAddr8Arr           OID = 775
+	Money                 OID = 790
+	MoneyArr              OID = 791
+	Numeric               OID = 1700
+	NumericArr            OID = 1231
+	NumRange              OID = 3906
+	NumRangeArr           OID = 3907
+	OIDVector             OID = 30
+	Path                  OID = 602
+	PathArr               OID = 1019
+	Point                 OID = 600
+	PointArr              OID = 1017
+	Polygon               OID = 604
+	PolygonArr            OID = 1027
+	Text                  OID = 25
+	TextArr               OID = 1009
+	Time                  OID = 1083
+	TimeArr               OID = 1183
+	Timestamp             OID = 1114
+	TimestampArr          OID = 1115
+	Timestamptz           OID = 1184
+	TimestamptzArr        OID = 1185
+	Timetz                OID = 1266
+	TimetzArr             OID = 1270
+	TSQuery               OID = 3615
+	TSQueryArr            OID = 3645
+	TsRange               OID = 3908
+	TsRangeArr            OID = 3909
+	TsTzRange             OID = 3910
+	TsTzRangeArr          OID = 3911
+	TSVector              OID = 3614
+	TSVectorArr           OID = 3643
+	UUID                  OID = 2950
+	UUIDArr               OID = 2951
+	Unknown               OID = 705
+	VarBit                OID = 1562
+	VarBitArr             OID = 1563
+	VarChar               OID = 1043
+	VarCharArr            OID = 1015
+	XML                   OID = 142
+	XMLArr                OID = 143
 
 	// HStore    OID = 9999
 	// HStoreArr OID = 9998
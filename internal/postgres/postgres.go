@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/config"
 	"github.com/frk/gosql/internal/postgres/oid"
 
 	"github.com/lib/pq"
@@ -23,8 +25,9 @@ var _ = log.Println
 // DB handles the connection pool to the target postgres database
 // and holds additional information about that database.
 //
-// DB is NOT safe for concurrent use, an instance of DB is intended to
-// be reused by separate runs of the type checker, just not concurrently.
+// DB is safe for concurrent use by multiple goroutines, an instance of DB
+// is intended to be shared by concurrent runs of the type checker. The DB's
+// Options must not be modified once the DB is in use.
 type DB struct {
 	// The underlying *sql.DB pool handle.
 	*sql.DB
@@ -41,11 +44,52 @@ type DB struct {
 	version int
 	// The catalog for the target database.
 	catalog *Catalog
+	// The directory of the catalog's cache file, or empty if not cached.
+	cacheDir string
+	// The options that affect the strictness of the type checker.
+	Options CheckOptions
+	// The name of the database as configured by the "databases" config
+	// option, or empty for the default database. (intended mainly for
+	// error reporting)
+	ConfigName string
+}
+
+// CheckOptions holds the settings that affect the strictness of the type checker.
+type CheckOptions struct {
+	// If set, fields mapped to columns of an enum type, or of an array of
+	// an enum type, MUST be of the Go type that the "gosql enums" command
+	// generates for that enum, or of the Go type declared for it in EnumTypes.
+	StrictEnums bool
+	// If set, fields that read NULLable columns MUST be of a type that can
+	// hold NULL, or they MUST have the "coalesce" or the "nn" tag option.
+	StrictNulls bool
+	// EnumTypes maps the names of enum types to the Go types declared for them.
+	EnumTypes map[string]config.ObjectIdent
+	// EnumsPkgPath is the import path of the package of the Go types that
+	// are generated by the "gosql enums" command.
+	EnumsPkgPath string
+	// If set, the role MUST have the privileges on the columns and relations
+	// referenced by a query that are necessary for the query's execution.
+	RuntimeRole string
+	// If set, the checker produces advice on the columns that a query
+	// filters, joins, or sorts by and that are not covered by an index.
+	AdviseIndexes bool
+	// DefaultSchemas maps Go packages and input files to the schemas against
+	// which the unqualified relation identifiers of their types are resolved.
+	DefaultSchemas []config.DefaultSchema
 }
 
 // Open opens a new connection pool to the dsn specified postgres
 // database and loads the catalog information.
 func Open(dsn string) (db *DB, err error) {
+	return OpenCached(dsn, "")
+}
+
+// OpenCached is like Open but, if cacheDir is not empty, it first tries to
+// read the catalog information from the cache file in cacheDir, falling back
+// to loading it from the database if the cached catalog is missing or stale.
+// The WriteCache method can then be used to update the cache file.
+func OpenCached(dsn string, cacheDir string) (db *DB, err error) {
 	conn, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, &dbError{Code: errDatabaseOpen, DB: dbInfo{DSN: dsn}, Err: err}
@@ -60,7 +104,7 @@ func Open(dsn string) (db *DB, err error) {
 		return nil, &dbError{Code: errDatabaseOpen, DB: dbInfo{DSN: dsn}, Err: err}
 	}
 
-	db = &DB{DB: conn, dsn: dsn}
+	db = &DB{DB: conn, dsn: dsn, cacheDir: cacheDir}
 	if err := db.QueryRow(`SELECT current_database(), current_user`).Scan(&db.name, &db.user); err != nil {
 		return nil, &dbError{Code: errDatabaseInit, DB: dbInfo{DSN: dsn}, Err: err}
 	}
@@ -89,9 +133,25 @@ func (db *DB) dbError(e dbError) *dbError {
 	e.DB.Name = db.name
 	e.DB.User = db.user
 	e.DB.SearchPath = db.searchpath
+	e.DB.ConfigName = db.ConfigName
 	return &e
 }
 
+// Enums returns the enum types of the database's catalog that are
+// visible in the current search path, sorted by name.
+func (db *DB) Enums() []*Type {
+	var enums []*Type
+	for _, typ := range db.catalog.Types {
+		if typ.Type == TypeTypeEnum {
+			enums = append(enums, typ)
+		}
+	}
+	sort.Slice(enums, func(i, j int) bool {
+		return enums[i].Name < enums[j].Name
+	})
+	return enums
+}
+
 // checker maintains the state of the type checker.
 type checker struct {
 	db   *DB
@@ -101,6 +161,8 @@ type checker struct {
 	force *analysis.ForceDirective
 	// TODO
 	optional *analysis.OptionalDirective
+	// The default schema of the target's package, or empty.
+	schema string
 	// The identifier of the target relation.
 	rid analysis.RelIdent
 	// The target relation.
@@ -116,7 +178,8 @@ type checker struct {
 }
 
 func (c *checker) dbError(e dbError, ptr analysis.FieldPtr) *dbError {
-	e.DB = dbInfo{DSN: c.db.dsn, Name: c.db.name, User: c.db.user, SearchPath: c.db.searchpath}
+	e.DB = dbInfo{DSN: c.db.dsn, Name: c.db.name, User: c.db.user, SearchPath: c.db.searchpath,
+		ConfigName: c.db.ConfigName}
 
 	tpos := c.info.FileSet.Position(c.info.TypeNamePos)
 	e.Target.Pkg = c.info.PkgPath
@@ -174,7 +237,19 @@ type TargetInfo struct {
 	PKeys    []*FieldWrite
 	Joins    [][]TableJoinConditional
 	Where    []WhereConditional
+	Orders   []*FieldOrder
 	Conflict *ConflictInfo
+	// The schema-qualified identifiers of the relations whose identifiers
+	// were resolved against the default schema, keyed by the original ones.
+	RelIdents map[analysis.RelIdent]analysis.RelIdent
+	// The join conditions of the child relations of the
+	// target relation's "many" fields, keyed by the field.
+	ManyJoins map[*analysis.FieldInfo][]TableJoinConditional
+	// The conditional that excludes soft-deleted records
+	// of the target relation, or nil.
+	SoftDelete *ColumnConditional
+	// The advice produced by the index advisor, if enabled.
+	Advice []*Advice
 }
 
 // Check type-checks the given TargetStruct against the connected-to postgres database.
@@ -184,6 +259,7 @@ func Check(db *DB, ts analysis.TargetStruct, info *analysis.Info) (_ *TargetInfo
 	c.res = new(TargetInfo)
 	c.res.Struct = ts
 	c.res.Info = info
+	c.schema = defaultSchema(db, info)
 
 	switch s := ts.(type) {
 	case *analysis.FilterStruct:
@@ -194,6 +270,9 @@ func Check(db *DB, ts analysis.TargetStruct, info *analysis.Info) (_ *TargetInfo
 		if err := typeCheckFilterStruct(c, s); err != nil {
 			return nil, err
 		}
+		if err := typeCheckFilterPrivileges(c, s); err != nil {
+			return nil, err
+		}
 	case *analysis.QueryStruct:
 		if err := loadTargetRelation(c, s.Rel); err != nil {
 			return nil, err
@@ -203,6 +282,10 @@ func Check(db *DB, ts analysis.TargetStruct, info *analysis.Info) (_ *TargetInfo
 			return nil, err
 		}
 	}
+
+	if db.Options.AdviseIndexes {
+		adviseIndexes(c)
+	}
 	return c.res, nil
 }
 
@@ -240,7 +323,7 @@ func typeCheckFilterTextSearchDirective(c *checker, fs *analysis.FilterStruct) e
 	if col := findRelColumn(c.rel, fs.TextSearch.Name); col == nil {
 		return c.dbError(dbError{Code: errColumnUnknown, Rel: relInfo{Relation: c.rel},
 			Col: colInfo{Id: fs.TextSearch.ColIdent}}, fs.TextSearch)
-	} else if col.Type.OID != oid.TSVector {
+	} else if baseType(c, col.Type).OID != oid.TSVector {
 		return c.dbError(dbError{Code: errColumnTextSearchType, Rel: relInfo{Relation: c.rel},
 			Col: colInfo{Id: fs.TextSearch.ColIdent, Column: col}}, fs.TextSearch)
 	}
@@ -252,6 +335,7 @@ func typeCheckQueryStruct(c *checker, qs *analysis.QueryStruct) error {
 	c.optional = qs.Optional
 
 	checks := []func(*checker, *analysis.QueryStruct) error{
+		typeCheckQueryRelKind,
 		typeCheckQueryJoinStruct,
 		typeCheckQueryForceDirective,
 		typeCheckQueryDefaultDirective,
@@ -262,7 +346,9 @@ func typeCheckQueryStruct(c *checker, qs *analysis.QueryStruct) error {
 		typeCheckQueryRelField,
 
 		typeCheckQueryWhereStruct,
+		typeCheckQuerySoftDelete,
 		typeCheckQueryOnConflictStruct,
+		typeCheckQueryPrivileges,
 	}
 	for i := 0; i < len(checks); i++ {
 		if err := checks[i](c, qs); err != nil {
@@ -291,11 +377,52 @@ func typeCheckQueryStruct(c *checker, qs *analysis.QueryStruct) error {
 	return nil
 }
 
+// typeCheckQueryRelKind checks that the kind of the target relation
+// supports the query's command.
+//
+// CHECKLIST:
+//
+//	✅ The target relation of an INSERT, UPDATE, or DELETE query MUST NOT
+//	   be a materialized view.
+//	✅ If the target relation is a foreign table then its foreign-data wrapper
+//	   MUST support the query's command, and the query MUST NOT have an
+//	   ON CONFLICT clause.
+func typeCheckQueryRelKind(c *checker, qs *analysis.QueryStruct) error {
+	var event RelEvents
+	switch qs.Kind {
+	case analysis.QueryKindInsert:
+		event = RelEventInsert
+	case analysis.QueryKindUpdate:
+		event = RelEventUpdate
+	case analysis.QueryKindDelete:
+		event = RelEventDelete
+	default:
+		return nil
+	}
+
+	switch c.rel.RelKind {
+	case RelKindMaterializedView:
+		return c.dbError(dbError{Code: errRelationMaterializedViewWrite,
+			Rel: relInfo{Id: c.rid, Relation: c.rel}}, qs.Rel)
+	case RelKindForeignTable:
+		if c.rel.Events&event == 0 {
+			return c.dbError(dbError{Code: errRelationForeignTableCommand,
+				Rel: relInfo{Id: c.rid, Relation: c.rel}}, qs.Rel)
+		}
+		if qs.OnConflict != nil {
+			return c.dbError(dbError{Code: errOnConflictForeignTable,
+				Rel: relInfo{Id: c.rid, Relation: c.rel}}, qs.OnConflict)
+		}
+	}
+	return nil
+}
+
 // typeCheckQueryForceDirective checks the columns listed in the gosql.Force directive's tag.
 //
 // CHECKLIST:
 //
 //	✅ Each column MUST be present in one of the loaded relations.
+//	✅ Each column MUST NOT be a generated column.
 func typeCheckQueryForceDirective(c *checker, qs *analysis.QueryStruct) error {
 	if qs.Force == nil {
 		return nil
@@ -303,9 +430,14 @@ func typeCheckQueryForceDirective(c *checker, qs *analysis.QueryStruct) error {
 	c.force = qs.Force
 
 	for _, cid := range qs.Force.Items {
-		if _, ecode := findColumn(c, cid); ecode > 0 {
+		col, ecode := findColumn(c, cid)
+		if ecode > 0 {
 			return c.dbError(dbError{Code: ecode, Col: colInfo{Id: cid}}, qs.Force)
 		}
+		if col.IsGenerated {
+			return c.dbError(dbError{Code: errColumnGeneratedForce,
+				Rel: relInfo{Relation: col.Relation}, Col: colInfo{Id: cid, Column: col}}, qs.Force)
+		}
 	}
 	return nil
 }
@@ -340,15 +472,42 @@ func typeCheckQueryDefaultDirective(c *checker, qs *analysis.QueryStruct) error
 // CHECKLIST:
 //
 //	✅ Each column MUST be present in one of the loaded relations.
+//	✅ If an item has an operator, the pg_operator table MUST contain an entry with
+//	   the operator's name whose left operand accepts the column's type and whose
+//	   right operand type is compatible with the type of the item's param field.
 func typeCheckQueryOrderByDirective(c *checker, qs *analysis.QueryStruct) error {
 	if qs.OrderBy == nil {
 		return nil
 	}
 
 	for _, item := range qs.OrderBy.Items {
-		if _, ecode := findColumn(c, item.ColIdent); ecode > 0 {
+		col, ecode := findColumn(c, item.ColIdent)
+		if ecode > 0 {
 			return c.dbError(dbError{Code: ecode, Col: colInfo{Id: item.ColIdent}}, qs.OrderBy)
 		}
+		if len(item.Operator) == 0 {
+			continue
+		}
+
+		// see the note on the same conversion in typeCheckFieldConditional
+		ftyp := item.Param.Type
+		if !ftyp.Kind.IsBasic() && ftyp.IsValuer {
+			ftyp = analysis.TypeInfo{Kind: analysis.TypeKindString}
+		}
+
+		_, comp := findFieldOperator(c, item.Operator, baseType(c, col.Type), ftyp, false, 0)
+		if comp == nil {
+			return c.dbError(dbError{Code: errOperatorUnknown,
+				Col: colInfo{Id: item.ColIdent, Column: col}, Oper: item.Operator}, item.Param)
+		}
+
+		order := new(FieldOrder)
+		order.Field = item.Param
+		order.ColIdent = item.ColIdent
+		order.Column = col
+		order.Operator = item.Operator
+		order.Valuer = comp.valuer
+		c.res.Orders = append(c.res.Orders, order)
 	}
 	return nil
 }
@@ -444,6 +603,13 @@ func typeCheckQueryRelField(c *checker, qs *analysis.QueryStruct) error {
 		}
 	} else if qs.Kind == analysis.QueryKindInsert || qs.Kind == analysis.QueryKindUpdate {
 		for _, f := range qs.Rel.Type.Fields {
+			// The values of expression, window function, and child relation
+			// fields are produced by the query, they are never written, even
+			// if their alias happens to match the name of a column.
+			if len(f.Expr) > 0 || f.Window != nil || f.Many != nil {
+				continue
+			}
+
 			w, err := typeCheckFieldWrite(c, f)
 			if err != nil {
 				return err
@@ -461,29 +627,112 @@ func typeCheckQueryRelField(c *checker, qs *analysis.QueryStruct) error {
 				if w.Column.IsPrimary {
 					c.res.PKeys = append(c.res.PKeys, w)
 				}
-				switch {
-				case qs.Kind == analysis.QueryKindInsert && !skipFieldInsert(c, w):
-					c.res.Writes = append(c.res.Writes, w)
-				case qs.Kind == analysis.QueryKindUpdate && !skipFieldUpdate(c, w):
-					c.res.Writes = append(c.res.Writes, w)
+				// A generated column's value is computed by the
+				// database, so it is never written to.
+				if w.Column.IsGenerated {
+					continue
+				}
+				if f.AutoNow > 0 {
+					if err := typeCheckFieldAutoNow(c, qs, w); err != nil {
+						return err
+					}
+					continue
+				}
+				if qs.Kind == analysis.QueryKindInsert && skipFieldInsert(c, w) {
+					continue
+				}
+				if qs.Kind == analysis.QueryKindUpdate && skipFieldUpdate(c, w) {
+					continue
+				}
+				if err := typeCheckFieldIdentity(c, qs, w); err != nil {
+					return err
 				}
+				c.res.Writes = append(c.res.Writes, w)
 			}
 		}
 	}
 	return nil
 }
 
+// typeCheckFieldIdentity checks the given FieldWrite of a field whose
+// column is an identity column declared as GENERATED ALWAYS.
+//
+// CHECKLIST:
+//
+//	✅ If the field's value is not set to DEFAULT:
+//	  - An INSERT query MUST have the gosql.Override directive with the "system" option.
+//	  - An UPDATE query MUST NOT write to the column.
+func typeCheckFieldIdentity(c *checker, qs *analysis.QueryStruct, w *FieldWrite) error {
+	if w.Column.Identity != ColumnIdentityAlways {
+		return nil
+	}
+	if w.Field.UseDefault || (qs.Default != nil && qs.Default.Contains(w.Field.ColIdent)) {
+		return nil
+	}
+
+	if qs.Kind == analysis.QueryKindInsert {
+		if qs.Override != nil && qs.Override.Kind == analysis.OverridingSystem {
+			return nil
+		}
+		return c.dbError(dbError{Code: errColumnIdentityOverride, Col: colInfo{Id: w.Field.ColIdent,
+			Column: w.Column}, Rel: relInfo{Relation: c.rel}}, w.Field)
+	}
+	return c.dbError(dbError{Code: errColumnIdentityUpdate, Col: colInfo{Id: w.Field.ColIdent,
+		Column: w.Column}, Rel: relInfo{Relation: c.rel}}, w.Field)
+}
+
+// typeCheckFieldAutoNow checks the given FieldWrite of a field tagged with
+// the "autonow" option and, if the option applies to the query's kind, adds
+// it to the list of the query's writes.
+//
+// CHECKLIST:
+//
+//	✅ The column denoted by the field MUST be of type timestamptz, timestamp, or date.
+//	✅ The field MUST NOT be written if the option does not apply to the query's kind.
+func typeCheckFieldAutoNow(c *checker, qs *analysis.QueryStruct, w *FieldWrite) error {
+	switch w.Column.Type.OID {
+	case oid.Timestamptz, oid.Timestamp, oid.Date:
+		// ok
+	default:
+		return c.dbError(dbError{Code: errColumnAutoNowType, Col: colInfo{Id: w.Field.ColIdent,
+			Column: w.Column}, Rel: relInfo{Relation: c.rel}}, w.Field)
+	}
+
+	kind := analysis.AutoNowInsert
+	if qs.Kind == analysis.QueryKindUpdate {
+		kind = analysis.AutoNowUpdate
+	}
+	if w.Field.AutoNow.Has(kind) {
+		c.res.Writes = append(c.res.Writes, w)
+	}
+	return nil
+}
+
 // typeCheckQueryJoinStruct ...
+//
+// CHECKLIST:
+//
+//	✅ If a join directive's condition was omitted, there MUST be exactly one
+//	   foreign key that links the joined relation to one of the relations
+//	   joined before it, in either direction. A self-referencing foreign key
+//	   links the relation to itself in both directions and is ambiguous.
 func typeCheckQueryJoinStruct(c *checker, qs *analysis.QueryStruct) error {
 	if qs.Join == nil {
 		return nil
 	}
 
+	// the relations that a join condition can reference, in the order
+	// of joining; the target of an UPDATE ... FROM or DELETE ... USING
+	// query cannot be referenced by the joins of the FROM / USING list
+	var scope []analysis.RelIdent
 	if qs.Join.Relation != nil {
 		rid := qs.Join.Relation.RelIdent
 		if _, err := loadJoinRelation(c, rid, qs.Join.Relation); err != nil {
 			return err
 		}
+		scope = append(scope, rid)
+	} else {
+		scope = append(scope, c.rid)
 	}
 
 	for _, dir := range qs.Join.Directives {
@@ -492,28 +741,22 @@ func typeCheckQueryJoinStruct(c *checker, qs *analysis.QueryStruct) error {
 			return err
 		}
 
-		conds := make([]TableJoinConditional, len(dir.TagItems))
-		for i, v := range dir.TagItems {
-			switch item := v.(type) {
-			case *analysis.JoinBoolTagItem:
-				cond := new(Boolean)
-				cond.Value = item.Value
-				conds[i] = cond
-			case *analysis.JoinConditionTagItem:
-				cond := new(ColumnConditional)
-				cond.LHSColIdent = item.LHSColIdent
-				cond.RHSColIdent = item.RHSColIdent
-				cond.RHSLiteral = item.RHSLiteral
-				cond.Predicate = item.Predicate
-				cond.Quantifier = item.Quantifier
-				if err := typeCheckColumnConditional(c, cond, rel, dir.RelIdent, dir); err != nil {
-					return err
-				}
-				conds[i] = cond
-			default:
-				// NOTE(mkopriva): currently predicates other than
-				// analysis.JoinConditionTagItem are not supported
-				// as a join condition.
+		items := dir.TagItems
+		if len(items) == 0 && dir.JoinType != analysis.JoinTypeCross {
+			if items, err = inferJoinTagItems(c, rel, dir.RelIdent, scope, dir); err != nil {
+				return err
+			}
+		}
+		scope = append(scope, dir.RelIdent)
+
+		conds, err := typeCheckJoinTagItems(c, items, rel, dir.RelIdent, dir)
+		if err != nil {
+			return err
+		}
+
+		if dir.SoftDelete != nil {
+			if conds, err = appendJoinSoftDelete(c, conds, dir.SoftDelete, rel); err != nil {
+				return err
 			}
 		}
 
@@ -522,6 +765,140 @@ func typeCheckQueryJoinStruct(c *checker, qs *analysis.QueryStruct) error {
 	return nil
 }
 
+// typeCheckJoinTagItems type-checks the given join tag items of the relation
+// identified by rid and returns the resulting slice of TableJoinConditionals.
+func typeCheckJoinTagItems(c *checker, items []analysis.JoinTagItem, rel *Relation, rid analysis.RelIdent, ptr analysis.FieldPtr) ([]TableJoinConditional, error) {
+	conds := make([]TableJoinConditional, len(items))
+	for i, v := range items {
+		switch item := v.(type) {
+		case *analysis.JoinBoolTagItem:
+			cond := new(Boolean)
+			cond.Value = item.Value
+			conds[i] = cond
+		case *analysis.JoinConditionTagItem:
+			cond := new(ColumnConditional)
+			cond.LHSColIdent = item.LHSColIdent
+			cond.RHSColIdent = item.RHSColIdent
+			cond.RHSLiteral = item.RHSLiteral
+			cond.Predicate = item.Predicate
+			cond.Operator = item.Operator
+			cond.Quantifier = item.Quantifier
+			if err := typeCheckColumnConditional(c, cond, rel, rid, ptr); err != nil {
+				return nil, err
+			}
+			conds[i] = cond
+		default:
+			// NOTE(mkopriva): currently predicates other than
+			// analysis.JoinConditionTagItem are not supported
+			// as a join condition.
+		}
+	}
+	return conds, nil
+}
+
+// inferJoinTagItems returns the join condition of the relation identified by rid
+// as inferred from the single foreign key that links the relation to one of the
+// relations in scope. The foreign key may be declared by either of the linked
+// relations, the condition compares each of the key's constrained columns to the
+// corresponding referenced column. A self-referencing foreign key links the
+// relation to itself in both directions and is therefore always ambiguous.
+func inferJoinTagItems(c *checker, rel *Relation, rid analysis.RelIdent, scope []analysis.RelIdent, ptr analysis.FieldPtr) ([]analysis.JoinTagItem, error) {
+	type candidate struct {
+		con   *Constraint
+		items []analysis.JoinTagItem
+	}
+	var cands []*candidate
+
+	// joinItems returns the condition that compares the columns of
+	// the joined relation to those of the other relation in scope
+	joinItems := func(other *Relation, qual string, key, okey []int64) (items []analysis.JoinTagItem) {
+		for i := range key {
+			item := new(analysis.JoinConditionTagItem)
+			item.LHSColIdent.Name = findRelColumnByNum(rel, key[i]).Name
+			item.LHSColIdent.Qualifier = relIdentKey(rid)
+			item.RHSColIdent.Name = findRelColumnByNum(other, okey[i]).Name
+			item.RHSColIdent.Qualifier = qual
+			item.Predicate = analysis.IsEQ
+
+			if len(items) > 0 {
+				items = append(items, &analysis.JoinBoolTagItem{Value: analysis.BoolAnd})
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	for _, id := range scope {
+		other := c.relMap[relIdentKey(id)]
+		if other == nil {
+			continue
+		}
+		for _, con := range rel.Constraints {
+			if con.Type == ConstraintTypeFKey && con.FRelOID == other.OID {
+				items := joinItems(other, relIdentKey(id), con.Key, con.FKey)
+				cands = append(cands, &candidate{con: con, items: items})
+			}
+		}
+		for _, con := range other.Constraints {
+			if con.Type == ConstraintTypeFKey && con.FRelOID == rel.OID {
+				items := joinItems(other, relIdentKey(id), con.FKey, con.Key)
+				cands = append(cands, &candidate{con: con, items: items})
+			}
+		}
+	}
+
+	if len(cands) != 1 {
+		e := dbError{Code: errJoinForeignKeyNone, Rel: relInfo{Id: rid, Relation: rel}}
+		if len(cands) > 1 {
+			e.Code = errJoinForeignKeyAmbiguous
+			for _, cand := range cands {
+				e.Cons = append(e.Cons, cand.con.Name+" ("+joinItemsString(cand.items)+")")
+			}
+		}
+		return nil, c.dbError(e, ptr)
+	}
+	return cands[0].items, nil
+}
+
+// joinItemsString returns the text of the given inferred join condition,
+// e.g. "a.x = b.y AND a.z = b.w".
+func joinItemsString(items []analysis.JoinTagItem) string {
+	var conds []string
+	for _, v := range items {
+		if item, ok := v.(*analysis.JoinConditionTagItem); ok {
+			conds = append(conds, item.LHSColIdent.String()+" = "+item.RHSColIdent.String())
+		}
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// appendJoinSoftDelete type-checks the given soft-delete directive of the
+// joined relation and appends the resulting conditional to the given conds.
+func appendJoinSoftDelete(c *checker, conds []TableJoinConditional, sd *analysis.SoftDeleteDirective, rel *Relation) ([]TableJoinConditional, error) {
+	cond, err := typeCheckSoftDelete(c, sd, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	// The join condition needs to be parenthesized if it
+	// contains an OR operator, otherwise the soft-delete
+	// conditional would bind only to the last operand.
+	for _, v := range conds {
+		if b, ok := v.(*Boolean); ok && b.Value == analysis.BoolOr {
+			nested := new(NestedConditional)
+			for _, v := range conds {
+				nested.Conditionals = append(nested.Conditionals, v)
+			}
+			conds = []TableJoinConditional{nested}
+			break
+		}
+	}
+	if len(conds) > 0 {
+		conds = append(conds, &Boolean{Value: analysis.BoolAnd})
+	}
+	return append(conds, cond), nil
+}
+
 // typeCheckQueryWhereStruct type-checks individual items of the query's WhereStruct.
 func typeCheckQueryWhereStruct(c *checker, qs *analysis.QueryStruct) (err error) {
 	if qs.Where == nil {
@@ -540,6 +917,44 @@ func typeCheckQueryWhereStruct(c *checker, qs *analysis.QueryStruct) (err error)
 	return nil
 }
 
+// typeCheckQuerySoftDelete checks the column of the target relation's soft-delete policy.
+func typeCheckQuerySoftDelete(c *checker, qs *analysis.QueryStruct) error {
+	if qs.SoftDelete == nil {
+		return nil
+	}
+
+	cond, err := typeCheckSoftDelete(c, qs.SoftDelete, c.rel)
+	if err != nil {
+		return err
+	}
+	c.res.SoftDelete = cond
+	return nil
+}
+
+// typeCheckSoftDelete checks the column of the given SoftDeleteDirective and
+// returns the conditional that excludes the soft-deleted records of the relation.
+//
+// CHECKLIST:
+//
+//	✅ The column MUST be present in the given relation.
+//	✅ The column MUST NOT have a NOT NULL constraint.
+func typeCheckSoftDelete(c *checker, sd *analysis.SoftDeleteDirective, rel *Relation) (*ColumnConditional, error) {
+	col := findRelColumn(rel, sd.Name)
+	if col == nil {
+		return nil, c.dbError(dbError{Code: errColumnUnknown,
+			Rel: relInfo{Relation: rel}, Col: colInfo{Id: sd.ColIdent}}, sd)
+	} else if col.HasNotNull {
+		return nil, c.dbError(dbError{Code: errColumnSoftDeleteNotNull,
+			Rel: relInfo{Relation: rel}, Col: colInfo{Id: sd.ColIdent, Column: col}}, sd)
+	}
+
+	cond := new(ColumnConditional)
+	cond.LHSColIdent = sd.ColIdent
+	cond.LHSColumn = col
+	cond.Predicate = analysis.IsNull
+	return cond, nil
+}
+
 // typeCheckWhereItem type-checks the given WhereItem and returns the resulting WhereConditional.
 func typeCheckWhereItem(c *checker, item analysis.WhereItem) (WhereConditional, error) {
 	switch wi := item.(type) {
@@ -564,6 +979,7 @@ func typeCheckWhereItem(c *checker, item analysis.WhereItem) (WhereConditional,
 		column.RHSColIdent = wi.RHSColIdent
 		column.RHSLiteral = wi.RHSLiteral
 		column.Predicate = wi.Predicate
+		column.Operator = wi.Operator
 		column.Quantifier = wi.Quantifier
 		if err := typeCheckColumnConditional(c, column, nil, analysis.RelIdent{}, wi); err != nil {
 			return nil, err
@@ -575,8 +991,10 @@ func typeCheckWhereItem(c *checker, item analysis.WhereItem) (WhereConditional,
 		field.FieldType = wi.Type
 		field.ColIdent = wi.ColIdent
 		field.Predicate = wi.Predicate
+		field.Operator = wi.Operator
 		field.Quantifier = wi.Quantifier
 		field.FuncName = wi.FuncName
+		field.Field = wi
 
 		if field.Column == nil {
 			col, ecode := findColumn(c, field.ColIdent)
@@ -589,7 +1007,7 @@ func typeCheckWhereItem(c *checker, item analysis.WhereItem) (WhereConditional,
 		if ecode := typeCheckFieldConditional(c, field); ecode > 0 {
 			return nil, c.dbError(dbError{Code: ecode,
 				Col:  colInfo{Id: wi.ColIdent, Column: field.Column},
-				Pred: wi.Predicate, Quant: wi.Quantifier, Func: field.FuncName}, wi)
+				Pred: wi.Predicate, Oper: wi.Operator, Quant: wi.Quantifier, Func: field.FuncName}, wi)
 		}
 		return field, nil
 	case *analysis.WhereBetweenStruct:
@@ -672,6 +1090,7 @@ func typeCheckWhereBetweenStruct(c *checker, wb *analysis.WhereBetweenStruct) (*
 			field.ColIdent = wb.ColIdent
 			field.Column = col
 			field.Predicate = r.pred
+			field.Field = b
 			if ecode := typeCheckFieldConditional(c, field); ecode > 0 {
 				if ecode == errColumnFieldComparison {
 					return nil, c.dbError(dbError{Code: errBetweenFieldComparison,
@@ -715,11 +1134,17 @@ func typeCheckFieldConditional(c *checker, cond *FieldConditional) dbErrorCode {
 	if len(cond.FuncName) > 0 {
 		return typeCheckFieldConditionalWithFunc(c, cond)
 	}
+	if cond.Predicate.IsOperator() {
+		return typeCheckFieldConditionalWithOperator(c, cond)
+	}
+	if !isEnumFieldTypeAllowed(c, cond.FieldType, cond.Column) {
+		return errColumnEnumFieldType
+	}
 
 	var (
 		ftyp    = cond.FieldType
-		ctyp    = cond.Column.Type
-		coid    = cond.Column.Type.OID
+		ctyp    = baseType(c, cond.Column.Type)
+		coid    = ctyp.OID
 		oprname = predicateToOprname[cond.Predicate]
 	)
 
@@ -737,16 +1162,15 @@ func typeCheckFieldConditional(c *checker, cond *FieldConditional) dbErrorCode {
 	}
 
 	if cond.Predicate.IsArray() || cond.Quantifier > 0 {
-		// FIXME(mkopriva): this currently breaks for custom types like
-		// enums. Must figure out how to find the oid of a custom type...
-		id, ok := oid.TypeToArray[cond.Column.Type.OID]
+		// The array type is resolved from the catalog, rather than from
+		// a static table, so that custom types like enums are supported.
+		typ, ok := c.db.catalog.Types[ctyp.Array]
 		if ok {
-			ctyp, ok = c.db.catalog.Types[id]
+			ctyp = typ
 		}
 		if !ok && cond.Predicate.IsArray() {
 			return errPredicateOperandArray
 		} else if !ok && cond.Quantifier > 0 {
-			log.Println("EHHEHEHEHE")
 			return errPredicateOperandQuantifier
 		}
 	}
@@ -851,6 +1275,40 @@ func typeCheckFieldConditionalWithFunc(c *checker, cond *FieldConditional) dbErr
 	return errProcedureUnknown
 }
 
+// typeCheckFieldConditionalWithOperator checks a field conditional whose
+// predicate is a custom operator.
+//
+// CHECKLIST:
+//
+//	✅ The pg_operator table MUST contain an entry with the operator's name whose left
+//	   operand type is the column's type, or a type to which the column's type can be
+//	   implicitly cast, and whose right operand type is compatible with the field's type.
+//	   (Note that, if a quantifier was provided, then the field is expected to be a
+//	   slice/array of the operator's right operand type).
+//	✅ The result type of the operator MUST be boolean.
+func typeCheckFieldConditionalWithOperator(c *checker, cond *FieldConditional) dbErrorCode {
+	if !isEnumFieldTypeAllowed(c, cond.FieldType, cond.Column) {
+		return errColumnEnumFieldType
+	}
+
+	// see the note on the same conversion in typeCheckFieldConditional
+	ftyp := cond.FieldType
+	if !ftyp.Kind.IsBasic() && ftyp.IsValuer {
+		ftyp = analysis.TypeInfo{Kind: analysis.TypeKindString}
+	}
+
+	ltyp := baseType(c, cond.Column.Type)
+	qua := cond.Quantifier > 0
+	if _, comp := findFieldOperator(c, cond.Operator, ltyp, ftyp, qua, oid.Bool); comp != nil {
+		cond.Valuer = comp.valuer
+		return 0
+	}
+	if op, _ := findFieldOperator(c, cond.Operator, ltyp, ftyp, qua, 0); op != nil {
+		return errOperatorResultBool
+	}
+	return errOperatorUnknown
+}
+
 // typeCheckColumnConditional
 //
 // CHECKLIST:
@@ -933,7 +1391,13 @@ func typeCheckColumnConditional(c *checker, cond *ColumnConditional, rel *Relati
 		cond.RHSType = typ
 	}
 
-	if ecode := typeCheckComparison(c, cond.LHSColumn.Type, cond.RHSType, cond.Predicate, cond.Quantifier); ecode > 0 {
+	var ecode dbErrorCode
+	if cond.Predicate.IsOperator() {
+		ecode = typeCheckOperatorComparison(c, cond.LHSColumn.Type, cond.RHSType, cond.Operator, cond.Quantifier)
+	} else {
+		ecode = typeCheckComparison(c, cond.LHSColumn.Type, cond.RHSType, cond.Predicate, cond.Quantifier)
+	}
+	if ecode > 0 {
 		var rhsCol colInfo
 		var rhsLit exprInfo
 		if len(cond.RHSColIdent.Name) > 0 {
@@ -943,7 +1407,8 @@ func typeCheckColumnConditional(c *checker, cond *ColumnConditional, rel *Relati
 		}
 		return c.dbError(dbError{Code: ecode,
 			Col:    colInfo{Id: cond.LHSColIdent, Column: cond.LHSColumn},
-			RHSCol: rhsCol, RHSLit: rhsLit, Pred: cond.Predicate, Quant: cond.Quantifier}, ptr)
+			RHSCol: rhsCol, RHSLit: rhsLit, Pred: cond.Predicate, Oper: cond.Operator,
+			Quant: cond.Quantifier}, ptr)
 	}
 	return nil
 }
@@ -969,6 +1434,8 @@ func typeCheckComparison(c *checker, ltyp *Type, rtyp *Type, pred analysis.Predi
 		rtyp = c.db.catalog.Types[rtyp.Elem]
 	}
 
+	// domains are compared using the operators of their base types
+	ltyp, rtyp = baseType(c, ltyp), baseType(c, rtyp)
 	if ltyp.OID == rtyp.OID {
 		return 0
 	}
@@ -1006,6 +1473,34 @@ func typeCheckComparison(c *checker, ltyp *Type, rtyp *Type, pred analysis.Predi
 	return errColumnComparison
 }
 
+// typeCheckOperatorComparison checks whether or not a valid boolean expression
+// can be generated from the provided operand types and the named operator.
+//
+// CHECKLIST:
+//
+//	✅ If a quantifier was provided the check MUST use the RHS's element type instead of the RHS type.
+//	✅ The pg_operator table MUST contain an entry with the operator's name whose
+//	   operand types match the LHS and RHS types, either directly or after casting
+//	   them implicitly.
+//	✅ The result type of the operator MUST be boolean.
+func typeCheckOperatorComparison(c *checker, ltyp *Type, rtyp *Type, name string, qua analysis.Quantifier) dbErrorCode {
+	if qua > 0 {
+		if rtyp.Category != TypeCategoryArray {
+			return errPredicateOperandQuantifier
+		}
+		rtyp = c.db.catalog.Types[rtyp.Elem]
+	}
+
+	ltyp, rtyp = baseType(c, ltyp), baseType(c, rtyp)
+	op := findOperator(c, name, ltyp, rtyp)
+	if op == nil {
+		return errOperatorUnknown
+	} else if op.Result != oid.Bool {
+		return errOperatorResultBool
+	}
+	return 0
+}
+
 // typeCheckQueryOnConflictStruct
 //
 // CHECKLIST:
@@ -1021,6 +1516,10 @@ func typeCheckComparison(c *checker, ltyp *Type, rtyp *Type, pred analysis.Predi
 //	   table and it MUST be a unique constraint.
 //	✅ If a gosql.Update directive was used in the OnConflict block, the columns
 //	   listed in the directive's tag MUST be present in the target table.
+//
+// NOTE: the conflict target of a partitioned table is checked against the unique
+// indexes and constraints of the table itself, since PostgreSQL requires those to
+// include the partition key a matching conflict target always includes it as well.
 func typeCheckQueryOnConflictStruct(c *checker, qs *analysis.QueryStruct) error {
 	if qs.OnConflict == nil {
 		return nil
@@ -1127,6 +1626,213 @@ func typeCheckQueryOnConflictStruct(c *checker, qs *analysis.QueryStruct) error
 	return nil
 }
 
+// privilege represents a privilege on a relation, or on one of its columns,
+// that the runtime role needs to execute a query.
+type privilege struct {
+	// The relation on which the privilege is needed.
+	rel *Relation
+	// The column on which the privilege is needed, or nil
+	// if the privilege is needed on the relation itself.
+	col *Column
+	// The name of the privilege, e.g. SELECT.
+	name string
+	// The field that references the relation or column.
+	ptr analysis.FieldPtr
+}
+
+// privilegeList is a list of privileges needed by a query.
+type privilegeList []*privilege
+
+func (list *privilegeList) addRel(rel *Relation, name string, ptr analysis.FieldPtr) {
+	if rel != nil {
+		*list = append(*list, &privilege{rel: rel, name: name, ptr: ptr})
+	}
+}
+
+func (list *privilegeList) addCol(col *Column, name string, ptr analysis.FieldPtr) {
+	// columns without a relation are the results of expressions
+	if col != nil && col.Relation != nil {
+		*list = append(*list, &privilege{rel: col.Relation, col: col, name: name, ptr: ptr})
+	}
+}
+
+// addWhere adds the SELECT privilege on the columns referenced by the given conditionals.
+func (list *privilegeList) addWhere(conds []WhereConditional, ptr analysis.FieldPtr) {
+	for _, cond := range conds {
+		switch cond := cond.(type) {
+		case *FieldConditional:
+			list.addCol(cond.Column, "SELECT", cond.Field)
+		case *ColumnConditional:
+			list.addCol(cond.LHSColumn, "SELECT", ptr)
+			list.addCol(cond.RHSColumn, "SELECT", ptr)
+		case *BetweenConditional:
+			list.addCol(cond.Column, "SELECT", ptr)
+			for _, bound := range []RangeBound{cond.LowerBound, cond.UpperBound} {
+				if bc, ok := bound.(*ColumnConditional); ok {
+					list.addCol(bc.LHSColumn, "SELECT", ptr)
+				}
+			}
+		case *NestedConditional:
+			list.addWhere(cond.Conditionals, ptr)
+		}
+	}
+}
+
+// addJoin adds the SELECT privilege on the columns referenced by the given join conditionals.
+func (list *privilegeList) addJoin(conds []TableJoinConditional, ptr analysis.FieldPtr) {
+	for _, cond := range conds {
+		if cc, ok := cond.(*ColumnConditional); ok {
+			list.addCol(cc.LHSColumn, "SELECT", ptr)
+			list.addCol(cc.RHSColumn, "SELECT", ptr)
+		}
+	}
+}
+
+// typeCheckQueryPrivileges checks the privileges of the runtime role, if set.
+//
+// CHECKLIST:
+//
+//	✅ The runtime role MUST have the INSERT, UPDATE, or DELETE privilege
+//	   on the target relation and on the columns that the query writes.
+//	✅ The runtime role MUST have the SELECT privilege on the columns that
+//	   the query reads, including the RETURNING columns, and on the columns
+//	   referenced by the query's conditionals.
+//	✅ The runtime role MUST have the SELECT privilege on the joined relations
+//	   and on the columns referenced by the join conditions.
+func typeCheckQueryPrivileges(c *checker, qs *analysis.QueryStruct) error {
+	if len(c.db.Options.RuntimeRole) == 0 {
+		return nil
+	}
+
+	var list privilegeList
+	switch qs.Kind {
+	case analysis.QueryKindInsert:
+		list.addRel(c.rel, "INSERT", qs.Rel)
+		for _, w := range c.res.Writes {
+			list.addCol(w.Column, "INSERT", w.Field)
+		}
+	case analysis.QueryKindUpdate:
+		list.addRel(c.rel, "UPDATE", qs.Rel)
+		for _, w := range c.res.Writes {
+			list.addCol(w.Column, "UPDATE", w.Field)
+		}
+	case analysis.QueryKindDelete:
+		if c.res.SoftDelete != nil {
+			list.addCol(c.res.SoftDelete.LHSColumn, "UPDATE", qs.SoftDelete)
+		} else {
+			list.addRel(c.rel, "DELETE", qs.Rel)
+		}
+	default:
+		list.addRel(c.rel, "SELECT", qs.Rel)
+	}
+
+	for _, r := range c.res.Reads {
+		list.addCol(r.Column, "SELECT", r.Field)
+
+		if many := r.Field.Many; many != nil {
+			rel := c.relMap[relIdentKey(many.RelIdent)]
+			list.addRel(rel, "SELECT", r.Field)
+			list.addJoin(c.res.ManyJoins[r.Field], r.Field)
+			for _, cf := range many.Type.Fields {
+				if len(cf.Expr) == 0 {
+					list.addCol(findRelColumn(rel, cf.ColIdent.Name), "SELECT", cf)
+				}
+			}
+		}
+	}
+	for _, pk := range c.res.PKeys {
+		list.addCol(pk.Column, "SELECT", pk.Field)
+	}
+	if qs.Where != nil {
+		list.addWhere(c.res.Where, qs.Where)
+	}
+	if c.res.SoftDelete != nil {
+		list.addCol(c.res.SoftDelete.LHSColumn, "SELECT", qs.SoftDelete)
+	}
+
+	if qs.Join != nil {
+		if jr := qs.Join.Relation; jr != nil {
+			list.addRel(c.relMap[relIdentKey(jr.RelIdent)], "SELECT", jr)
+		}
+		for i, dir := range qs.Join.Directives {
+			list.addRel(c.relMap[relIdentKey(dir.RelIdent)], "SELECT", dir)
+			list.addJoin(c.res.Joins[i], dir)
+		}
+	}
+
+	if info := c.res.Conflict; info != nil && qs.OnConflict.Update != nil {
+		for _, col := range info.Update {
+			list.addCol(col, "UPDATE", qs.OnConflict.Update)
+		}
+	}
+	return checkPrivileges(c, list)
+}
+
+// typeCheckFilterPrivileges checks that the runtime role, if set, has
+// the SELECT privilege on the columns of the given filter struct.
+func typeCheckFilterPrivileges(c *checker, fs *analysis.FilterStruct) error {
+	if len(c.db.Options.RuntimeRole) == 0 {
+		return nil
+	}
+
+	var list privilegeList
+	list.addRel(c.rel, "SELECT", fs.Rel)
+	for _, f := range c.res.Filters {
+		list.addCol(f.Column, "SELECT", f.Field)
+	}
+	return checkPrivileges(c, list)
+}
+
+// checkPrivileges checks the given privileges of the runtime role with a single
+// query and returns an error for the first privilege that the role lacks. The
+// privilege on a relation is satisfied by the privilege on any of its columns,
+// except for DELETE which is not a column privilege.
+func checkPrivileges(c *checker, list privilegeList) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	oids := make([]int64, len(list))
+	cols := make([]string, len(list))
+	names := make([]string, len(list))
+	for i, p := range list {
+		oids[i] = int64(p.rel.OID)
+		if p.col != nil {
+			cols[i] = p.col.Name
+		}
+		names[i] = p.name
+	}
+
+	const selectMissingPrivilege = `SELECT
+		x.num
+	FROM unnest($2::int8[], $3::text[], $4::text[]) WITH ORDINALITY AS x(rel, col, priv, num)
+	WHERE NOT CASE
+		WHEN x.col <> '' THEN has_column_privilege($1, x.rel::oid, x.col, x.priv)
+		WHEN x.priv = 'DELETE' THEN has_table_privilege($1, x.rel::oid, x.priv)
+		ELSE has_any_column_privilege($1, x.rel::oid, x.priv)
+	END
+	ORDER BY x.num
+	LIMIT 1` //`
+	var num int
+	row := c.db.QueryRow(selectMissingPrivilege, c.db.Options.RuntimeRole,
+		pq.Array(oids), pq.Array(cols), pq.Array(names))
+	if err := row.Scan(&num); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return c.dbError(dbError{Code: errPrivilegeGet,
+			Priv: privInfo{Role: c.db.Options.RuntimeRole}, Err: err}, nil)
+	}
+
+	p := list[num-1]
+	e := dbError{Code: errPrivilegeMissing, Rel: relInfo{Relation: p.rel},
+		Priv: privInfo{Role: c.db.Options.RuntimeRole, Name: p.name}}
+	if p.col != nil {
+		e.Col = colInfo{Id: analysis.ColIdent{Name: p.col.Name}, Column: p.col}
+	}
+	return c.dbError(e, p.ptr)
+}
+
 // typeCheckFieldRead checks if a value from the column that is associated
 // with the given field can be read into that field. If strict=false and there
 // is no column associated with the given field the check will be skipped.
@@ -1153,6 +1859,28 @@ func typeCheckQueryOnConflictStruct(c *checker, qs *analysis.QueryStruct) error
 //	    ✅ The field's type MUST be a type that, together with the column's type,
 //	    has an entry in the compatibility table.
 func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
+	if len(f.Expr) > 0 {
+		col, err := loadExprColumn(c, f)
+		if err != nil {
+			return err
+		}
+		return typeCheckFieldReadColumn(c, f, col)
+	}
+	if f.Window != nil {
+		col, err := typeCheckFieldWindow(c, f)
+		if err != nil {
+			return err
+		}
+		return typeCheckFieldReadColumn(c, f, col)
+	}
+	if f.Many != nil {
+		col, err := typeCheckFieldMany(c, f)
+		if err != nil {
+			return err
+		}
+		return typeCheckFieldReadColumn(c, f, col)
+	}
+
 	col := findRelColumn(c.rel, f.ColIdent.Name)
 	if col == nil && strict {
 		return c.dbError(dbError{Code: errColumnUnknown, Col: colInfo{Id: f.ColIdent},
@@ -1160,7 +1888,13 @@ func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
 	} else if col == nil && !strict {
 		return nil
 	}
+	return typeCheckFieldReadColumn(c, f, col)
+}
 
+// typeCheckFieldReadColumn checks if a value of the given column can be read
+// into the given field and, if the check is successful, appends a new instance
+// of *FieldRead to the reads of the *TargetInfo instance.
+func typeCheckFieldReadColumn(c *checker, f *analysis.FieldInfo, col *Column) error {
 	check := func(f *analysis.FieldInfo, col *Column) (scanner string, ecode dbErrorCode) {
 		// non-empty interface, reject
 		if f.Type.Kind == analysis.TypeKindInterface && !f.Type.IsEmptyInterface {
@@ -1172,7 +1906,7 @@ func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
 			return "", 0
 		}
 
-		if col.Type.OID == oid.JSON || col.Type.OID == oid.JSONB {
+		if ctyp := baseType(c, col.Type); ctyp.is(oid.JSON, oid.JSONB) {
 			if !f.Type.ImplementsJSONUnmarshaler() {
 				// chan, func, or complex, reject
 				if f.Type.IsJSONIllegal() {
@@ -1199,7 +1933,7 @@ func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
 			return "AnyToEmptyInterface", 0
 		}
 
-		if col.Type.OID == oid.XML {
+		if baseType(c, col.Type).OID == oid.XML {
 			if !f.Type.ImplementsXMLUnmarshaler() {
 				if f.Type.IsXMLIllegal() {
 					return "", errColumnFieldTypeRead
@@ -1232,16 +1966,29 @@ func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
 		// 	// ...
 		// } else if col.Type.Type == TypeTypeDomain {
 		// 	// ...
-		// } else if col.Type.Type == TypeTypeComposite {
-		// 	// ...
 		// }
 		return "", errColumnFieldTypeRead
 	}
 
-	scanner, ecode := check(f, col)
-	if ecode > 0 {
-		return c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
-			Col: colInfo{Id: f.ColIdent, Column: col}}, f) // TODO test
+	if !isEnumFieldTypeAllowed(c, f.Type, col) {
+		return c.dbError(dbError{Code: errColumnEnumFieldType, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent, Column: col}}, f)
+	}
+
+	scanner, composite, err := typeCheckFieldComposite(c, f, col, true)
+	if err != nil {
+		return err
+	} else if composite == nil {
+		var ecode dbErrorCode
+		if scanner, ecode = check(f, col); ecode > 0 {
+			return c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
+				Col: colInfo{Id: f.ColIdent, Column: col}}, f) // TODO test
+		}
+	}
+
+	if c.db.Options.StrictNulls && !isNULLSafeRead(f, col, scanner) {
+		return c.dbError(dbError{Code: errColumnNullableRead, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent, Column: col}}, f)
 	}
 
 	read := new(FieldRead)
@@ -1249,6 +1996,7 @@ func typeCheckFieldRead(c *checker, f *analysis.FieldInfo, strict bool) error {
 	read.Column = col
 	read.ColIdent = f.ColIdent
 	read.Scanner = scanner
+	read.CompositeFields = composite
 
 	// NOTE(mkopriva): currently reading is allowed ONLY from the target
 	// relation therefore here the alias of the target relation is used.
@@ -1303,7 +2051,7 @@ func typeCheckFieldWrite(c *checker, f *analysis.FieldInfo) (*FieldWrite, error)
 			return "", 0
 		}
 
-		if col.Type.OID == oid.JSON || col.Type.OID == oid.JSONB {
+		if ctyp := baseType(c, col.Type); ctyp.is(oid.JSON, oid.JSONB) {
 			if !f.Type.ImplementsJSONMarshaler() {
 				// chan, func, or complex, reject
 				if f.Type.IsJSONIllegal() {
@@ -1323,7 +2071,7 @@ func typeCheckFieldWrite(c *checker, f *analysis.FieldInfo) (*FieldWrite, error)
 
 			// everything else, accept with JSON
 			return "JSON", 0
-		} else if col.Type.OID == oid.XML {
+		} else if ctyp.OID == oid.XML {
 			if !f.Type.ImplementsXMLMarshaler() {
 				if f.Type.IsXMLIllegal() {
 					return "", errColumnFieldTypeWrite
@@ -1356,17 +2104,25 @@ func typeCheckFieldWrite(c *checker, f *analysis.FieldInfo) (*FieldWrite, error)
 		// 	// ...
 		// } else if col.Type.Type == TypeTypeDomain {
 		// 	// ...
-		// } else if col.Type.Type == TypeTypeComposite {
-		// 	// ...
 		// }
 		return "", errColumnFieldTypeWrite
 
 	}
 
-	valuer, ecode := check(f, col)
-	if ecode > 0 {
-		return nil, c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
-			Col: colInfo{Id: f.ColIdent, Column: col}}, f) // TODO test
+	if !isEnumFieldTypeAllowed(c, f.Type, col) {
+		return nil, c.dbError(dbError{Code: errColumnEnumFieldType, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent, Column: col}}, f)
+	}
+
+	valuer, composite, err := typeCheckFieldComposite(c, f, col, false)
+	if err != nil {
+		return nil, err
+	} else if composite == nil {
+		var ecode dbErrorCode
+		if valuer, ecode = check(f, col); ecode > 0 {
+			return nil, c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
+				Col: colInfo{Id: f.ColIdent, Column: col}}, f) // TODO test
+		}
 	}
 
 	w := new(FieldWrite)
@@ -1375,9 +2131,109 @@ func typeCheckFieldWrite(c *checker, f *analysis.FieldInfo) (*FieldWrite, error)
 	w.ColIdent = f.ColIdent
 	w.ColIdent.Qualifier = c.rid.Alias
 	w.Valuer = valuer
+	w.CompositeFields = composite
 	return w, nil
 }
 
+// typeCheckFieldComposite checks if the given field can be read from, or
+// written to, the given column as a composite type value. If the column is
+// not of a composite type, or if the field has no composite fields, then
+// the check is skipped and a nil slice is returned.
+//
+// On success the name of the converter to be used for the column is returned
+// together with the names of the struct fields in the order of the attributes.
+//
+// CHECKLIST:
+//
+//	✅ If the column is an array of a composite type the field's type MUST
+//	   be a slice of structs, or a slice of pointers to structs, otherwise
+//	   it MUST be a struct or a pointer to a struct.
+//	✅ Each of the struct's fields MUST be mapped to an attribute of the composite type.
+//	✅ Each of the struct's fields MUST either implement the sql.Scanner (read),
+//	   or driver.Valuer (write), interface or it MUST be of a type that, together
+//	   with the attribute's type, has an entry in the compatibility table that
+//	   requires no converter.
+func typeCheckFieldComposite(c *checker, f *analysis.FieldInfo, col *Column, read bool) (conv string, fields []string, err error) {
+	if len(f.Composite) == 0 {
+		return "", nil, nil
+	}
+
+	ctyp, conv := col.Type, "Composite"
+	if ctyp.Category == TypeCategoryArray {
+		ctyp, conv = c.db.catalog.Types[ctyp.Elem], "CompositeArray"
+	}
+	if ctyp == nil || ctyp.Type != TypeTypeComposite {
+		return "", nil, nil
+	}
+
+	ecode := errColumnFieldTypeWrite
+	if read {
+		ecode = errColumnFieldTypeRead
+	}
+
+	ftyp := f.Type
+	if ftyp.Kind == analysis.TypeKindPtr {
+		ftyp = *ftyp.Elem
+	}
+	if conv == "CompositeArray" {
+		if ftyp.Kind != analysis.TypeKindSlice {
+			return "", nil, c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
+				Col: colInfo{Id: f.ColIdent, Column: col}}, f)
+		}
+		if ftyp = *ftyp.Elem; ftyp.Kind == analysis.TypeKindPtr {
+			ftyp = *ftyp.Elem
+		}
+	}
+	if ftyp.Kind != analysis.TypeKindStruct {
+		return "", nil, c.dbError(dbError{Code: ecode, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent, Column: col}}, f)
+	}
+
+	fields = make([]string, len(ctyp.Attributes))
+	for _, cf := range f.Composite {
+		idx := -1
+		for i, attr := range ctyp.Attributes {
+			if attr.Name == cf.Attr {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return "", nil, c.dbError(dbError{Code: errColumnCompositeAttrUnknown, Rel: relInfo{Relation: c.rel},
+				Col: colInfo{Id: f.ColIdent, Column: col}, Attr: attrInfo{Field: cf.Name, Name: cf.Attr}}, f)
+		}
+
+		attr := ctyp.Attributes[idx]
+		if !isCompositeAttrTypeAllowed(c, cf.Type, attr, read) {
+			return "", nil, c.dbError(dbError{Code: errColumnCompositeAttrType, Rel: relInfo{Relation: c.rel},
+				Col: colInfo{Id: f.ColIdent, Column: col}, Attr: attrInfo{Field: cf.Name, Name: cf.Attr, Column: attr}}, f)
+		}
+		fields[idx] = cf.Name
+	}
+	return conv, fields, nil
+}
+
+// isCompositeAttrTypeAllowed reports whether or not a field of the given
+// type can be read from, or written to, the given composite type attribute.
+func isCompositeAttrTypeAllowed(c *checker, ftyp analysis.TypeInfo, attr *Column, read bool) bool {
+	if read && ftyp.ImplementsScanner() {
+		return true
+	} else if !read && ftyp.ImplementsValuer() {
+		return true
+	} else if attr.Type == nil {
+		return false
+	}
+
+	comp := typeCompatibility(c, attr.Type, ftyp, isLength1Type(c, attr))
+	if comp == nil {
+		return false
+	}
+	if read {
+		return comp.scanner == ""
+	}
+	return comp.valuer == ""
+}
+
 // typeCheckFieldFilter
 //
 // CHECKLIST:
@@ -1420,7 +2276,7 @@ func typeCheckFieldFilter(c *checker, f *analysis.FieldInfo, strict bool) error
 			return "", 0
 		}
 
-		if col.Type.OID == oid.JSON || col.Type.OID == oid.JSONB {
+		if ctyp := baseType(c, col.Type); ctyp.is(oid.JSON, oid.JSONB) {
 			if !f.Type.ImplementsJSONMarshaler() {
 				// chan, func, or complex? reject
 				if f.Type.IsJSONIllegal() {
@@ -1440,7 +2296,7 @@ func typeCheckFieldFilter(c *checker, f *analysis.FieldInfo, strict bool) error
 
 			// everything else, accept with JSON
 			return "JSON", 0
-		} else if col.Type.OID == oid.XML {
+		} else if ctyp.OID == oid.XML {
 			if !f.Type.ImplementsXMLMarshaler() {
 				// chan, func, or map, reject
 				if f.Type.IsXMLIllegal() {
@@ -1474,8 +2330,6 @@ func typeCheckFieldFilter(c *checker, f *analysis.FieldInfo, strict bool) error
 		// 	// ...
 		// } else if col.Type.Type == TypeTypeDomain {
 		// 	// ...
-		// } else if col.Type.Type == TypeTypeComposite {
-		// 	// ...
 		// }
 		return "", errColumnFieldTypeWrite
 	}
@@ -1496,7 +2350,64 @@ func typeCheckFieldFilter(c *checker, f *analysis.FieldInfo, strict bool) error
 	return nil
 }
 
+// checkOperandCoercion reports whether or not a value of the given type can
+// be passed as the operand, of the given type, of an operator. Unlike the
+// checkTypeCoercion function, which is used for assignments, only implicit
+// casts are accepted, but the operand type may be a polymorphic pseudo-type.
+func checkOperandCoercion(c *checker, target oid.OID, source *Type) bool {
+	if target == source.OID || target == oid.Any || source.OID == oid.Unknown {
+		return true
+	}
+
+	switch target {
+	case oid.AnyElement, oid.AnyCompatible:
+		return true
+	case oid.AnyArray, oid.AnyCompatibleArray:
+		return source.Category == TypeCategoryArray
+	case oid.AnyNonArray, oid.AnyCompatibleNonArray:
+		return source.Category != TypeCategoryArray
+	case oid.AnyEnum:
+		return source.Type == TypeTypeEnum
+	case oid.AnyRange, oid.AnyCompatibleRange:
+		return source.Type == TypeTypeRange
+	}
+
+	key := CastKey{Target: target, Source: source.OID}
+	if cast := c.db.catalog.Casts[key]; cast != nil {
+		return cast.Context == CastContextImplicit
+	}
+	return false
+}
+
+// resolveOperandType returns the type of an operator's right operand given
+// the type of the left operand. If the operand type is a polymorphic
+// pseudo-type it is resolved from the left operand's type, in which case
+// the result may be nil if the type cannot be resolved.
+func resolveOperandType(c *checker, target oid.OID, left *Type) *Type {
+	if typ, ok := c.db.catalog.Types[target]; ok {
+		return typ
+	}
+
+	switch target {
+	case oid.AnyArray, oid.AnyCompatibleArray:
+		if left.Category == TypeCategoryArray {
+			return left
+		}
+		return c.db.catalog.Types[left.Array]
+	case oid.AnyElement, oid.AnyCompatible, oid.AnyNonArray, oid.AnyCompatibleNonArray,
+		oid.AnyEnum, oid.AnyRange, oid.AnyCompatibleRange:
+		if left.Category == TypeCategoryArray {
+			return c.db.catalog.Types[left.Elem]
+		}
+		return left
+	}
+	return nil
+}
+
 func checkTypeCoercion(c *checker, target oid.OID, source oid.OID) bool {
+	// domains are coerced to and from their base types implicitly
+	target, source = baseTypeOID(c, target), baseTypeOID(c, source)
+
 	// if same type, accept
 	if target == source {
 		return true
@@ -1524,6 +2435,75 @@ func checkTypeCoercion(c *checker, target oid.OID, source oid.OID) bool {
 // Find Functions
 //
 
+// findOperator finds and returns the operator with the given name whose operand
+// types are either the given types or types to which the given types can be
+// implicitly cast. An exact match is preferred, followed by the operators whose
+// operand types match more of the given types. If no such operator exists,
+// nil is returned.
+func findOperator(c *checker, name string, ltyp, rtyp *Type) *Operator {
+	key := OpKey{Name: name, Left: ltyp.OID, Right: rtyp.OID}
+	if op, ok := c.db.catalog.Operators[key]; ok {
+		return op
+	}
+
+	var found *Operator
+	var score int
+	for key, op := range c.db.catalog.Operators {
+		if key.Name != name || !checkOperandCoercion(c, key.Left, ltyp) || !checkOperandCoercion(c, key.Right, rtyp) {
+			continue
+		}
+
+		var n int
+		if key.Left == ltyp.OID {
+			n += 1
+		}
+		if key.Right == rtyp.OID {
+			n += 1
+		}
+		if found == nil || n > score || (n == score && op.OID < found.OID) {
+			found, score = op, n
+		}
+	}
+	return found
+}
+
+// findFieldOperator finds and returns the operator with the given name whose
+// left operand accepts the given column type and whose right operand type is
+// compatible with the given field type, together with the compatibility entry
+// of the field's type. If quantified is true the field is expected to be a
+// slice/array of the right operand type. If result is not 0 only operators
+// with that result type are considered. If no such operator exists, nil is returned.
+func findFieldOperator(c *checker, name string, ltyp *Type, ftyp analysis.TypeInfo, quantified bool, result oid.OID) (*Operator, *compentry) {
+	var found *Operator
+	var comp *compentry
+	for key, op := range c.db.catalog.Operators {
+		if key.Name != name || !checkOperandCoercion(c, key.Left, ltyp) || (result > 0 && op.Result != result) {
+			continue
+		}
+
+		rtyp := resolveOperandType(c, key.Right, ltyp)
+		if rtyp != nil && quantified {
+			rtyp = c.db.catalog.Types[rtyp.Array]
+		}
+		if rtyp == nil {
+			continue
+		}
+
+		ce := typeCompatibility(c, rtyp, ftyp, false)
+		if ce == nil {
+			continue
+		}
+
+		// prefer an operator whose operand doesn't require a valuer,
+		// the rest is sorted out by the oids to keep the result stable
+		if found == nil || (comp.valuer != "" && ce.valuer == "") ||
+			((comp.valuer == "") == (ce.valuer == "") && op.OID < found.OID) {
+			found, comp = op, ce
+		}
+	}
+	return found, comp
+}
+
 // findColumn finds and returns the *Column identified by the given ColIdent.
 // If the relation denoted by the column's qualifier doesn't exist, or if the
 // relation exists but the column itself is not present in that relation an
@@ -1552,6 +2532,17 @@ func findRelColumn(rel *Relation, name string) *Column {
 	return nil
 }
 
+// findRelColumnByNum finds and returns the *Column with the given number.
+// If no Column is found in the provided Relation, nil will be returned instead.
+func findRelColumnByNum(rel *Relation, num int64) *Column {
+	for _, col := range rel.Columns {
+		if int64(col.Num) == num {
+			return col
+		}
+	}
+	return nil
+}
+
 // findRelIndex finds and returns the *Index identified by the given name.
 // If no Index is found in the provided Relation, nil will be returned instead.
 func findRelIndex(rel *Relation, name string) *Index {
@@ -1608,12 +2599,28 @@ func loadCatalog(db *DB, key string) (*Catalog, error) {
 		return cat, nil
 	}
 
+	var fingerprint string
+	if len(db.cacheDir) > 0 {
+		if err := db.QueryRow(selectCatalogFingerprint).Scan(&fingerprint); err != nil {
+			return nil, db.dbError(dbError{Code: errCatalogCacheRead, Err: err})
+		}
+		cat, err := readCatalogCache(db, fingerprint)
+		if err != nil {
+			return nil, err
+		} else if cat != nil {
+			catalogCache.m[key] = cat
+			return cat, nil
+		}
+	}
+
 	cat := new(Catalog)
 	cat.Types = make(map[oid.OID]*Type)
 	cat.Operators = make(map[OpKey]*Operator)
 	cat.Casts = make(map[CastKey]*Cast)
 	cat.Procs = make(map[string][]*Proc)
 	cat.Relations = make(map[analysis.RelIdent]*Relation)
+	cat.fingerprint = fingerprint
+	cat.relFingerprints = make(map[oid.OID]string)
 
 	const selectTypes = `SELECT
 		t.oid
@@ -1624,6 +2631,10 @@ func loadCatalog(db *DB, key string) (*Catalog, error) {
 		, t.typcategory
 		, t.typispreferred
 		, t.typelem
+		, t.typarray
+		, t.typbasetype
+		, t.typtypmod
+		, t.typnotnull
 	FROM pg_type t
 	WHERE pg_catalog.pg_type_is_visible(t.oid)
 	AND t.typcategory <> 'P'` //`
@@ -1644,6 +2655,10 @@ func loadCatalog(db *DB, key string) (*Catalog, error) {
 			&typ.Category,
 			&typ.IsPreferred,
 			&typ.Elem,
+			&typ.Array,
+			&typ.BaseType,
+			&typ.TypeMod,
+			&typ.NotNull,
 		)
 		if err != nil {
 			return nil, db.dbError(dbError{Code: errCatalogTypeScan, Err: err})
@@ -1654,6 +2669,75 @@ func loadCatalog(db *DB, key string) (*Catalog, error) {
 		return nil, db.dbError(dbError{Code: errCatalogTypeGet, Err: err})
 	}
 
+	const selectEnums = `SELECT
+		e.enumtypid
+		, e.enumlabel
+	FROM pg_enum e
+	ORDER BY e.enumtypid, e.enumsortorder` //`
+	rows, err = db.Query(selectEnums)
+	if err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogEnumGet, Err: err})
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var typoid oid.OID
+		var label string
+		if err := rows.Scan(&typoid, &label); err != nil {
+			return nil, db.dbError(dbError{Code: errCatalogEnumScan, Err: err})
+		}
+		if typ, ok := cat.Types[typoid]; ok {
+			typ.EnumLabels = append(typ.EnumLabels, label)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogEnumGet, Err: err})
+	}
+
+	const selectAttributes = `SELECT
+		t.oid
+		, a.attnum
+		, a.attname
+		, a.atttypmod
+		, a.attndims
+		, a.atttypid
+	FROM pg_type t
+	JOIN pg_class c ON c.oid = t.typrelid
+	JOIN pg_attribute a ON a.attrelid = c.oid
+	WHERE t.typtype = 'c'
+	AND c.relkind = 'c'
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+	ORDER BY t.oid, a.attnum` //`
+	rows, err = db.Query(selectAttributes)
+	if err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogAttributeGet, Err: err})
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var typoid oid.OID
+		attr := new(Column)
+		err := rows.Scan(
+			&typoid,
+			&attr.Num,
+			&attr.Name,
+			&attr.TypeMod,
+			&attr.NumDims,
+			&attr.TypeOID,
+		)
+		if err != nil {
+			return nil, db.dbError(dbError{Code: errCatalogAttributeScan, Err: err})
+		}
+		if typ, ok := cat.Types[typoid]; ok {
+			attr.Type = cat.Types[attr.TypeOID]
+			typ.Attributes = append(typ.Attributes, attr)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, db.dbError(dbError{Code: errCatalogAttributeGet, Err: err})
+	}
+
 	const selectOperators = `SELECT
 		o.oid
 		, o.oprname
@@ -1779,7 +2863,11 @@ func loadCatalog(db *DB, key string) (*Catalog, error) {
 
 func loadTargetRelation(c *checker, f *analysis.RelField) error {
 	rid := f.Id
-	rel, err := loadRelation(c, c.db, rid, f)
+	qid, err := resolveRelIdent(c, rid, f)
+	if err != nil {
+		return err
+	}
+	rel, err := loadRelation(c, c.db, qid, f)
 	if err != nil {
 		return err
 	}
@@ -1795,32 +2883,332 @@ func loadTargetRelation(c *checker, f *analysis.RelField) error {
 	return nil
 }
 
+// typeCheckFieldWindow checks the columns referenced by the given field's window
+// function and returns the function's result in the form of a column.
+//
+// CHECKLIST:
+//
+//	✅ The column of the function's argument, if any, MUST be present in one
+//	   of the loaded relations.
+//	✅ Each column of the window's PARTITION BY and ORDER BY clauses MUST
+//	   be present in one of the loaded relations.
+func typeCheckFieldWindow(c *checker, f *analysis.FieldInfo) (*Column, error) {
+	win := f.Window
+
+	var arg *Column
+	if !win.Arg.IsEmpty() {
+		col, ecode := findColumn(c, win.Arg)
+		if ecode > 0 {
+			return nil, c.dbError(dbError{Code: ecode, Col: colInfo{Id: win.Arg}}, f)
+		}
+		arg = col
+	}
+	for _, cid := range win.Partition {
+		if _, ecode := findColumn(c, cid); ecode > 0 {
+			return nil, c.dbError(dbError{Code: ecode, Col: colInfo{Id: cid}}, f)
+		}
+	}
+	for _, item := range win.OrderBy {
+		if _, ecode := findColumn(c, item.ColIdent); ecode > 0 {
+			return nil, c.dbError(dbError{Code: ecode, Col: colInfo{Id: item.ColIdent}}, f)
+		}
+	}
+
+	col := new(Column)
+	col.Name = f.ColIdent.Name
+	switch win.Name {
+	case "row_number", "rank", "dense_rank", "count":
+		col.Type = c.db.catalog.Types[oid.Int8]
+		col.HasNotNull = true
+	case "percent_rank", "cume_dist":
+		col.Type = c.db.catalog.Types[oid.Float8]
+		col.HasNotNull = true
+	default: // lag, lead, first_value, last_value
+		col.Type = arg.Type
+		col.TypeMod = arg.TypeMod
+		col.NumDims = arg.NumDims
+	}
+	return col, nil
+}
+
+// loadExprColumn determines the result type of the given expression field's
+// SQL expression and returns it in the form of a column. The expression is
+// never evaluated, instead it is used to define a temporary view over the
+// relations that are loaded by the query and the type is read from the view's
+// column. The view is created inside a transaction that is always rolled back.
+func loadExprColumn(c *checker, f *analysis.FieldInfo) (*Column, error) {
+	keys := make([]string, 0, len(c.relMap))
+	for key := range c.relMap {
+		if len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	view := `CREATE TEMP VIEW _gosql_expr AS SELECT (` + f.Expr + `) AS _ FROM (VALUES (NULL)) AS _gosql_(_)`
+	for _, key := range keys {
+		rel := c.relMap[key]
+		view += ` LEFT JOIN ` + pq.QuoteIdentifier(rel.Schema) + `.` +
+			pq.QuoteIdentifier(rel.Name) + ` AS ` + pq.QuoteIdentifier(key) + ` ON false`
+	}
+
+	const selectViewColumnType = `SELECT
+		a.atttypid
+	FROM pg_attribute a
+	WHERE a.attrelid = 'pg_temp._gosql_expr'::regclass
+	AND a.attnum = 1` //`
+
+	var typoid oid.OID
+	err := func() error {
+		tx, err := c.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(view); err != nil {
+			return err
+		}
+		return tx.QueryRow(selectViewColumnType).Scan(&typoid)
+	}()
+	if err != nil {
+		return nil, c.dbError(dbError{Code: errColumnExprInvalid, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent}, Err: err}, f)
+	}
+
+	typ, ok := c.db.catalog.Types[typoid]
+	if !ok {
+		return nil, c.dbError(dbError{Code: errColumnExprInvalid, Rel: relInfo{Relation: c.rel},
+			Col: colInfo{Id: f.ColIdent}, Err: fmt.Errorf("unknown result type oid %d", typoid)}, f)
+	}
+
+	col := new(Column)
+	col.Name = f.ColIdent.Name
+	col.Type = typ
+	return col, nil
+}
+
+// typeCheckFieldMany loads the child relation of the given "many" field,
+// type-checks the field's join conditions and the columns of the child
+// relation, and returns a json column that represents the aggregated
+// records of the child relation.
+//
+// CHECKLIST:
+//
+//	✅ The child relation MUST exist.
+//	✅ The join conditions MUST reference existing columns.
+//	✅ Each field of the element type MUST have a corresponding column
+//	   in the child relation, unless it is an expression field.
+//	✅ The json value of each of the element type's columns MUST be
+//	   decodable by encoding/json into the corresponding field.
+func typeCheckFieldMany(c *checker, f *analysis.FieldInfo) (*Column, error) {
+	many := f.Many
+	rel, err := loadJoinRelation(c, many.RelIdent, f)
+	if err != nil {
+		return nil, err
+	}
+
+	conds, err := typeCheckJoinTagItems(c, many.TagItems, rel, many.RelIdent, f)
+	if err != nil {
+		return nil, err
+	}
+	if many.SoftDelete != nil {
+		if conds, err = appendJoinSoftDelete(c, conds, many.SoftDelete, rel); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cf := range many.Type.Fields {
+		var col *Column
+		if len(cf.Expr) > 0 {
+			if col, err = loadExprColumn(c, cf); err != nil {
+				return nil, err
+			}
+		} else if col = findRelColumn(rel, cf.ColIdent.Name); col == nil {
+			return nil, c.dbError(dbError{Code: errColumnUnknown, Col: colInfo{Id: cf.ColIdent},
+				Rel: relInfo{Relation: rel}}, cf)
+		}
+
+		// the records are decoded by encoding/json
+		if !isJSONDecodable(c, &cf.Type, col.Type) {
+			return nil, c.dbError(dbError{Code: errColumnFieldTypeRead, Rel: relInfo{Relation: rel},
+				Col: colInfo{Id: cf.ColIdent, Column: col}}, cf)
+		}
+	}
+
+	if c.res.ManyJoins == nil {
+		c.res.ManyJoins = make(map[*analysis.FieldInfo][]TableJoinConditional)
+	}
+	c.res.ManyJoins[f] = conds
+
+	col := new(Column)
+	col.Name = f.ColIdent.Name
+	col.Type = c.db.catalog.Types[oid.JSON]
+	col.HasNotNull = true
+	return col, nil
+}
+
+// isJSONDecodable reports whether or not the json value that json_build_object
+// produces from a value of the given column type can be decoded by encoding/json
+// into a value of the given Go type.
+func isJSONDecodable(c *checker, ftyp *analysis.TypeInfo, ctyp *Type) bool {
+	if ftyp.Kind == analysis.TypeKindPtr {
+		ftyp = ftyp.Elem
+	}
+	if ftyp.IsEmptyInterface {
+		return true
+	}
+	if ftyp.Kind == analysis.TypeKindInterface || ftyp.IsJSONIllegal() {
+		return false
+	}
+
+	ctyp = baseType(c, ctyp)
+
+	// time.Time decodes RFC 3339 strings only, of the date/time
+	// types it is only the timestamptz's json value that conforms
+	if ftyp.GenericLiteral() == analysis.LiteralTime {
+		return ctyp.OID == oid.Timestamptz
+	}
+	if ftyp.ImplementsJSONUnmarshaler() {
+		return true
+	}
+
+	switch {
+	case ctyp.is(oid.JSON, oid.JSONB):
+		return true
+	case ctyp.is(oid.Int2, oid.Int4, oid.Int8):
+		return ftyp.Is(analysis.TypeKindInt, analysis.TypeKindInt8, analysis.TypeKindInt16,
+			analysis.TypeKindInt32, analysis.TypeKindInt64, analysis.TypeKindUint,
+			analysis.TypeKindUint8, analysis.TypeKindUint16, analysis.TypeKindUint32,
+			analysis.TypeKindUint64, analysis.TypeKindFloat32, analysis.TypeKindFloat64)
+	case ctyp.is(oid.Float4, oid.Float8, oid.Numeric):
+		return ftyp.Is(analysis.TypeKindFloat32, analysis.TypeKindFloat64)
+	case ctyp.is(oid.Bool):
+		return ftyp.Is(analysis.TypeKindBool)
+	case ctyp.Category == TypeCategoryArray:
+		elem := c.db.catalog.Types[ctyp.Elem]
+		return elem != nil && ftyp.IsSequence() && isJSONDecodable(c, ftyp.Elem, elem)
+	case ctyp.Type == TypeTypeComposite:
+		return ftyp.Kind == analysis.TypeKindStruct || (ftyp.Kind == analysis.TypeKindMap &&
+			ftyp.Key.Kind == analysis.TypeKindString)
+	}
+
+	// Everything else, including bytea whose hex format is not the base64
+	// that encoding/json expects of a []byte, is encoded as a json string.
+	return ftyp.Kind == analysis.TypeKindString
+}
+
 func loadJoinRelation(c *checker, rid analysis.RelIdent, ptr analysis.FieldPtr) (rel *Relation, err error) {
-	if rel, err = loadRelation(c, c.db, rid, ptr); err != nil {
+	qid, err := resolveRelIdent(c, rid, ptr)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err = loadRelation(c, c.db, qid, ptr); err != nil {
 		return nil, err
 	}
 	c.relMap[relIdentKey(rid)] = rel
 	return rel, nil
 }
 
+// defaultSchema returns the schema of the first of the db's DefaultSchemas
+// that matches the package and the file of the given target, or empty.
+func defaultSchema(db *DB, info *analysis.Info) string {
+	filename := info.FileSet.Position(info.TypeNamePos).Filename
+	for _, ds := range db.Options.DefaultSchemas {
+		if ds.Match(info.PkgPath, filename) {
+			return ds.Schema.Value
+		}
+	}
+	return ""
+}
+
+// resolveRelIdent qualifies the given relation identifier with the name of the
+// schema that contains the relation, if the identifier has no qualifier and the
+// target's package has a default schema. The default schema takes precedence,
+// if it has no such relation then the relation's name MUST be unique among the
+// rest of the database's schemas. The resolved identifier is recorded in the
+// result so that the generator can emit the relation's qualified name.
+func resolveRelIdent(c *checker, rid analysis.RelIdent, ptr analysis.FieldPtr) (analysis.RelIdent, error) {
+	if len(c.schema) == 0 || len(rid.Qualifier) > 0 {
+		return rid, nil
+	}
+
+	qid := rid
+	qid.Qualifier = c.schema
+	c.db.catalog.RLock()
+	_, ok := c.db.catalog.Relations[qid]
+	c.db.catalog.RUnlock()
+
+	if !ok {
+		const selectRelationSchemas = `SELECT
+			ns.nspname
+		FROM pg_class c
+		JOIN pg_namespace ns ON ns.oid = c.relnamespace
+		WHERE c.relname = $1
+		AND c.relkind IN ('r', 'v', 'm', 'p', 'f')
+		AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+		AND ns.nspname NOT LIKE 'pg\_%'
+		ORDER BY ns.nspname <> $2, ns.nspname` //`
+		rows, err := c.db.Query(selectRelationSchemas, rid.Name, c.schema)
+		if err != nil {
+			return rid, c.dbError(dbError{Code: errRelationScan, Rel: relInfo{Id: rid}, Err: err}, ptr)
+		}
+		defer rows.Close()
+
+		var schemas []string
+		for rows.Next() {
+			var schema string
+			if err := rows.Scan(&schema); err != nil {
+				return rid, c.dbError(dbError{Code: errRelationScan, Rel: relInfo{Id: rid}, Err: err}, ptr)
+			}
+			schemas = append(schemas, schema)
+		}
+		if err := rows.Err(); err != nil {
+			return rid, c.dbError(dbError{Code: errRelationScan, Rel: relInfo{Id: rid}, Err: err}, ptr)
+		}
+
+		switch {
+		case len(schemas) == 0:
+			return rid, c.dbError(dbError{Code: errRelationUnknown, Rel: relInfo{Id: rid},
+				Schema: c.schema}, ptr)
+		case schemas[0] != c.schema && len(schemas) > 1:
+			return rid, c.dbError(dbError{Code: errRelationAmbiguous, Rel: relInfo{Id: rid},
+				Schema: c.schema, Schemas: schemas}, ptr)
+		}
+		qid.Qualifier = schemas[0]
+	}
+
+	if c.res.RelIdents == nil {
+		c.res.RelIdents = make(map[analysis.RelIdent]analysis.RelIdent)
+	}
+	c.res.RelIdents[rid] = qid
+	return qid, nil
+}
+
 func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldPtr) (*Relation, error) {
-	db.catalog.Lock()
-	defer db.catalog.Unlock()
-	if rel, ok := db.catalog.Relations[rid]; ok && rel != nil {
+	db.catalog.RLock()
+	rel, ok := db.catalog.Relations[rid]
+	db.catalog.RUnlock()
+	if ok && rel != nil {
 		return rel, nil
 	}
 
-	rel := new(Relation)
+	// The lock is not held while the relation is being loaded so as to not
+	// block the checkers running concurrently, which means that the same
+	// relation may be loaded more than once, only the first one is kept.
+	rel = new(Relation)
 	const selectRelationInfo = `SELECT
 		c.oid
 		, c.relname
 		, c.relkind
 		, ns.nspname
+		, ` + relFingerprintExpr + `
 	FROM pg_class c
 	LEFT JOIN pg_namespace ns ON ns.oid = c.relnamespace
 	WHERE c.oid = to_regclass($1)` //`
+	var fingerprint string
 	row := db.QueryRow(selectRelationInfo, rid.QualifiedName())
-	if err := row.Scan(&rel.OID, &rel.Name, &rel.RelKind, &rel.Schema); err != nil {
+	if err := row.Scan(&rel.OID, &rel.Name, &rel.RelKind, &rel.Schema, &fingerprint); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, c.dbError(dbError{Code: errRelationUnknown,
 				Rel: relInfo{Id: rid}}, ptr)
@@ -1829,12 +3217,19 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			Rel: relInfo{Id: rid}, Err: err}, ptr)
 	}
 
-	const selectRelationColumns = `SELECT
+	// generated columns are supported since v12
+	isGenerated := "false"
+	if db.version >= 120000 {
+		isGenerated = "a.attgenerated = 's'"
+	}
+	selectRelationColumns := `SELECT
 		a.attnum
 		, a.attname
 		, a.atttypmod
 		, a.attnotnull
 		, a.atthasdef
+		, ` + isGenerated + `
+		, a.attidentity::text
 		, COALESCE(i.indisprimary, false)
 		, a.attndims
 		, a.atttypid
@@ -1863,6 +3258,8 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			&col.TypeMod,
 			&col.HasNotNull,
 			&col.HasDefault,
+			&col.IsGenerated,
+			&col.Identity,
 			&col.IsPrimary,
 			&col.NumDims,
 			&col.TypeOID,
@@ -1879,6 +3276,15 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			col.Type = typ
 		}
 
+		// A domain's NOT NULL constraint applies to the columns
+		// of that domain in the same way as the column's own.
+		for typ := col.Type; typ != nil && typ.Type == TypeTypeDomain; typ = db.catalog.Types[typ.BaseType] {
+			if typ.NotNull {
+				col.HasNotNull = true
+				break
+			}
+		}
+
 		rel.Columns = append(rel.Columns, col)
 		col.Relation = rel
 	}
@@ -1887,6 +3293,12 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
 	}
 
+	if rel.RelKind == RelKindView || rel.RelKind == RelKindMaterializedView {
+		if err := loadViewColumnsNotNull(c, db, rid, rel, ptr); err != nil {
+			return nil, err
+		}
+	}
+
 	const selectRelationConstraints = `SELECT
 		c.oid
 		, c.conname
@@ -1895,6 +3307,7 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 		, c.condeferred
 		, c.conkey
 		, c.confkey
+		, c.confrelid
 	FROM pg_constraint c
 	LEFT JOIN pg_index i ON i.indexrelid = c.conindid
 	WHERE c.conrelid = $1
@@ -1916,6 +3329,7 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			&con.IsDeferred,
 			(*pq.Int64Array)(&con.Key),
 			(*pq.Int64Array)(&con.FKey),
+			&con.FRelOID,
 		)
 		if err != nil {
 			return nil, c.dbError(dbError{Code: errRelationConstraintScan,
@@ -1979,10 +3393,98 @@ func loadRelation(c *checker, db *DB, rid analysis.RelIdent, ptr analysis.FieldP
 			Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
 	}
 
+	switch rel.RelKind {
+	case RelKindForeignTable:
+		const selectForeignTable = `SELECT
+			w.fdwname
+			, CASE WHEN w.fdwhandler = 0 THEN 0
+				ELSE pg_relation_is_updatable(t.ftrelid, false)
+			END
+		FROM pg_foreign_table t
+		JOIN pg_foreign_server s ON s.oid = t.ftserver
+		JOIN pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+		WHERE t.ftrelid = $1` //`
+		row := db.QueryRow(selectForeignTable, rel.OID)
+		if err := row.Scan(&rel.ForeignDataWrapper, &rel.Events); err != nil {
+			return nil, c.dbError(dbError{Code: errRelationScan,
+				Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
+		}
+	}
+
+	db.catalog.Lock()
+	defer db.catalog.Unlock()
+	if r, ok := db.catalog.Relations[rid]; ok && r != nil {
+		return r, nil // loaded in the meantime by another checker
+	}
 	db.catalog.Relations[rid] = rel
+	db.catalog.relFingerprints[rel.OID] = fingerprint
 	return rel, nil
 }
 
+// loadViewColumnsNotNull infers the NOT NULL constraint of the given view's
+// columns by tracing them back to the columns of the view's base tables.
+//
+// The columns are traced through the query tree of the view's rewrite rule, a
+// view column is considered NOT NULL only if it is a plain reference to a base
+// table column that has a NOT NULL constraint. Expressions, like NULLIF or CASE,
+// can produce NULLs from NOT NULL columns, and so can outer joins, set operations,
+// and grouping sets, therefore nothing is inferred for those, see parseViewColumnRefs.
+func loadViewColumnsNotNull(c *checker, db *DB, rid analysis.RelIdent, rel *Relation, ptr analysis.FieldPtr) error {
+	const selectViewRule = `SELECT
+		r.ev_action::text
+	FROM pg_rewrite r
+	WHERE r.ev_class = $1
+	AND r.rulename = '_RETURN'` //`
+	var action string
+	if err := db.QueryRow(selectViewRule, rel.OID).Scan(&action); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return c.dbError(dbError{Code: errRelationColumnGet,
+			Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
+	}
+
+	refs := parseViewColumnRefs(action)
+	if len(refs) == 0 {
+		return nil
+	}
+	relids, attnums := make([]int64, len(refs)), make([]int64, len(refs))
+	for i, ref := range refs {
+		relids[i], attnums[i] = int64(ref.RelId), int64(ref.RefNum)
+	}
+
+	const selectColumnsNotNull = `SELECT
+		x.num
+	FROM unnest($1::int8[], $2::int8[]) WITH ORDINALITY AS x(rel, att, num)
+	JOIN pg_attribute a ON a.attrelid = x.rel::oid AND a.attnum = x.att
+	WHERE a.attnotnull
+	AND NOT a.attisdropped` //`
+	rows, err := db.Query(selectColumnsNotNull, pq.Array(relids), pq.Array(attnums))
+	if err != nil {
+		return c.dbError(dbError{Code: errRelationColumnGet,
+			Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var num int
+		if err := rows.Scan(&num); err != nil {
+			return c.dbError(dbError{Code: errRelationColumnScan,
+				Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
+		}
+		for _, col := range rel.Columns {
+			if col.Num == refs[num-1].Num {
+				col.HasNotNull = true
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return c.dbError(dbError{Code: errRelationColumnGet,
+			Rel: relInfo{Id: rid, Relation: rel}, Err: err}, ptr)
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Helper Functions
 //
@@ -2002,8 +3504,55 @@ func skipFieldRead(c *checker, fr *FieldRead) bool {
 	return fr.Field.IsWriteOnly() && (c.force == nil || !c.force.Contains(fr.ColIdent))
 }
 
+// isNULLSafeRead reports whether or not a NULL value of the given column can be
+// read into the given field without failing at runtime. A column is considered
+// NULLable unless it has a NOT NULL constraint or, if it is the result of an
+// expression or of a window function, unless its result is known to be NOT NULL.
+func isNULLSafeRead(f *analysis.FieldInfo, col *Column, scanner string) bool {
+	if col.HasNotNull {
+		return true
+	}
+	if f.UseCoalesce || f.Mode.TreatAsNotNULL() || f.Type.ImplementsScanner() || len(scanner) > 0 {
+		return true
+	}
+	return f.Type.Is(analysis.TypeKindPtr, analysis.TypeKindSlice, analysis.TypeKindMap, analysis.TypeKindInterface)
+}
+
+// isEnumFieldTypeAllowed reports whether or not a field of the given type
+// is allowed to be mapped to the given column by the enum policy of the checker.
+// If the column is of an enum type, or of an array of an enum type, and the
+// StrictEnums option is set, the field's base type MUST match the enum's Go type,
+// i.e. the type declared for the enum in EnumTypes, or the type with the enum's
+// Go name in the EnumsPkgPath package.
+func isEnumFieldTypeAllowed(c *checker, ftyp analysis.TypeInfo, col *Column) bool {
+	if !c.db.Options.StrictEnums || col == nil || col.Type == nil {
+		return true
+	}
+
+	ctyp := baseType(c, col.Type)
+	if ctyp.Category == TypeCategoryArray {
+		if ctyp = c.db.catalog.Types[ctyp.Elem]; ctyp == nil {
+			return true
+		}
+	}
+	if ctyp.Type != TypeTypeEnum {
+		return true
+	}
+
+	for ftyp.Elem != nil && (ftyp.Kind == analysis.TypeKindPtr ||
+		ftyp.Kind == analysis.TypeKindSlice || ftyp.Kind == analysis.TypeKindArray) {
+		ftyp = *ftyp.Elem
+	}
+	if id, ok := c.db.Options.EnumTypes[ctyp.Name]; ok {
+		return ftyp.PkgPath == id.PkgPath && ftyp.Name == id.Name
+	}
+	return len(ftyp.Name) > 0 && ftyp.Name == ctyp.EnumGoName() &&
+		ftyp.PkgPath == c.db.Options.EnumsPkgPath
+}
+
 // typeCompatibility
 func typeCompatibility(c *checker, ctyp *Type, ftyp analysis.TypeInfo, typmod1 bool) *compentry {
+	ctyp = baseType(c, ctyp)
 	lit := ftyp.GenericLiteral()
 	key := compkey{oid: ctyp.OID, typmod1: typmod1}
 	if ctyp.Type == TypeTypeEnum { // enum labels are basically text
@@ -2020,7 +3569,7 @@ func typeCompatibility(c *checker, ctyp *Type, ftyp analysis.TypeInfo, typmod1 b
 		if ctyp.Category == TypeCategoryString {
 			key.oid = oid.Text
 		} else if ctyp.Category == TypeCategoryArray {
-			if et := c.db.catalog.Types[ctyp.Elem]; et != nil && et.Category == TypeCategoryString {
+			if et := c.db.catalog.Types[ctyp.Elem]; et != nil && (et.Category == TypeCategoryString || et.Type == TypeTypeEnum) {
 				key.oid = oid.TextArr
 			}
 		}
@@ -2046,19 +3595,64 @@ func typeOfLiteral(c *checker, expr string) (*Type, dbErrorCode) {
 // isLength1Type reports whether or not the given column's type
 // is a "length 1" type, i.e. char(1), varchar(1), or bit(1)[], etc.
 func isLength1Type(c *checker, col *Column) bool {
-	typ := col.Type
+	typ, typmod := col.Type, col.TypeMod
 	if typ.Category == TypeCategoryArray {
 		typ = c.db.catalog.Types[typ.Elem]
 	}
 
+	// If the type is a domain, the typmod is the one
+	// applied by the domain to its base type.
+	for typ != nil && typ.Type == TypeTypeDomain {
+		if typmod < 0 {
+			typmod = typ.TypeMod
+		}
+		typ = c.db.catalog.Types[typ.BaseType]
+	}
+	if typ == nil {
+		return false
+	}
+
 	if typ.Category == TypeCategoryBitstring {
-		return (col.TypeMod == 1)
+		return (typmod == 1)
 	} else if typ.Category == TypeCategoryString {
-		return ((col.TypeMod - 4) == 1)
+		return ((typmod - 4) == 1)
 	}
 	return false
 }
 
+// baseType returns the base type of the given type if it is a domain, following
+// the chain of domains down to the first type that is not a domain. If the given
+// type is an array of a domain the array type of the element's base type is
+// returned. For all other types, or if the base type cannot be found in the
+// catalog, the given type is returned as is.
+func baseType(c *checker, typ *Type) *Type {
+	if typ.Category == TypeCategoryArray {
+		if elem := c.db.catalog.Types[typ.Elem]; elem != nil && elem.Type == TypeTypeDomain {
+			if arr := c.db.catalog.Types[baseType(c, elem).Array]; arr != nil {
+				return arr
+			}
+		}
+		return typ
+	}
+
+	for typ.Type == TypeTypeDomain {
+		base := c.db.catalog.Types[typ.BaseType]
+		if base == nil {
+			break
+		}
+		typ = base
+	}
+	return typ
+}
+
+// baseTypeOID is like baseType but it operates on type OIDs.
+func baseTypeOID(c *checker, id oid.OID) oid.OID {
+	if typ := c.db.catalog.Types[id]; typ != nil {
+		return baseType(c, typ).OID
+	}
+	return id
+}
+
 func relIdentKey(rid analysis.RelIdent) string {
 	if len(rid.Alias) > 0 {
 		return rid.Alias
@@ -3,6 +3,8 @@ package postgres
 import (
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/frk/compare"
@@ -86,6 +88,42 @@ func TestCheck(t *testing.T) {
 	if err != nil {
 		log.Fatalf("relation not found: %v\n", err)
 	}
+	test_generated, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_generated", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	matview_test, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"matview_test", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_foreign, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_foreign", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_foreign_updatable, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_foreign_updatable", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_join_pair, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_join_pair", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_place, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_place", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_join_node, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_join_node", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_join1, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_join1", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	test_post, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"test_post", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
 
 	tests := []struct {
 		name     string
@@ -126,6 +164,34 @@ func TestCheck(t *testing.T) {
 		name:     "SelectPostgresTestOK_WhereJoinedUnaryNullColumn",
 		printerr: true,
 		err:      nil,
+	}, {
+		name:     "SelectPostgresTestOK_DomainTypes",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "InsertPostgresTestOK_DomainTypes",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "InsertPostgresTestOK_GeneratedColumns",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "InsertPostgresTestOK_IdentityDefault",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "InsertPostgresTestOK_ExprFields",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "SelectPostgresTestOK_EnumArrayPredicates",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "UpdatePostgresTestOK_IdentityReadOnly",
+		printerr: true,
+		err:      nil,
 	}, {
 		name: "SelectPostgresTestBAD_NoRelation",
 		err: &dbError{
@@ -1486,19 +1552,1441 @@ func TestCheck(t *testing.T) {
 			},
 			Rel: relInfo{Id: analysis.RelIdent{"column_tests_2", "", "public"}, Relation: column_tests_2},
 		},
-	}}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			info, err := testCheck(tt.name, t)
-			if e := compare.Compare(err, tt.err); e != nil {
-				t.Errorf("%v - %#v %v", e, err, err)
-			}
-			_ = info
-
-			if tt.printerr && err != nil {
-				fmt.Println(err)
-			}
-		})
+	}, {
+		name: "InsertPostgresTestBAD_AutoNowColumnType",
+		err: &dbError{
+			Code: errColumnAutoNowType,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "InsertPostgresTestBAD_AutoNowColumnType",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 443,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "string",
+				Tag:  `sql:"col_b,autonow"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 445,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"column_tests_1", "", "public"}, Relation: column_tests_1},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(column_tests_1, "col_b")},
+		},
+	}, {
+		name: "UpdatePostgresTestBAD_ForceGeneratedColumn",
+		err: &dbError{
+			Code: errColumnGeneratedForce,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "UpdatePostgresTestBAD_ForceGeneratedColumn",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 450,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.Force",
+				Tag:  `sql:"g.total"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 455,
+				},
+			},
+			Rel: relInfo{Relation: test_generated},
+			Col: colInfo{Id: analysis.ColIdent{"total", "g"}, Column: findRelColumn(test_generated, "total")},
+		},
+	}, {
+		name: "InsertPostgresTestBAD_IdentityNoOverride",
+		err: &dbError{
+			Code: errColumnIdentityOverride,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "InsertPostgresTestBAD_IdentityNoOverride",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 459,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Id",
+				Type: "int",
+				Tag:  `sql:"id"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 461,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_generated", "g", "public"}, Relation: test_generated},
+			Col: colInfo{Id: analysis.ColIdent{"id", ""}, Column: findRelColumn(test_generated, "id")},
+		},
+	}, {
+		name: "UpdatePostgresTestBAD_IdentityUpdate",
+		err: &dbError{
+			Code: errColumnIdentityUpdate,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "UpdatePostgresTestBAD_IdentityUpdate",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 468,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Id",
+				Type: "int",
+				Tag:  `sql:"id"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 470,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_generated", "g", "public"}, Relation: test_generated},
+			Col: colInfo{Id: analysis.ColIdent{"id", ""}, Column: findRelColumn(test_generated, "id")},
+		},
+	}, {
+		name: "InsertPostgresTestOK_PartitionedOnConflict",
+	}, {
+		name: "SelectPostgresTestOK_MaterializedView",
+	}, {
+		name: "SelectPostgresTestOK_ForeignTable",
+	}, {
+		name: "UpdatePostgresTestBAD_MaterializedView",
+		err: &dbError{
+			Code: errRelationMaterializedViewWrite,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "UpdatePostgresTestBAD_MaterializedView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 491,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Rel",
+				Type: "struct{A int \"sql:\\\"col_a\\\"\"; B string \"sql:\\\"col_b\\\"\"}",
+				Tag:  `rel:"matview_test"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 492,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"matview_test", "", ""}, Relation: matview_test},
+		},
+	}, {
+		name: "InsertPostgresTestBAD_ForeignTable",
+		err: &dbError{
+			Code: errRelationForeignTableCommand,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "InsertPostgresTestBAD_ForeignTable",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 499,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Rel",
+				Type: "struct{Id int \"sql:\\\"id\\\"\"; Name string \"sql:\\\"name\\\"\"}",
+				Tag:  `rel:"test_foreign"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 500,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_foreign", "", ""}, Relation: test_foreign},
+		},
+	}, {
+		name: "InsertPostgresTestBAD_ForeignTableOnConflict",
+		err: &dbError{
+			Code: errOnConflictForeignTable,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "InsertPostgresTestBAD_ForeignTableOnConflict",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 607,
+				},
+			},
+			Field: fieldInfo{
+				Name: "OnConflict",
+				Type: "struct{_ github.com/frk/gosql.Ignore}",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 612,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_foreign_updatable", "", ""}, Relation: test_foreign_updatable},
+		},
+	}, {
+		name: "SelectPostgresTestOK_JoinInferred",
+	}, {
+		name: "SelectPostgresTestBAD_JoinInferredNone",
+		err: &dbError{
+			Code: errJoinForeignKeyNone,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_JoinInferredNone",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 525,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.LeftJoin",
+				Tag:  `sql:"column_tests_1:c"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 530,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"column_tests_1", "c", ""}, Relation: column_tests_1},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_JoinInferredAmbiguous",
+		err: &dbError{
+			Code: errJoinForeignKeyAmbiguous,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_JoinInferredAmbiguous",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 535,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.LeftJoin",
+				Tag:  `sql:"test_user:u"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 540,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_user", "u", ""}, Relation: test_user},
+			Cons: []string{
+				"test_join_message_sender_id_fkey (u.id = m.sender_id)",
+				"test_join_message_recipient_id_fkey (u.id = m.recipient_id)",
+			},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_JoinInferredSelfAmbiguous",
+		err: &dbError{
+			Code: errJoinForeignKeyAmbiguous,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_JoinInferredSelfAmbiguous",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 618,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.LeftJoin",
+				Tag:  `sql:"test_join_pair:b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 623,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_join_pair", "b", ""}, Relation: test_join_pair},
+			Cons: []string{
+				"test_join_pair_left_id_fkey (b.left_id = a.id)",
+				"test_join_pair_right_id_fkey (b.right_id = a.id)",
+				"test_join_pair_left_id_fkey (b.id = a.left_id)",
+				"test_join_pair_right_id_fkey (b.id = a.right_id)",
+			},
+		},
+	}, {
+		name: "SelectPostgresTestOK_WhereOperators",
+	}, {
+		name: "SelectPostgresTestOK_OrderByOperator",
+	}, {
+		name: "SelectPostgresTestBAD_WhereOperatorUnknown",
+		err: &dbError{
+			Code: errOperatorUnknown,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_WhereOperatorUnknown",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 628,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Name",
+				Type: "string",
+				Tag:  `sql:"p.name <->"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 631,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"test_place", "", "public"}, Relation: test_place},
+			Col:  colInfo{Id: analysis.ColIdent{"name", "p"}, Column: findRelColumn(test_place, "name")},
+			Pred: analysis.IsOperator,
+			Oper: "<->",
+		},
+	}, {
+		name: "SelectPostgresTestBAD_WhereOperatorFieldType",
+		err: &dbError{
+			Code: errOperatorUnknown,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_WhereOperatorFieldType",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 636,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Name",
+				Type: "int",
+				Tag:  `sql:"p.name ~~*"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 639,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"test_place", "", "public"}, Relation: test_place},
+			Col:  colInfo{Id: analysis.ColIdent{"name", "p"}, Column: findRelColumn(test_place, "name")},
+			Pred: analysis.IsOperator,
+			Oper: "~~*",
+		},
+	}, {
+		name: "SelectPostgresTestBAD_WhereOperatorColumnType",
+		err: &dbError{
+			Code: errOperatorUnknown,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_WhereOperatorColumnType",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 644,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.Column",
+				Tag:  `sql:"p.id ~~* 'foo%'"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 647,
+				},
+			},
+			Rel:    relInfo{Id: analysis.RelIdent{"test_place", "", "public"}, Relation: test_place},
+			Col:    colInfo{Id: analysis.ColIdent{"id", "p"}, Column: findRelColumn(test_place, "id")},
+			RHSLit: exprInfo{Expr: "'foo%'", Type: testdb.DB.catalog.Types[oid.Unknown]},
+			Pred:   analysis.IsOperator,
+			Oper:   "~~*",
+		},
+	}, {
+		name: "SelectPostgresTestBAD_WhereOperatorResult",
+		err: &dbError{
+			Code: errOperatorResultBool,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_WhereOperatorResult",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 652,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Point",
+				Type: "[2]float64",
+				Tag:  `sql:"p.location <->"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 655,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"test_place", "", "public"}, Relation: test_place},
+			Col:  colInfo{Id: analysis.ColIdent{"location", "p"}, Column: findRelColumn(test_place, "location")},
+			Pred: analysis.IsOperator,
+			Oper: "<->",
+		},
+	}, {
+		name: "SelectPostgresTestBAD_OrderByOperatorParamType",
+		err: &dbError{
+			Code: errOperatorUnknown,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_OrderByOperatorParamType",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 660,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Point",
+				Type: "int",
+				Tag:  ``,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 663,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"test_place", "", "public"}, Relation: test_place},
+			Col:  colInfo{Id: analysis.ColIdent{"location", "p"}, Column: findRelColumn(test_place, "location")},
+			Oper: "<->",
+		},
+	}, {
+		name: "SelectPostgresTestBAD_JoinInferredSelf",
+		err: &dbError{
+			Code: errJoinForeignKeyAmbiguous,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_JoinInferredSelf",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 674,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.LeftJoin",
+				Tag:  `sql:"test_join_node:p"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 679,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_join_node", "p", ""}, Relation: test_join_node},
+			Cons: []string{
+				"test_join_node_parent_id_fkey (p.parent_id = n.id)",
+				"test_join_node_parent_id_fkey (p.id = n.parent_id)",
+			},
+		},
+	}, {
+		name: "DeletePostgresTestBAD_JoinInferredTarget",
+		err: &dbError{
+			Code: errJoinForeignKeyNone,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "DeletePostgresTestBAD_JoinInferredTarget",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 684,
+				},
+			},
+			Field: fieldInfo{
+				Name: "_",
+				Type: "github.com/frk/gosql.LeftJoin",
+				Tag:  `sql:"test_join1:j"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 688,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_join1", "j", ""}, Relation: test_join1},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_ManyFieldType",
+		err: &dbError{
+			Code: errColumnFieldTypeRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_ManyFieldType",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 696,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"test_post", "", "public"}, Relation: test_post},
+			Col: colInfo{Id: analysis.ColIdent{"user_id", ""}, Column: findRelColumn(test_post, "user_id")},
+			Field: fieldInfo{
+				Name: "UserId",
+				Type: "string",
+				Tag:  `sql:"user_id"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 704,
+				},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := testCheck(tt.name, t)
+			if e := compare.Compare(err, tt.err); e != nil {
+				t.Errorf("%v - %#v %v", e, err, err)
+			}
+			_ = info
+
+			if tt.printerr && err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+}
+
+func TestCheckJoinInferred(t *testing.T) {
+	type cond struct{ LHS, RHS analysis.ColIdent }
+	tests := []struct {
+		name string
+		want [][]cond
+	}{{
+		name: "SelectPostgresTestOK_JoinInferred",
+		want: [][]cond{
+			{{analysis.ColIdent{"id", "u"}, analysis.ColIdent{"user_id", "p"}}},
+			{{analysis.ColIdent{"post_id", "j"}, analysis.ColIdent{"id", "p"}}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := testCheck(tt.name, t)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got [][]cond
+			for _, conds := range info.Joins {
+				var list []cond
+				for _, c := range conds {
+					if cc, ok := c.(*ColumnConditional); ok && cc.Predicate == analysis.IsEQ {
+						list = append(list, cond{cc.LHSColIdent, cc.RHSColIdent})
+					}
+				}
+				got = append(got, list)
+			}
+			if e := compare.Compare(got, tt.want); e != nil {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestCheckStrictNulls(t *testing.T) {
+	test_dbinfo := dbInfo{DSN: testdb.DB.dsn, Name: testdb.DB.name, User: testdb.DB.user, SearchPath: testdb.DB.searchpath}
+	column_tests_1, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"column_tests_1", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	view_test_outer, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"view_test_outer", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+
+	view_test_nullif, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"view_test_nullif", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	view_test_case, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"view_test_case", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	view_test_union, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"view_test_union", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+	view_test_alias, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"view_test_alias", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+
+	col_a := findRelColumn(column_tests_1, "col_a")
+
+	testdb.DB.Options.StrictNulls = true
+	defer func() { testdb.DB.Options.StrictNulls = false }()
+
+	tests := []struct {
+		name     string
+		err      error
+		printerr bool
+	}{{
+		name:     "SelectPostgresTestOK_StrictNulls",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "SelectPostgresTestOK_StrictNullsView",
+		printerr: true,
+		err:      nil,
+	}, {
+		name:     "SelectPostgresTestOK_StrictNullsAliasView",
+		printerr: true,
+		err:      nil,
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsColumn",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsColumn",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 476,
+				},
+			},
+			Field: fieldInfo{
+				Name: "C",
+				Type: "bool",
+				Tag:  `sql:"col_c"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 479,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"column_tests_1", "", "public"}, Relation: column_tests_1},
+			Col: colInfo{Id: analysis.ColIdent{"col_c", ""}, Column: findRelColumn(column_tests_1, "col_c")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsOuterJoinView",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsOuterJoinView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 484,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "string",
+				Tag:  `sql:"col_b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 486,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"view_test_outer", "", "public"}, Relation: view_test_outer},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(view_test_outer, "col_b")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsNullifView",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsNullifView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 579,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "string",
+				Tag:  `sql:"col_b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 581,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"view_test_nullif", "", "public"}, Relation: view_test_nullif},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(view_test_nullif, "col_b")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsCaseView",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsCaseView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 586,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "string",
+				Tag:  `sql:"col_b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 588,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"view_test_case", "", "public"}, Relation: view_test_case},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(view_test_case, "col_b")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsUnionView",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsUnionView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 593,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "string",
+				Tag:  `sql:"col_b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 595,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"view_test_union", "", "public"}, Relation: view_test_union},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(view_test_union, "col_b")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsAliasView",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsAliasView",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 600,
+				},
+			},
+			Field: fieldInfo{
+				Name: "B",
+				Type: "bool",
+				Tag:  `sql:"col_b"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 602,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"view_test_alias", "", "public"}, Relation: view_test_alias},
+			Col: colInfo{Id: analysis.ColIdent{"col_b", ""}, Column: findRelColumn(view_test_alias, "col_b")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictNullsWindowLag",
+		err: &dbError{
+			Code: errColumnNullableRead,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictNullsWindowLag",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 667,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Prev",
+				Type: "int",
+				Tag:  `sql:"@lag(col_a),over:order=col_a"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 669,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"column_tests_1", "", "public"}, Relation: column_tests_1},
+			Col: colInfo{Id: analysis.ColIdent{"prev", ""}, Column: &Column{
+				Name:    "prev",
+				TypeMod: col_a.TypeMod,
+				NumDims: col_a.NumDims,
+				Type:    col_a.Type,
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testCheck(tt.name, t)
+			if e := compare.Compare(err, tt.err); e != nil {
+				t.Errorf("%v - %#v %v", e, err, err)
+			}
+
+			if tt.printerr && err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+}
+
+func TestCheckStrictEnums(t *testing.T) {
+	test_dbinfo := dbInfo{DSN: testdb.DB.dsn, Name: testdb.DB.name, User: testdb.DB.user, SearchPath: testdb.DB.searchpath}
+	column_tests_3, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"column_tests_3", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+
+	testdb.DB.Options.StrictEnums = true
+	testdb.DB.Options.EnumsPkgPath = "path/to/test"
+	defer func() {
+		testdb.DB.Options.StrictEnums = false
+		testdb.DB.Options.EnumsPkgPath = ""
+	}()
+
+	tests := []struct {
+		name     string
+		err      error
+		printerr bool
+	}{{
+		name:     "SelectPostgresTestOK_StrictEnums",
+		printerr: true,
+		err:      nil,
+	}, {
+		name: "SelectPostgresTestBAD_StrictEnumsPackage",
+		err: &dbError{
+			Code: errColumnEnumFieldType,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictEnumsPackage",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 562,
+				},
+			},
+			Field: fieldInfo{
+				Name: "ColorEnum",
+				Type: "github.com/frk/gosql/internal/testdata/common.ColorEnum",
+				Tag:  `sql:"color_enum"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 564,
+				},
+			},
+			Rel: relInfo{Id: analysis.RelIdent{"column_tests_3", "", "public"}, Relation: column_tests_3},
+			Col: colInfo{Id: analysis.ColIdent{"color_enum", ""}, Column: findRelColumn(column_tests_3, "color_enum")},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_StrictEnumsWhere",
+		err: &dbError{
+			Code: errColumnEnumFieldType,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_StrictEnumsWhere",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 569,
+				},
+			},
+			Field: fieldInfo{
+				Name: "ColorEnums",
+				Type: "[]string",
+				Tag:  `sql:"color_enum isin"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 574,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"column_tests_3", "", "public"}, Relation: column_tests_3},
+			Col:  colInfo{Id: analysis.ColIdent{"color_enum", ""}, Column: findRelColumn(column_tests_3, "color_enum")},
+			Pred: analysis.IsIn,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testCheck(tt.name, t)
+			if e := compare.Compare(err, tt.err); e != nil {
+				t.Errorf("%v - %#v %v", e, err, err)
+			}
+
+			if tt.printerr && err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+}
+
+func TestParseViewColumnRefs(t *testing.T) {
+	// the query tree of a view with two plain column references, one
+	// renamed, and an expression; abridged to the fields that matter
+	const plain = `({QUERY :commandType 1 :querySource 0 :canSetTag true :utilityStmt <>` +
+		` :rtable ({RANGETBLENTRY :alias <> :eref {ALIAS :aliasname t :colnames ("a" "b" "c")}` +
+		` :rtekind 0 :relid 16385 :relkind r :rellockmode 1 :inh true})` +
+		` :jointree {FROMEXPR :fromlist ({RANGETBLREF :rtindex 1}) :quals <>}` +
+		` :targetList ({TARGETENTRY :expr {VAR :varno 1 :varattno 1 :vartype 23 :vartypmod -1` +
+		` :varcollid 0 :varnullingrels (b) :varlevelsup 0 :location 7} :resno 1 :resname id :resjunk false}` +
+		` {TARGETENTRY :expr {VAR :varno 1 :varattno 2 :vartype 25 :vartypmod -1 :varcollid 100` +
+		` :varlevelsup 0 :location 19} :resno 2 :resname b :resjunk false}` +
+		` {TARGETENTRY :expr {FUNCEXPR :funcid 1317 :funcresulttype 23 :args ({VAR :varno 1 :varattno 2` +
+		` :vartype 25 :varlevelsup 0 :location 30}) :location 23} :resno 3 :resname \(len\) :resjunk false})` +
+		` :groupingSets <> :setOperations <> :constraintDeps <> :stmt_location 0})`
+
+	// join returns the plain view's tree with its relation joined to itself
+	join := func(jointype string) string {
+		return strings.Replace(plain, "({RANGETBLREF :rtindex 1})", "({JOINEXPR :jointype "+jointype+
+			" :isNatural false :larg {RANGETBLREF :rtindex 1} :rarg {RANGETBLREF :rtindex 1}})", 1)
+	}
+
+	tests := []struct {
+		name   string
+		action string
+		want   []viewColumnRef
+	}{{
+		name:   "plain",
+		action: plain,
+		want:   []viewColumnRef{{Num: 1, RelId: 16385, RefNum: 1}, {Num: 2, RelId: 16385, RefNum: 2}},
+	}, {
+		name:   "outer join",
+		action: join("1"),
+		want:   nil,
+	}, {
+		name:   "inner join",
+		action: join("0"),
+		want:   []viewColumnRef{{Num: 1, RelId: 16385, RefNum: 1}, {Num: 2, RelId: 16385, RefNum: 2}},
+	}, {
+		name:   "set operation",
+		action: strings.Replace(plain, ":setOperations <>", ":setOperations {SETOPERATIONSTMT :op 1 :all true}", 1),
+		want:   nil,
+	}, {
+		name:   "grouping sets",
+		action: strings.Replace(plain, ":groupingSets <>", ":groupingSets ({GROUPINGSET :kind 3 :content <>})", 1),
+		want:   nil,
+	}, {
+		name:   "subquery",
+		action: strings.Replace(plain, ":rtekind 0", ":rtekind 1", 1),
+		want:   nil,
+	}}
+	for _, tt := range tests {
+		got := parseViewColumnRefs(tt.action)
+		if e := compare.Compare(got, tt.want); e != nil {
+			t.Errorf("%s: %v", tt.name, e)
+		}
+	}
+}
+
+func TestCheckRuntimeRole(t *testing.T) {
+	test_dbinfo := dbInfo{DSN: testdb.DB.dsn, Name: testdb.DB.name, User: testdb.DB.user, SearchPath: testdb.DB.searchpath}
+	column_tests_1, err := loadRelation(&checker{db: testdb.DB}, testdb.DB, analysis.RelIdent{"column_tests_1", "", ""}, 0)
+	if err != nil {
+		log.Fatalf("relation not found: %v\n", err)
+	}
+
+	testdb.DB.Options.RuntimeRole = testRuntimeRole
+	defer func() { testdb.DB.Options.RuntimeRole = "" }()
+
+	tests := []struct {
+		name     string
+		err      error
+		printerr bool
+	}{{
+		name: "SelectPostgresTestOK_RuntimeRole",
+		err:  nil,
+	}, {
+		name: "SelectPostgresTestBAD_RuntimeRoleColumn",
+		err: &dbError{
+			Code: errPrivilegeMissing,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_RuntimeRoleColumn",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 507,
+				},
+			},
+			Field: fieldInfo{
+				Name: "C",
+				Type: "*bool",
+				Tag:  `sql:"col_c"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 510,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"column_tests_1", "", "public"}, Relation: column_tests_1},
+			Col:  colInfo{Id: analysis.ColIdent{"col_c", ""}, Column: findRelColumn(column_tests_1, "col_c")},
+			Priv: privInfo{Role: testRuntimeRole, Name: "SELECT"},
+		},
+	}, {
+		name: "DeletePostgresTestBAD_RuntimeRoleRelation",
+		err: &dbError{
+			Code: errPrivilegeMissing,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "DeletePostgresTestBAD_RuntimeRoleRelation",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 515,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Rel",
+				Type: "struct{A int \"sql:\\\"col_a\\\"\"}",
+				Tag:  `rel:"column_tests_1"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 516,
+				},
+			},
+			Rel:  relInfo{Id: analysis.RelIdent{"column_tests_1", "", "public"}, Relation: column_tests_1},
+			Priv: privInfo{Role: testRuntimeRole, Name: "DELETE"},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testCheck(tt.name, t)
+			if e := compare.Compare(err, tt.err); e != nil {
+				t.Errorf("%v - %#v %v", e, err, err)
+			}
+			if tt.printerr && err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+}
+
+func TestCheckDefaultSchemas(t *testing.T) {
+	test_dbinfo := dbInfo{DSN: testdb.DB.dsn, Name: testdb.DB.name, User: testdb.DB.user, SearchPath: testdb.DB.searchpath}
+
+	testdb.DB.Options.DefaultSchemas = []config.DefaultSchema{{
+		Package: config.String{Value: "path/to/...", IsSet: true},
+		Schema:  config.String{Value: "test_billing", IsSet: true},
+	}}
+	defer func() { testdb.DB.Options.DefaultSchemas = nil }()
+
+	tests := []struct {
+		name     string
+		want     map[analysis.RelIdent]analysis.RelIdent
+		err      error
+		printerr bool
+	}{{
+		name: "SelectPostgresTestOK_DefaultSchema",
+		want: map[analysis.RelIdent]analysis.RelIdent{
+			{"test_invoice", "i", ""}: {"test_invoice", "i", "test_billing"},
+			{"test_session", "s", ""}: {"test_session", "s", "test_auth"},
+		},
+	}, {
+		name: "SelectPostgresTestBAD_DefaultSchemaAmbiguous",
+		err: &dbError{
+			Code: errRelationAmbiguous,
+			DB:   test_dbinfo,
+			Target: targetInfo{
+				Pkg:  "path/to/test",
+				Name: "SelectPostgresTestBAD_DefaultSchemaAmbiguous",
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 545,
+				},
+			},
+			Field: fieldInfo{
+				Name: "Rel",
+				Type: "struct{Id int \"sql:\\\"id\\\"\"}",
+				Tag:  `rel:"test_account"`,
+				File: fileInfo{
+					Name: "../testdata/postgres_bad.go",
+					Line: 546,
+				},
+			},
+			Rel:     relInfo{Id: analysis.RelIdent{"test_account", "", ""}},
+			Schema:  "test_billing",
+			Schemas: []string{"test_auth", "test_shop"},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := testCheck(tt.name, t)
+			if e := compare.Compare(err, tt.err); e != nil {
+				t.Errorf("%v - %#v %v", e, err, err)
+			}
+			if err == nil {
+				if e := compare.Compare(info.RelIdents, tt.want); e != nil {
+					t.Error(e)
+				}
+			}
+			if tt.printerr && err != nil {
+				fmt.Println(err)
+			}
+		})
+	}
+}
+
+func TestCheckConfigName(t *testing.T) {
+	testdb.DB.ConfigName = "analytics"
+	defer func() { testdb.DB.ConfigName = "" }()
+
+	_, err := testCheck("SelectPostgresTestBAD_JoinInferredNone", t)
+	e, ok := err.(*dbError)
+	if !ok {
+		t.Fatalf("got error %v, want *dbError", err)
+	}
+	if e.DB.ConfigName != "analytics" {
+		t.Errorf("got config name %q, want %q", e.DB.ConfigName, "analytics")
+	}
+	if msg := e.Error(); !strings.Contains(msg, "analytics") {
+		t.Errorf("got error message %q, want it to name the database", msg)
+	}
+}
+
+func TestModels(t *testing.T) {
+	models, err := testdb.DB.Models([]string{"test_auth.test_*", "test_coalesce"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range models {
+		got = append(got, m.Id.QualifiedName()+":"+m.GoName(true))
+	}
+	want := []string{
+		"test_auth.test_account:TestAccount",
+		"test_auth.test_session:TestSession",
+		"test_coalesce:TestCoalesce",
+	}
+	if e := compare.Compare(got, want); e != nil {
+		t.Error(e)
+	}
+
+	var fields []ModelField
+	for _, f := range models[2].Fields {
+		fields = append(fields, ModelField{Type: f.Type, IsNULLable: f.IsNULLable})
+	}
+	wantFields := []ModelField{
+		{Type: analysis.LiteralString, IsNULLable: true},
+		{Type: analysis.LiteralString, IsNULLable: false},
+		{Type: analysis.LiteralInt32, IsNULLable: true},
+	}
+	if e := compare.Compare(fields[:3], wantFields); e != nil {
+		t.Error(e)
+	}
+}
+
+func TestModelFieldTypes(t *testing.T) {
+	m, err := testdb.DB.Model("pgsql_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := make(map[string]analysis.LiteralType)
+	for _, f := range m.Fields {
+		types[f.Column.Name] = f.Type
+	}
+
+	tests := []struct {
+		col  string
+		want analysis.LiteralType
+	}{
+		{col: "col_numeric", want: analysis.LiteralString},
+		{col: "col_numericarr", want: analysis.LiteralStringSlice},
+		{col: "col_float8arr", want: analysis.LiteralFloat64Slice},
+	}
+	for _, tt := range tests {
+		if got := types[tt.col]; got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestModelUpsertTarget(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want *UpsertTarget
+	}{{
+		rel:  "test_onconflict",
+		want: &UpsertTarget{Name: "test_onconflict_key_idx", IsIndex: true},
+	}, {
+		rel:  "test_auth.test_account",
+		want: &UpsertTarget{Name: "test_account_pkey", IsPrimary: true},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			m, err := testdb.DB.Model(tt.rel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := m.UpsertTarget()
+			if got == nil {
+				t.Fatal("got nil upsert target")
+			}
+			if len(got.Fields) != 1 {
+				t.Errorf("got %d key fields, want 1", len(got.Fields))
+			}
+			got.Fields = nil
+			if e := compare.Compare(got, tt.want); e != nil {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	named, pos := testutil.FindNamedType("SelectPostgresTestBAD_Drift", tdata)
+	if named == nil {
+		t.Fatal("SelectPostgresTestBAD_Drift not found")
+	}
+	info, err := analysis.Run(tdata.Fset, named, pos, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := CheckDrift(testdb.DB, info.Struct, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range res.Drift {
+		got = append(got, string(d.Kind)+":"+d.Field+":"+d.Column)
+	}
+	want := []string{
+		"null_mismatch:A:col_a",
+		"type_mismatch:B:col_b",
+		"dropped_column:X:col_x",
+	}
+	if e := compare.Compare(got, want); e != nil {
+		t.Error(e)
+	}
+	if e := compare.Compare(res.Columns, []string{"col_a", "col_b", "col_c"}); e != nil {
+		t.Error(e)
+	}
+
+	mapped := map[string]bool{"col_a": true, "col_b": true, "col_c": true}
+	got = nil
+	for _, d := range CheckUnmappedColumns(testdb.DB, res.Relation, mapped) {
+		got = append(got, string(d.Kind)+":"+d.Column)
+	}
+	want = []string{"unmapped_column:col_d", "unmapped_column:col_e"}
+	if e := compare.Compare(got, want); e != nil {
+		t.Error(e)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	intType := analysis.TypeInfo{Kind: analysis.TypeKindInt}
+
+	tests := []struct {
+		query  string
+		params []*QueryParam
+		code   dbErrorCode
+	}{{
+		query:  `SELECT col_a FROM column_tests_1 WHERE col_a = $1`,
+		params: []*QueryParam{{Type: intType}},
+	}, {
+		query:  `SELECT col_a FROM column_tests_1 LIMIT $1`,
+		params: []*QueryParam{{}},
+	}, {
+		query: `SELECT col_a FROM column_tests_1 a, column_tests_1 b`,
+		code:  errQueryPrepare,
+	}, {
+		query:  `SELECT col_a FROM column_tests_1 WHERE col_b = $1`,
+		params: []*QueryParam{{Type: intType}},
+		code:   errQueryParamType,
+	}, {
+		query:  `SELECT col_a FROM column_tests_1 WHERE col_b = $1`,
+		params: []*QueryParam{{Type: intType, Valuer: "IntToText"}},
+	}}
+
+	info, err := testCheck("SelectPostgresTestOK_Simple", t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			err := Verify(testdb.DB, info, tt.query, tt.params)
+			if tt.code == 0 && err != nil {
+				t.Errorf("got error %v, want nil", err)
+			} else if e, ok := err.(*dbError); tt.code > 0 && (!ok || e.Code != tt.code) {
+				t.Errorf("got error %v, want code %d", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestCheckAdviseIndexes(t *testing.T) {
+	testdb.DB.Options.AdviseIndexes = true
+	defer func() { testdb.DB.Options.AdviseIndexes = false }()
+
+	tests := []struct {
+		name string
+		want []*Advice
+	}{{
+		name: "SelectPostgresTestOK_AdviseIndexes",
+		want: []*Advice{{
+			Kind:     AdviceNoExprIndex,
+			Target:   "path/to/test.SelectPostgresTestOK_AdviseIndexes",
+			File:     "../testdata/postgres_ok.go",
+			Line:     200,
+			Field:    "Email",
+			Relation: "public.test_user",
+			Columns:  []string{"email"},
+			Message:  `table "test_user" has no index on the expression lower(email)`,
+		}, {
+			Kind:     AdviceNoIndex,
+			Target:   "path/to/test.SelectPostgresTestOK_AdviseIndexes",
+			File:     "../testdata/postgres_ok.go",
+			Line:     201,
+			Field:    "IsActive",
+			Relation: "public.test_user",
+			Columns:  []string{"is_active"},
+			Message: `none of the filtered columns (is_active) of table "test_user"` +
+				` is the leading key column of any index`,
+		}, {
+			Kind:     AdviceNoIndex,
+			Target:   "path/to/test.SelectPostgresTestOK_AdviseIndexes",
+			File:     "../testdata/postgres_ok.go",
+			Line:     203,
+			Field:    "_",
+			Relation: "public.test_user",
+			Columns:  []string{"created_at"},
+			Message: `the rows are sorted by column "created_at" of table "test_user"` +
+				` which is not the leading key column of any index`,
+		}},
+	}, {
+		name: "SelectPostgresTestOK_AdviseIndexesCovered",
+		want: nil,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := testCheck(tt.name, t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e := compare.Compare(info.Advice, tt.want); e != nil {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		query   string
+		minRows int
+		want    []AdviceKind
+	}{{
+		query:   `SELECT id FROM test_user WHERE email = $1`,
+		minRows: 0,
+		want:    []AdviceKind{AdviceSeqScan},
+	}, {
+		query:   `SELECT id FROM test_user WHERE email = $1`,
+		minRows: 10000,
+		want:    nil,
+	}}
+
+	info, err := testCheck("SelectPostgresTestOK_Simple", t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			advice, err := Explain(testdb.DB, info, tt.query, tt.minRows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []AdviceKind
+			for _, adv := range advice {
+				got = append(got, adv.Kind)
+			}
+			if e := compare.Compare(got, tt.want); e != nil {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestCatalogCache(t *testing.T) {
+	dsn, dir := testdb.DB.dsn, t.TempDir()
+	rid := analysis.RelIdent{Name: "column_tests_1"}
+
+	// the in-memory cache would otherwise be used instead of the file
+	open := func() *DB {
+		catalogCache.Lock()
+		delete(catalogCache.m, dsn)
+		catalogCache.Unlock()
+
+		db, err := OpenCached(dsn, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	defer func() {
+		catalogCache.Lock()
+		catalogCache.m[dsn] = testdb.DB.catalog
+		catalogCache.Unlock()
+	}()
+
+	db := open()
+	want, err := loadRelation(&checker{db: db}, db, rid, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WriteCache(); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db = open()
+	got := db.catalog.Relations[rid]
+	if got == nil {
+		t.Fatalf("relation %q not read from cache", rid.Name)
+	}
+	if len(got.Columns) != len(want.Columns) {
+		t.Fatalf("got %d columns, want %d", len(got.Columns), len(want.Columns))
+	}
+	for i, col := range got.Columns {
+		if col.Name != want.Columns[i].Name || col.TypeOID != want.Columns[i].TypeOID {
+			t.Errorf("got column %q, want %q", col.Name, want.Columns[i].Name)
+		}
+		if col.Type == nil || col.Relation != got {
+			t.Errorf("column %q not relinked", col.Name)
+		}
+	}
+	db.Close()
+}
+
+func TestCheckConcurrent(t *testing.T) {
+	names := []string{
+		"SelectPostgresTestOK_Simple",
+		"SelectPostgresTestOK_StrictNullsView",
+		"InsertPostgresTestOK_GeneratedColumns",
+		"UpdatePostgresTestOK_IdentityReadOnly",
+	}
+
+	// drop the relations loaded by the other tests
+	// so that they are loaded by the concurrent checkers
+	testdb.DB.catalog.Lock()
+	for rid := range testdb.DB.catalog.Relations {
+		delete(testdb.DB.catalog.Relations, rid)
+	}
+	testdb.DB.catalog.Unlock()
+
+	errs := make([]error, len(names)*4)
+	wg := sync.WaitGroup{}
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = testCheck(names[i%len(names)], t)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("%s: %v", names[i%len(names)], err)
+		}
 	}
 }
 
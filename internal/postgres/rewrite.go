@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"strconv"
+
+	"github.com/frk/gosql/internal/postgres/oid"
+)
+
+// viewColumnRef is a column of a view that is a plain reference
+// to a column of one of the relations that the view selects from.
+type viewColumnRef struct {
+	// The number of the view's column.
+	Num int16
+	// The OID of the referenced relation.
+	RelId oid.OID
+	// The number of the referenced column.
+	RefNum int16
+}
+
+// parseViewColumnRefs parses the given query tree of a view's "_RETURN" rule,
+// as stored in the ev_action column of the pg_rewrite catalog, and returns the
+// view's columns that are plain references to the columns of the relations in
+// the view's FROM clause. Columns that are produced by expressions, or that are
+// selected from subqueries or functions, are not returned. Nothing is returned
+// for views whose query contains set operations, grouping sets, or outer joins,
+// since those can produce values, or NULLs, that are not in the base relations.
+func parseViewColumnRefs(action string) []viewColumnRef {
+	p := &nodeTreeParser{toks: tokenizeNodeTree(action)}
+	list, ok := p.value().([]interface{})
+	if !ok || len(list) != 1 {
+		return nil
+	}
+	q, ok := list[0].(*treeNode)
+	if !ok || q.name != "QUERY" {
+		return nil
+	}
+	if !q.isNull("setOperations") || !q.isNull("groupingSets") || hasOuterJoin(q.node("jointree")) {
+		return nil
+	}
+
+	rtable := q.list("rtable")
+	var refs []viewColumnRef
+	for _, v := range q.list("targetList") {
+		te, ok := v.(*treeNode)
+		if !ok || te.name != "TARGETENTRY" || te.atom("resjunk") == "true" {
+			continue
+		}
+		vr := te.node("expr")
+		if vr == nil || vr.name != "VAR" || vr.atom("varlevelsup") != "0" {
+			continue
+		}
+
+		varno, err1 := strconv.Atoi(vr.atom("varno"))
+		attno, err2 := strconv.Atoi(vr.atom("varattno"))
+		resno, err3 := strconv.Atoi(te.atom("resno"))
+		if err1 != nil || err2 != nil || err3 != nil || varno < 1 || varno > len(rtable) || attno < 1 {
+			continue
+		}
+
+		// only the entries of kind RTE_RELATION (0)
+		// reference the columns of a relation
+		rte, ok := rtable[varno-1].(*treeNode)
+		if !ok || rte.name != "RANGETBLENTRY" || rte.atom("rtekind") != "0" {
+			continue
+		}
+		relid, err := strconv.ParseUint(rte.atom("relid"), 10, 32)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, viewColumnRef{Num: int16(resno), RelId: oid.OID(relid), RefNum: int16(attno)})
+	}
+	return refs
+}
+
+// hasOuterJoin reports whether or not the given part of a query
+// tree contains a join expression whose type is not JOIN_INNER (0).
+func hasOuterJoin(v interface{}) bool {
+	switch v := v.(type) {
+	case *treeNode:
+		if v == nil {
+			return false
+		}
+		if v.name == "JOINEXPR" && v.atom("jointype") != "0" {
+			return true
+		}
+		for _, vals := range v.fields {
+			for _, val := range vals {
+				if hasOuterJoin(val) {
+					return true
+				}
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if hasOuterJoin(val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// treeNode is a node of a query tree in the text form in which the query trees
+// are stored in the system catalogs, e.g. "{VAR :varno 1 :varattno 2 ...}". The
+// values of the node's fields are nodes, lists, or atoms, i.e. *treeNode,
+// []interface{}, or string.
+type treeNode struct {
+	name   string
+	fields map[string][]interface{}
+}
+
+// node returns the node value of the named field, or nil.
+func (n *treeNode) node(key string) *treeNode {
+	if vals := n.fields[key]; len(vals) == 1 {
+		if v, ok := vals[0].(*treeNode); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// list returns the list value of the named field, or nil.
+func (n *treeNode) list(key string) []interface{} {
+	if vals := n.fields[key]; len(vals) == 1 {
+		if v, ok := vals[0].([]interface{}); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// atom returns the atom value of the named field, or "".
+func (n *treeNode) atom(key string) string {
+	if vals := n.fields[key]; len(vals) == 1 {
+		if v, ok := vals[0].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// isNull reports whether or not the named field is
+// absent from the node or whether its value is NULL.
+func (n *treeNode) isNull(key string) bool {
+	vals, ok := n.fields[key]
+	return !ok || (len(vals) == 1 && vals[0] == "<>")
+}
+
+// nodeTreeParser parses the tokens of a query tree.
+type nodeTreeParser struct {
+	toks []string
+	pos  int
+}
+
+func (p *nodeTreeParser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *nodeTreeParser) peek() string {
+	if p.eof() {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *nodeTreeParser) next() string {
+	tok := p.peek()
+	p.pos += 1
+	return tok
+}
+
+// value parses the next node, list, or atom.
+func (p *nodeTreeParser) value() interface{} {
+	switch tok := p.next(); tok {
+	case "{":
+		n := &treeNode{name: p.next(), fields: make(map[string][]interface{})}
+		for !p.eof() && p.peek() != "}" {
+			key := p.next()
+			var vals []interface{}
+			for !p.eof() && p.peek() != "}" && !isNodeTreeKey(p.peek()) {
+				vals = append(vals, p.value())
+			}
+			if isNodeTreeKey(key) {
+				n.fields[key[1:]] = vals
+			}
+		}
+		p.next()
+		return n
+	case "(":
+		list := []interface{}{}
+		for !p.eof() && p.peek() != ")" {
+			list = append(list, p.value())
+		}
+		p.next()
+		return list
+	default:
+		return tok
+	}
+}
+
+// isNodeTreeKey reports whether or not the token is the key of a node's field.
+func isNodeTreeKey(tok string) bool {
+	return len(tok) > 1 && tok[0] == ':'
+}
+
+// tokenizeNodeTree splits the given query tree into tokens. The braces and
+// parentheses are tokens of their own, the other tokens are separated by
+// whitespace. Backslash escaped characters are kept, together with their
+// backslash, in the token so that they are never mistaken for structure.
+func tokenizeNodeTree(s string) (toks []string) {
+	isDelim := func(c byte) bool {
+		return c == ' ' || c == '\n' || c == '\t' || c == '(' || c == ')' || c == '{' || c == '}'
+	}
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\n' || c == '\t':
+			i += 1
+		case isDelim(c):
+			toks = append(toks, s[i:i+1])
+			i += 1
+		default:
+			j := i
+			for j < len(s) && !isDelim(s[j]) {
+				if s[j] == '\\' && j+1 < len(s) {
+					j += 1
+				}
+				j += 1
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks
+}
@@ -6,6 +6,9 @@ import (
 	"log"
 )
 
+// The role whose privileges are granted by the test database.
+const testRuntimeRole = "gosql_test_runtime_role"
+
 type TestDB struct {
 	root *sql.DB
 	DB   *DB
@@ -28,6 +31,14 @@ func (t *TestDB) Init() (err error) {
 		return err
 	}
 
+	// create the role used by the runtime role privilege checks
+	if _, err = t.root.Exec("DROP ROLE IF EXISTS " + testRuntimeRole); err != nil {
+		return err
+	}
+	if _, err = t.root.Exec("CREATE ROLE " + testRuntimeRole); err != nil {
+		return err
+	}
+
 	// populate test db
 	const populatedbquery = `
 CREATE TABLE relation_test (
@@ -142,6 +153,45 @@ CREATE TABLE test_post (
 	, created_at timestamptz not null
 );
 
+CREATE TABLE test_soft_delete (
+	id serial primary key
+	, user_id integer not null REFERENCES test_user (id)
+	, content text not null
+	, deleted_at timestamptz
+);
+
+CREATE TYPE test_address AS (
+	street text
+	, city text
+	, zip integer
+);
+
+CREATE TABLE test_composite (
+	id serial primary key
+	, address test_address
+	, addresses test_address[]
+);
+
+CREATE DOMAIN test_email AS text CHECK (VALUE LIKE '%@%');
+CREATE DOMAIN test_email_nn AS test_email NOT NULL;
+CREATE DOMAIN test_flag AS char(1);
+
+CREATE TABLE test_domains (
+	id serial primary key
+	, email test_email
+	, backup_email test_email_nn
+	, flag test_flag
+	, emails test_email[]
+);
+
+CREATE TABLE test_generated (
+	id int generated always as identity primary key
+	, seq int generated by default as identity
+	, price numeric not null
+	, qty int not null
+	, total numeric generated always as (price * qty) stored
+);
+
 CREATE TABLE test_nested (
 	foo_bar_baz_val text not null default ''
 	, foo_baz_val text not null default ''
@@ -169,6 +219,53 @@ CREATE TABLE test_join4 (
 	, join3_id integer not null REFERENCES test_join3 (id)
 );
 
+CREATE TABLE test_join_message (
+	id serial primary key
+	, sender_id integer not null REFERENCES test_user (id)
+	, recipient_id integer not null REFERENCES test_user (id)
+);
+
+CREATE TABLE test_join_node (
+	id serial primary key
+	, parent_id integer REFERENCES test_join_node (id)
+);
+
+CREATE TABLE test_join_pair (
+	id serial primary key
+	, left_id integer REFERENCES test_join_pair (id)
+	, right_id integer REFERENCES test_join_pair (id)
+);
+
+CREATE TABLE test_place (
+	id serial primary key
+	, name text not null
+	, location point
+);
+
+CREATE SCHEMA test_billing;
+CREATE SCHEMA test_auth;
+CREATE SCHEMA test_shop;
+
+CREATE TABLE test_billing.test_invoice (
+	id serial primary key
+	, account_id integer not null
+);
+
+CREATE TABLE test_auth.test_account (
+	id serial primary key
+	, email text not null
+);
+
+CREATE TABLE test_shop.test_account (
+	id serial primary key
+	, email text not null
+);
+
+CREATE TABLE test_auth.test_session (
+	id serial primary key
+	, account_id integer not null
+);
+
 CREATE TABLE test_coalesce (
 	col_a text
 	, col_b text not null
@@ -186,6 +283,38 @@ CREATE VIEW view_test AS SELECT
 	, (length(col_b) > 0) AS col_z
 FROM column_tests_1;
 
+CREATE VIEW view_test_outer AS SELECT
+	a.col_a
+	, a.col_b
+	, b.col_baz
+FROM column_tests_1 a
+LEFT JOIN column_tests_2 b ON b.col_conkey1 = a.col_b;
+
+CREATE VIEW view_test_nullif AS SELECT
+	col_a
+	, NULLIF(col_b, '') AS col_b
+FROM column_tests_1;
+
+CREATE VIEW view_test_case AS SELECT
+	col_a
+	, CASE WHEN col_c THEN col_b END AS col_b
+FROM column_tests_1;
+
+CREATE VIEW view_test_union AS SELECT
+	col_a
+	, col_b
+FROM column_tests_1
+UNION ALL SELECT
+	col_a
+	, NULL
+FROM column_tests_1;
+
+CREATE VIEW view_test_alias AS SELECT
+	col_a AS id
+	, col_b AS name
+	, col_c AS col_b
+FROM column_tests_1;
+
 CREATE TABLE test_onconflict (
 	id serial primary key
 	, key int4
@@ -210,6 +339,37 @@ CREATE TABLE test_composite_pkey (
 	, PRIMARY KEY (id, key, name)
 );
 
+CREATE TABLE test_partitioned (
+	id int not null
+	, region text not null
+	, name text
+	, PRIMARY KEY (id, region)
+) PARTITION BY LIST (region);
+
+CREATE TABLE test_partitioned_eu PARTITION OF test_partitioned FOR VALUES IN ('eu');
+CREATE TABLE test_partitioned_us PARTITION OF test_partitioned FOR VALUES IN ('us');
+
+CREATE MATERIALIZED VIEW matview_test AS SELECT
+	col_a
+	, col_b
+FROM column_tests_1;
+
+GRANT SELECT (col_a, col_b) ON column_tests_1 TO gosql_test_runtime_role;
+
+CREATE FOREIGN DATA WRAPPER test_fdw;
+CREATE SERVER test_fdw_server FOREIGN DATA WRAPPER test_fdw;
+CREATE FOREIGN TABLE test_foreign (
+	id int not null
+	, name text
+) SERVER test_fdw_server;
+
+CREATE EXTENSION postgres_fdw;
+CREATE SERVER test_postgres_fdw_server FOREIGN DATA WRAPPER postgres_fdw;
+CREATE FOREIGN TABLE test_foreign_updatable (
+	id int not null
+	, name text
+) SERVER test_postgres_fdw_server;
+
 CREATE FUNCTION increment(i integer) RETURNS integer AS $$
 BEGIN
 	RETURN i + 1;
@@ -358,6 +518,9 @@ func (t *TestDB) Close() {
 		if _, err := t.root.Exec("DROP DATABASE " + t.DB.name); err != nil {
 			log.Println("error dropping test db:", err)
 		}
+		if _, err := t.root.Exec("DROP ROLE " + testRuntimeRole); err != nil {
+			log.Println("error dropping test role:", err)
+		}
 		if err := t.root.Close(); err != nil {
 			log.Println("error closing root db handle:", err)
 		}
@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/frk/gosql/internal/analysis"
 	"github.com/frk/gosql/internal/postgres/oid"
@@ -22,6 +24,81 @@ type (
 		ColIdent analysis.ColIdent
 		// The name of the valuer to be used for writing the column, or empty.
 		Valuer string
+		// If the column is of a composite type, or an array of a composite
+		// type, CompositeFields holds the names of the struct fields that
+		// are mapped to the composite's attributes in the attributes' order,
+		// an attribute with no field mapped to it is represented by "".
+		CompositeFields []string
+	}
+
+	// Advice holds a note produced by the index advisor on a query that may
+	// not be able to use an index. Advice does not prevent code generation.
+	Advice struct {
+		// The kind of the advice.
+		Kind AdviceKind `json:"kind"`
+		// The package path and name of the target type.
+		Target string `json:"target"`
+		// The file and line of the field that the advice is about,
+		// or of the target type if the advice is about the whole query.
+		File string `json:"file"`
+		Line int    `json:"line"`
+		// The name of the field, or empty.
+		Field string `json:"field,omitempty"`
+		// The relation that the advice is about.
+		Relation string `json:"relation"`
+		// The columns that the advice is about, if any.
+		Columns []string `json:"columns,omitempty"`
+		// A human-readable description of the advice.
+		Message string `json:"message"`
+	}
+
+	// Drift holds a difference, found by CheckDrift or by CheckUnmappedColumns,
+	// between a relation of the database and the struct types mapped to it.
+	Drift struct {
+		// The kind of the drift.
+		Kind DriftKind `json:"kind"`
+		// The package path and name of the target type, or empty
+		// if the drift is about a column that no field is mapped to.
+		Target string `json:"target,omitempty"`
+		// The file and line of the field that the drift is about, or
+		// of the target type if the drift is about the target's relation.
+		File string `json:"file,omitempty"`
+		Line int    `json:"line,omitempty"`
+		// The name of the field, or empty.
+		Field string `json:"field,omitempty"`
+		// The config name of the database, or empty for the default database.
+		Database string `json:"database,omitempty"`
+		// The relation that the drift is about.
+		Relation string `json:"relation"`
+		// The column that the drift is about, or empty.
+		Column string `json:"column,omitempty"`
+		// A human-readable description of the drift.
+		Message string `json:"message"`
+		// The ALTER TABLE statements, or the struct field patches,
+		// that would resolve the drift, if any.
+		Suggestions []string `json:"suggestions,omitempty"`
+	}
+
+	// DriftInfo holds the result of CheckDrift.
+	DriftInfo struct {
+		// The target's relation, or nil if the relation was dropped.
+		Relation *Relation
+		// The names of the relation's columns that are mapped to the target's fields.
+		Columns []string
+		// The drift found between the target's fields and the relation.
+		Drift []*Drift
+	}
+
+	// QueryParam holds the information on a parameter of a generated
+	// query that is needed to verify the parameter's type.
+	QueryParam struct {
+		// The analyzed field whose value is passed as the parameter.
+		Field analysis.FieldPtr
+		// Type info of the Go value passed as the parameter, the zero
+		// value if the type is not known.
+		Type analysis.TypeInfo
+		// The name of the valuer used to convert the Go value, or empty.
+		Valuer string
 	}
 
 	// FieldRead holds the information needed by the generator to produce the
@@ -35,6 +112,9 @@ type (
 		ColIdent analysis.ColIdent
 		// The name of the scanner to be used for reading the column, or empty.
 		Scanner string
+		// The names of the struct fields into which the attributes of
+		// a composite type column will be read, see FieldWrite.
+		CompositeFields []string
 	}
 
 	// FieldFilter ...
@@ -49,6 +129,22 @@ type (
 		Valuer string
 	}
 
+	// FieldOrder holds the information needed by the generator to produce an
+	// ORDER BY item that orders by the result of an operator applied to a column
+	// and to the value of a field, e.g. "ORDER BY location <-> $1".
+	FieldOrder struct {
+		// The analyzed field whose value is the operator's right operand.
+		Field *analysis.OrderByParamField
+		// The identifier of the column that is the operator's left operand.
+		ColIdent analysis.ColIdent
+		// The column that is the operator's left operand.
+		Column *Column
+		// The name of the operator.
+		Operator string
+		// The name of the valuer to be used for converting the field value.
+		Valuer string
+	}
+
 	// Boolean
 	Boolean struct {
 		Value analysis.Boolean
@@ -66,12 +162,16 @@ type (
 		Column *Column
 		// The type of the predicate, or 0.
 		Predicate analysis.Predicate
+		// The name of the custom operator, or empty.
+		Operator string
 		// The predicate quantifier, or 0.
 		Quantifier analysis.Quantifier
 		// Name of the modifier function, or empty.
 		FuncName analysis.FuncName
 		// Name of the valuer to be employed, or empty.
 		Valuer string
+		// The analyzed field from which the conditional was produced.
+		Field analysis.FieldPtr
 	}
 
 	// ColumnConditional holds the information needed by the generator
@@ -91,6 +191,8 @@ type (
 		RHSType *Type
 		// The type of the predicate, or 0.
 		Predicate analysis.Predicate
+		// The name of the custom operator, or empty.
+		Operator string
 		// The predicate quantifier, or 0.
 		Quantifier analysis.Quantifier
 	}
@@ -157,8 +259,15 @@ type (
 
 		// populated by loadRelation
 		Relations map[analysis.RelIdent]*Relation
-		// sync.RWMutex is necessary to be used only for the Relations map,
-		// the rest is read-only once initialized.
+		// The fingerprints of the loaded relations, mapped by their OIDs,
+		// used to invalidate the cached relations that have been modified.
+		relFingerprints map[oid.OID]string
+		// The fingerprint of the catalog's objects, set only if
+		// the catalog is read from, or written to, a cache file.
+		fingerprint string
+		// sync.RWMutex is necessary to be used only for the Relations and
+		// relFingerprints maps, the rest is read-only once initialized,
+		// and so is each Relation once it has been added to the map.
 		sync.RWMutex
 	}
 
@@ -188,7 +297,7 @@ type (
 		Name string
 		// The name of the schema to which the relation belongs.
 		Schema string
-		// The relation's kind, we're only interested in r, v, and m.
+		// The relation's kind, we're only interested in r, v, m, f, and p.
 		RelKind RelKind
 		// List of columns associated with the relation.
 		Columns []*Column
@@ -196,6 +305,10 @@ type (
 		Constraints []*Constraint
 		// List of indexes applied to the relation.
 		Indexes []*Index
+		// The name of the foreign-data wrapper of a foreign table.
+		ForeignDataWrapper string
+		// The commands supported by a foreign table's foreign-data wrapper.
+		Events RelEvents
 	}
 
 	// Column holds the info of a "pg_attribute" entry that represents
@@ -216,12 +329,17 @@ type (
 		// calculate the scale use (typmod - 4) && 65535
 		TypeMod int
 		// Indicates whether or not the column has a NOT NULL constraint.
-		// NOTE(mkopriva): this is ambiguous if the column's relation is a view.
+		// If the column's relation is a view the constraint is inferred
+		// from the columns of the view's base tables, if possible.
 		HasNotNull bool
 		// Indicates whether or not the column has a DEFAULT value.
 		HasDefault bool
 		// Reports whether or not the column is a primary key.
 		IsPrimary bool
+		// Indicates whether or not the column is a stored generated column.
+		IsGenerated bool
+		// The identity kind of the column, empty if the column is not an identity column.
+		Identity ColumnIdentity
 		// The number of dimensions if the column is an array type, otherwise 0.
 		NumDims int
 		// The OID of the column's type.
@@ -252,6 +370,22 @@ type (
 		// If this is an array type then elem identifies the element type
 		// of that array type.
 		Elem oid.OID
+		// If this is not an array type then array identifies the array
+		// type that has this type as its element type, or 0.
+		Array oid.OID
+		// The labels of an enum type in their sort order, nil for other types.
+		EnumLabels []string
+		// The attributes of a composite type in their declared order,
+		// nil for other types.
+		Attributes []*Column
+		// If this is a domain then BaseType identifies the type on which
+		// this domain is based, otherwise 0.
+		BaseType oid.OID
+		// The typmod that a domain applies to its base type,
+		// -1 if the base type does not use a typmod.
+		TypeMod int
+		// Indicates whether or not a domain has a NOT NULL constraint.
+		NotNull bool
 	}
 
 	// Index holds the info of a "pg_index" entry that represents a table's index.
@@ -307,6 +441,8 @@ type (
 		Key []int64
 		// If a foreign key, list of the referenced columns
 		FKey []int64
+		// If a foreign key, the object identifier of the referenced table
+		FRelOID oid.OID
 	}
 
 	// Operator holds info on a "pg_operator" entry.
@@ -382,6 +518,22 @@ func (t Type) ZeroValueLiteral() (lit string, ok bool) {
 	return lit, ok
 }
 
+// EnumGoName returns the name of the Go type that is generated for the
+// enum type t by the "gosql enums" command, e.g. "order_status" => "OrderStatus".
+func (t *Type) EnumGoName() string {
+	return goIdent(t.Name)
+}
+
+// EnumLabelGoName returns the name of the Go constant that is generated
+// for the given label of the enum type t, e.g. "in_progress" => "OrderStatusInProgress".
+func (t *Type) EnumLabelGoName(label string) string {
+	name := goIdent(label)
+	if len(name) == 0 {
+		name = "Empty"
+	}
+	return t.EnumGoName() + name
+}
+
 func (t *Type) GetNameFmt() string {
 	if t == nil {
 		return "<nil>"
@@ -390,8 +542,15 @@ func (t *Type) GetNameFmt() string {
 }
 
 func (c *Column) IsNULLable() bool {
-	return c.Relation != nil && c.Relation.RelKind == RelKindOrdinaryTable &&
-		c.HasNotNull == false
+	return c.Relation != nil && c.Relation.IsTable() && c.HasNotNull == false
+}
+
+// IsTable reports whether or not the relation is a table, i.e. an ordinary,
+// partitioned, or foreign table, whose columns' NOT NULL constraints are known.
+func (r *Relation) IsTable() bool {
+	return r.RelKind == RelKindOrdinaryTable ||
+		r.RelKind == RelKindPartitionedTable ||
+		r.RelKind == RelKindForeignTable
 }
 
 func (f *FieldFilter) TreatAsNULLable() bool {
@@ -401,6 +560,7 @@ func (f *FieldFilter) TreatAsNULLable() bool {
 // TableJoinConditional implementations
 func (*Boolean) tableJoinConditional()           {}
 func (*ColumnConditional) tableJoinConditional() {}
+func (*NestedConditional) tableJoinConditional() {}
 
 // WhereConditional implementations
 func (*Boolean) whereConditional()            {}
@@ -416,3 +576,23 @@ func (*ColumnConditional) rangeBound() {}
 // ConflictTarget implementations
 func (*ConflictIndex) conflictTarget()      {}
 func (*ConflictConstraint) conflictTarget() {}
+
+// goIdent converts the given postgres identifier into an exported Go
+// identifier by removing any non-alphanumeric characters and upper-casing
+// the first letter of each of the remaining words.
+func goIdent(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+
+	"github.com/frk/gosql/internal/analysis"
+	"github.com/frk/gosql/internal/postgres/oid"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// The name of the prepared statement used by Verify.
+	verifyStmtName = "gosql_verify"
+	// The prefix added by prepareStmt to the query to be prepared.
+	verifyPrefix = `PREPARE ` + verifyStmtName + ` AS `
+)
+
+// Verify sends the given query, generated for the given target, to the server
+// as a prepared statement to catch the errors that the type checker does not
+// detect, e.g. ambiguous column references or unresolvable operators. The
+// statement is prepared inside a transaction that is always rolled back.
+//
+// Once the statement is prepared, the types that the server inferred for the
+// statement's parameters are compared to the Go types of the given params, where
+// params[i] is the parameter referenced in the query by the ordinal $(i+1).
+func Verify(db *DB, info *TargetInfo, query string, params []*QueryParam) error {
+	c := &checker{db: db, info: info.Info}
+
+	stmt, err := prepareStmt(db, verifyStmtName, query)
+	if err != nil {
+		var ptr analysis.FieldPtr
+		if num := errorParamNum(err, query); num > 0 && num <= len(params) {
+			ptr = params[num-1].Field
+		}
+		return c.dbError(dbError{Code: errQueryPrepare, Query: query, Err: err}, ptr)
+	}
+	defer stmt.close()
+
+	const selectParamTypes = `SELECT
+		u.typ::oid
+	FROM pg_prepared_statements s
+	CROSS JOIN unnest(s.parameter_types) WITH ORDINALITY AS u(typ, num)
+	WHERE s.name = $1
+	ORDER BY u.num` //`
+	rows, err := stmt.Query(selectParamTypes, verifyStmtName)
+	if err != nil {
+		return c.dbError(dbError{Code: errQueryPrepare, Query: query, Err: err}, nil)
+	}
+	defer rows.Close()
+
+	var oids []oid.OID
+	for rows.Next() {
+		var id oid.OID
+		if err := rows.Scan(&id); err != nil {
+			return c.dbError(dbError{Code: errQueryPrepare, Query: query, Err: err}, nil)
+		}
+		oids = append(oids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return c.dbError(dbError{Code: errQueryPrepare, Query: query, Err: err}, nil)
+	}
+
+	for i, id := range oids {
+		if i >= len(params) {
+			break
+		}
+		if typ := db.catalog.Types[id]; typ != nil && !isParamTypeAllowed(c, typ, params[i]) {
+			return c.dbError(dbError{Code: errQueryParamType, Query: query,
+				Param: paramInfo{Num: i + 1, Type: typ}}, params[i].Field)
+		}
+	}
+	return nil
+}
+
+// preparedStmt is a statement that was prepared by prepareStmt together
+// with the transaction, and the connection, in which it was prepared.
+type preparedStmt struct {
+	*sql.Tx
+	conn *sql.Conn
+	name string
+}
+
+// prepareStmt prepares the given query as the named statement inside a new
+// transaction on a connection that is dedicated to the statement. The returned
+// value's close method MUST be called once the statement is no longer needed.
+func prepareStmt(db *DB, name, query string) (*preparedStmt, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := tx.Exec(`PREPARE ` + name + ` AS ` + query); err != nil {
+		tx.Rollback()
+		conn.Close()
+		return nil, err
+	}
+	return &preparedStmt{Tx: tx, conn: conn, name: name}, nil
+}
+
+// close rolls back the statement's transaction and deallocates the statement.
+// A prepared statement outlives the transaction in which it was prepared, even
+// one that was aborted, so it is deallocated only after the rollback, on the same
+// connection. If that fails the connection is discarded instead of being returned
+// to the pool, so that the statement's name cannot clash with a later PREPARE.
+func (s *preparedStmt) close() {
+	s.Tx.Rollback()
+	if _, err := s.conn.ExecContext(context.Background(), `DEALLOCATE `+s.name); err != nil {
+		s.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	s.conn.Close()
+}
+
+// isParamTypeAllowed reports whether or not the Go value described by
+// the given QueryParam can be passed as a parameter of the given type.
+// Parameters whose value is converted by a valuer, or whose Go type is
+// not known, are not checked.
+func isParamTypeAllowed(c *checker, typ *Type, p *QueryParam) bool {
+	if p.Type.Kind == analysis.TypeKindInvalid || len(p.Valuer) > 0 {
+		return true
+	}
+	if p.Type.ImplementsValuer() || p.Type.IsEmptyInterface {
+		return true
+	}
+	if typ.Type == TypeTypePseudo || typ.OID == oid.Unknown {
+		return true
+	}
+
+	comp := typeCompatibility(c, typ, p.Type, false)
+	return comp != nil && len(comp.valuer) == 0
+}
+
+// errorParamNum returns the ordinal number of the parameter at the position
+// reported by the given server error, or 0 if the error reports no position
+// or if there is no parameter reference at that position.
+func errorParamNum(err error, query string) int {
+	e, ok := err.(*pq.Error)
+	if !ok || len(e.Position) == 0 {
+		return 0
+	}
+	pos, perr := strconv.Atoi(e.Position)
+	if perr != nil {
+		return 0
+	}
+
+	// The position is 1-based, in characters, and relative
+	// to the statement sent to the server, i.e. the prefixed query.
+	pos -= len(verifyPrefix) + 1
+	runes := []rune(query)
+	if pos < 0 || pos >= len(runes) || runes[pos] != '$' {
+		return 0
+	}
+
+	j := pos + 1
+	for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+		j++
+	}
+	num, _ := strconv.Atoi(string(runes[pos+1 : j]))
+	return num
+}
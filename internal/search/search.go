@@ -1,13 +1,16 @@
 package search
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -37,6 +40,9 @@ type Match struct {
 	Named *types.Named
 	// The source position of the matched type.
 	Pos token.Pos
+	// The name of the database specified by the type's "gosql:db"
+	// directive, or empty if the type has no such directive.
+	DB string
 }
 
 // File represents a Go file that contains one or more matching query struct types.
@@ -46,13 +52,16 @@ type File struct {
 	Matches []*Match
 }
 
-// Package represents a Go package that contains one or more matching query struct types.
+// Package represents a Go package whose files were searched for matching query struct types.
 type Package struct {
 	Name  string
 	Path  string
 	Fset  *token.FileSet
 	Info  *types.Info
 	Files []*File
+	// The paths of all the package's files that were searched,
+	// including those that do not contain any matching types.
+	Searched []string
 }
 
 // Search
@@ -95,6 +104,7 @@ func Search(dir string, recursive bool, filter func(filePath string) bool) (out
 			if filePath := pkg.CompiledGoFiles[i]; !filter(filePath) {
 				continue
 			}
+			p.Searched = append(p.Searched, pkg.CompiledGoFiles[i])
 
 			f := new(File)
 			f.Path = pkg.CompiledGoFiles[i]
@@ -127,6 +137,9 @@ func Search(dir string, recursive bool, filter func(filePath string) bool) (out
 					match := new(Match)
 					match.Named = named
 					match.Pos = typeName.Pos()
+					if match.DB = dbDirective(typeSpec.Doc); len(match.DB) == 0 {
+						match.DB = dbDirective(gd.Doc)
+					}
 					f.Matches = append(f.Matches, match)
 				}
 			}
@@ -137,8 +150,8 @@ func Search(dir string, recursive bool, filter func(filePath string) bool) (out
 			}
 		}
 
-		// add package only if it has files with matches
-		if len(p.Files) > 0 {
+		// add package only if any of its files were searched
+		if len(p.Searched) > 0 {
 			out = append(out, p)
 		}
 	}
@@ -157,3 +170,56 @@ func hasIgnoreDirective(doc *ast.CommentGroup) bool {
 	}
 	return false
 }
+
+// dbDirective returns the name of the database specified by the "gosql:db"
+// directive in the given documentation, e.g. "//gosql:db analytics", or empty.
+func dbDirective(doc *ast.CommentGroup) string {
+	if doc != nil {
+		for _, com := range doc.List {
+			text := strings.TrimSpace(strings.TrimPrefix(com.Text, "//"))
+			if name, ok := strings.CutPrefix(text, "gosql:db "); ok {
+				return strings.TrimSpace(name)
+			}
+		}
+	}
+	return ""
+}
+
+// PackagePath returns the import path of the Go package in the given directory.
+// If the directory does not contain any Go files, the import path is derived from
+// the path of the module that contains the directory.
+func PackagePath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	loadConfig := new(packages.Config)
+	loadConfig.Mode = packages.NeedName
+	loadConfig.Dir = dir
+	pkgs, err := packages.Load(loadConfig, ".")
+	if err == nil && len(pkgs) == 1 && strings.Contains(pkgs[0].PkgPath, "/") {
+		return pkgs[0].PkgPath, nil
+	}
+
+	for mod := dir; ; mod = filepath.Dir(mod) {
+		if data, err := os.ReadFile(filepath.Join(mod, "go.mod")); err == nil {
+			path := modfile.ModulePath(data)
+			if len(path) == 0 {
+				break
+			}
+			rel, err := filepath.Rel(mod, dir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return path, nil
+			}
+			return path + "/" + filepath.ToSlash(rel), nil
+		}
+		if mod == filepath.Dir(mod) {
+			break
+		}
+	}
+	return "", fmt.Errorf("failed to resolve the package path of directory: %q", dir)
+}
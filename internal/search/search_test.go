@@ -0,0 +1,86 @@
+package search
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frk/compare"
+)
+
+func TestPackagePath(t *testing.T) {
+	// a directory of the module that does not contain any Go files
+	empty := filepath.Join("..", "testdata", "generator", "empty_pkgpath")
+	if err := os.MkdirAll(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(empty)
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: ".", want: "github.com/frk/gosql/internal/search"},
+		{dir: "../testdata/common", want: "github.com/frk/gosql/internal/testdata/common"},
+		{dir: "../../", want: "github.com/frk/gosql"},
+		{dir: empty, want: "github.com/frk/gosql/internal/testdata/generator/empty_pkgpath"},
+	}
+	for _, tt := range tests {
+		got, err := PackagePath(tt.dir)
+		if err != nil {
+			t.Errorf("%s: %v", tt.dir, err)
+			continue
+		}
+		if e := compare.Compare(got, tt.want); e != nil {
+			t.Errorf("%s: %v", tt.dir, e)
+		}
+	}
+}
+
+func TestDBDirective(t *testing.T) {
+	tests := []struct {
+		src string
+		// the directive in the doc of the type spec and of the gen decl
+		spec, decl string
+	}{{
+		src:  "//gosql:db analytics\ntype T struct{}",
+		decl: "analytics",
+	}, {
+		src:  "type (\n\t//gosql:db analytics\n\tT struct{}\n)",
+		spec: "analytics",
+	}, {
+		src:  "//gosql:db main\ntype (\n\t//gosql:db analytics\n\tT struct{}\n)",
+		spec: "analytics",
+		decl: "main",
+	}, {
+		src:  "// T is a query.\n//\n//gosql:db  analytics \ntype T struct{}",
+		decl: "analytics",
+	}, {
+		src: "//gosql:db\ntype T struct{}",
+	}, {
+		src: "//gosql:dbanalytics\ntype T struct{}",
+	}, {
+		src: "/* gosql:db analytics */\ntype T struct{}",
+	}, {
+		src: "// T is a query for the gosql:db analytics database.\ntype T struct{}",
+	}}
+	for _, tt := range tests {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", "package p\n\n"+tt.src+"\n", parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gd := file.Decls[0].(*ast.GenDecl)
+		spec := gd.Specs[0].(*ast.TypeSpec)
+
+		if got := dbDirective(spec.Doc); got != tt.spec {
+			t.Errorf("%q: type spec got %q, want %q", tt.src, got, tt.spec)
+		}
+		if got := dbDirective(gd.Doc); got != tt.decl {
+			t.Errorf("%q: gen decl got %q, want %q", tt.src, got, tt.decl)
+		}
+	}
+}
@@ -993,3 +993,64 @@ type FilterAnalysisTestBAD_ConflictingFilterConstructor struct {
 	common.FilterMaker
 	maker common.FilterMaker
 }
+
+// BAD: Insert with the WithDeleted directive
+type InsertAnalysisTestBAD_WithDeleted struct {
+	Rel T `rel:"relation_a:a"`
+	_   gosql.WithDeleted
+}
+
+// BAD: Insert with bad autonow option in dataType field's tag
+type InsertAnalysisTestBAD_BadAutoNowOption struct {
+	Rel struct {
+		Foo string `sql:"foo,autonow=delete"`
+	} `rel:"relation_a:a"`
+}
+
+// BAD: Select with empty expression in dataType field's tag
+type SelectAnalysisTestBAD_EmptyFieldExpr struct {
+	Rel struct {
+		Foo string `sql:"="`
+	} `rel:"relation_a:a"`
+}
+
+// BAD: Select with unknown window function in dataType field's tag
+type SelectAnalysisTestBAD_BadWindowFunc struct {
+	Rel struct {
+		Foo int64 `sql:"@sum(a.foo),over"`
+	} `rel:"relation_a:a"`
+}
+
+// BAD: Select with "totalcount" field and single-record relation
+type SelectAnalysisTestBAD_IllegalTotalCountField struct {
+	Rel        T `rel:"relation_a:a"`
+	TotalCount int
+}
+
+// BAD: Select with "many" option on a non-slice dataType field
+type SelectAnalysisTestBAD_BadManyFieldType struct {
+	Rel struct {
+		Foo T `sql:"relation_b:b,many,b.a_id = a.id"`
+	} `rel:"relation_a:a"`
+}
+
+// BAD: Select with an order by operator whose operand is not a field
+type SelectAnalysisTestBAD_BadOrderByDirectiveOperatorParam struct {
+	Rel []T           `rel:"relation_a:a"`
+	_   gosql.OrderBy `sql:"a.location <-> $Point"`
+}
+
+// BAD: Select with a modifier function in a custom operator's tag
+type SelectAnalysisTestBAD_IllegalOperatorModifier struct {
+	Rel   []T `rel:"relation_a:a"`
+	Where struct {
+		Name string `sql:"a.name %,@lower"`
+	}
+}
+
+// BAD: Select with a star argument to a window function that requires a column
+type SelectAnalysisTestBAD_BadWindowFuncStarArg struct {
+	Rel struct {
+		Foo int64 `sql:"@lag(*),over"`
+	} `rel:"relation_a:a"`
+}
@@ -632,3 +632,102 @@ type SelectAnalysisTestOK_WithContextNamed struct {
 	Ctx     context.Context
 	UserRec *common.User `rel:"users_table"`
 }
+
+type softDeleteRecord struct {
+	_  gosql.SoftDelete `sql:"deleted_at"`
+	Id int              `sql:"id"`
+}
+
+// OK: test of the gosql.SoftDelete directive in the relation type
+type SelectAnalysisTestOK_SoftDelete struct {
+	Rel []*softDeleteRecord `rel:"relation_a:a"`
+}
+
+// OK: test of the gosql.WithDeleted directive
+type DeleteAnalysisTestOK_WithDeleted struct {
+	Rel []*softDeleteRecord `rel:"relation_a:a"`
+	_   gosql.All
+	_   gosql.WithDeleted
+}
+
+type autoNowRecord struct {
+	Id        int       `sql:"id,pk"`
+	CreatedAt time.Time `sql:"created_at,autonow=insert"`
+	UpdatedAt time.Time `sql:"updated_at,autonow=update:clock_timestamp"`
+}
+
+// OK: test of the "autonow" field tag option
+type UpdateAnalysisTestOK_AutoNow struct {
+	Rel *autoNowRecord `rel:"relation_a:a"`
+}
+
+// OK: test of expression fields
+type SelectAnalysisTestOK_ExprFields struct {
+	Rel struct {
+		Id         int    `sql:"id"`
+		EmailLower string `sql:"=lower(a.email)"`
+		FullName   string `sql:"=concat_ws(' ', a.first_name, a.last_name)"`
+	} `rel:"relation_a:a"`
+}
+
+type windowRecord struct {
+	Id        int   `sql:"id"`
+	RowNumber int64 `sql:"@row_number,over:partition=a.org_id;order=-a.created_at"`
+	PrevId    *int  `sql:"@lag(a.id),over"`
+}
+
+// OK: test of window function fields and the "totalcount" field
+type SelectAnalysisTestOK_WindowFields struct {
+	Rel        []*windowRecord `rel:"relation_a:a"`
+	TotalCount int
+}
+
+type manyChildRecord struct {
+	_    gosql.SoftDelete `sql:"deleted_at"`
+	Id   int              `sql:"id"`
+	Name string           `sql:"name"`
+}
+
+type manyRecord struct {
+	Id       int                `sql:"id"`
+	Children []*manyChildRecord `sql:"relation_b:b,many,b.a_id = a.id"`
+}
+
+// OK: test of the "many" field tag option
+type SelectAnalysisTestOK_ManyFields struct {
+	Rel []*manyRecord `rel:"relation_a:a"`
+}
+
+type compositeAddress struct {
+	Street string `sql:"street"`
+	Zip    *int   `sql:"zip"`
+	Note   string `sql:"-"`
+	hidden string `sql:"hidden"`
+}
+
+type compositeRecord struct {
+	Address   *compositeAddress  `sql:"address"`
+	Addresses []compositeAddress `sql:"addresses"`
+	CreatedAt time.Time          `sql:"created_at"`
+}
+
+// OK: test of struct fields mapped to composite type columns
+type SelectAnalysisTestOK_CompositeFields struct {
+	Rel []*compositeRecord `rel:"relation_a:a"`
+}
+
+// OK: test of custom operators in where, join, and order by tags
+type SelectAnalysisTestOK_CustomOperators struct {
+	Rel  []T `rel:"relation_a:a"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"relation_b:b,b.tags && a.tags"`
+	}
+	Where struct {
+		Tags []string     `sql:"a.tags @>"`
+		Name string       `sql:"a.name %"`
+		_    gosql.Column `sql:"a.name ~~* 'foo%'"`
+		_    gosql.Column `sql:"a.num=-1"`
+	}
+	_     gosql.OrderBy `sql:"-a.location <-> $Point:nullsfirst,a.id"`
+	Point string
+}
@@ -171,3 +171,7 @@ func (t MyTime) Scan(src interface{}) error {
 	// ....
 	return nil
 }
+
+// ColorEnum has the Go name of the color_enum type's generated Go type
+// but it is declared outside of the package of the generated enum types.
+type ColorEnum string
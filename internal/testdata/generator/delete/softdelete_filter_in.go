@@ -0,0 +1,10 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+type DeleteSoftDeleteWithFilterQuery struct {
+	_ gosql.Relation `rel:"test_soft_delete"`
+	gosql.Filter
+}
@@ -0,0 +1,12 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+type DeleteSoftDeleteWithWhereQuery struct {
+	_     gosql.Relation `rel:"test_soft_delete"`
+	Where struct {
+		Id int `sql:"id"`
+	}
+}
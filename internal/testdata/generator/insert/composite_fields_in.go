@@ -0,0 +1,17 @@
+package testdata
+
+type CompositeFieldsAddress struct {
+	Street  string `sql:"street"`
+	City    string `sql:"city"`
+	ZipCode int    `sql:"zip"`
+}
+
+type CompositeFieldsRecord struct {
+	Id        int                      `sql:"id"`
+	Address   CompositeFieldsAddress   `sql:"address"`
+	Addresses []CompositeFieldsAddress `sql:"addresses"`
+}
+
+type InsertCompositeFieldsQuery struct {
+	Record *CompositeFieldsRecord `rel:"test_composite:c"`
+}
@@ -0,0 +1,52 @@
+// This file was scaffolded by "github.com/frk/gosql", it is meant to be edited.
+
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+// SelectOrderItemByOrderIDAndProductID selects a single record of the "shop.order_items" table by its primary key.
+type SelectOrderItemByOrderIDAndProductID struct {
+	OrderItem *OrderItem `rel:"shop.order_items"`
+	Where     struct {
+		OrderID   int64 `sql:"order_id"`
+		ProductID int64 `sql:"product_id"`
+	}
+}
+
+// SelectOrderItemList selects a filtered list of records of the "shop.order_items" table.
+type SelectOrderItemList struct {
+	OrderItems []*OrderItem `rel:"shop.order_items"`
+	gosql.Filter
+}
+
+// InsertOrderItem inserts a single record into the "shop.order_items" table.
+type InsertOrderItem struct {
+	OrderItem *OrderItem   `rel:"shop.order_items"`
+	_         gosql.Return `sql:"*"`
+}
+
+// UpdateOrderItem updates a single record of the "shop.order_items" table by its primary key.
+type UpdateOrderItem struct {
+	OrderItem *OrderItem `rel:"shop.order_items"`
+}
+
+// DeleteOrderItem deletes a single record of the "shop.order_items" table by its primary key.
+type DeleteOrderItem struct {
+	_     gosql.Relation `rel:"shop.order_items"`
+	Where struct {
+		OrderID   int64 `sql:"order_id"`
+		ProductID int64 `sql:"product_id"`
+	}
+}
+
+// InsertOrUpdateOrderItem inserts a single record into the "shop.order_items" table, or updates the record that conflicts with it.
+type InsertOrUpdateOrderItem struct {
+	OrderItem  *OrderItem `rel:"shop.order_items"`
+	OnConflict struct {
+		_ gosql.Constraint `sql:"order_items_pkey"`
+		_ gosql.Update     `sql:"quantity"`
+	}
+	_ gosql.Return `sql:"*"`
+}
@@ -0,0 +1,52 @@
+// This file was scaffolded by "github.com/frk/gosql", it is meant to be edited.
+
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+// SelectTagByID selects a single record of the "tags" table by its primary key.
+type SelectTagByID struct {
+	Tag   *Tag `rel:"tags"`
+	Where struct {
+		ID int64 `sql:"id"`
+	}
+}
+
+// SelectTagList selects a filtered list of records of the "tags" table.
+type SelectTagList struct {
+	Tags []*Tag `rel:"tags"`
+	gosql.Filter
+}
+
+// InsertTag inserts a single record into the "tags" table.
+type InsertTag struct {
+	Tag *Tag          `rel:"tags"`
+	_   gosql.Default `sql:"id"`
+	_   gosql.Return  `sql:"*"`
+}
+
+// UpdateTag updates a single record of the "tags" table by its primary key.
+type UpdateTag struct {
+	Tag *Tag `rel:"tags"`
+}
+
+// DeleteTag deletes a single record of the "tags" table by its primary key.
+type DeleteTag struct {
+	_     gosql.Relation `rel:"tags"`
+	Where struct {
+		ID int64 `sql:"id"`
+	}
+}
+
+// InsertOrUpdateTag inserts a single record into the "tags" table, or updates the record that conflicts with it.
+type InsertOrUpdateTag struct {
+	Tag        *Tag          `rel:"tags"`
+	_          gosql.Default `sql:"id"`
+	OnConflict struct {
+		_ gosql.Index  `sql:"tags_slug_idx"`
+		_ gosql.Update `sql:"label"`
+	}
+	_ gosql.Return `sql:"*"`
+}
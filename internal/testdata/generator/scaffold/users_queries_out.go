@@ -0,0 +1,53 @@
+// This file was scaffolded by "github.com/frk/gosql", it is meant to be edited.
+
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+// SelectUserByID selects a single record of the "users" table by its primary key.
+type SelectUserByID struct {
+	User  *User `rel:"users"`
+	Where struct {
+		ID int32 `sql:"id"`
+	}
+}
+
+// SelectUserList selects a filtered list of records of the "users" table.
+type SelectUserList struct {
+	Users []*User `rel:"users"`
+	gosql.Filter
+}
+
+// InsertUser inserts a single record into the "users" table.
+type InsertUser struct {
+	User *User         `rel:"users"`
+	_    gosql.Default `sql:"id,search"`
+	_    gosql.Return  `sql:"*"`
+}
+
+// UpdateUser updates a single record of the "users" table by its primary key.
+type UpdateUser struct {
+	User *User         `rel:"users"`
+	_    gosql.Default `sql:"search"`
+}
+
+// DeleteUser deletes a single record of the "users" table by its primary key.
+type DeleteUser struct {
+	_     gosql.Relation `rel:"users"`
+	Where struct {
+		ID int32 `sql:"id"`
+	}
+}
+
+// InsertOrUpdateUser inserts a single record into the "users" table, or updates the record that conflicts with it.
+type InsertOrUpdateUser struct {
+	User       *User         `rel:"users"`
+	_          gosql.Default `sql:"id,search"`
+	OnConflict struct {
+		_ gosql.Constraint `sql:"users_email_key"`
+		_ gosql.Update     `sql:"full_name,created_at"`
+	}
+	_ gosql.Return `sql:"*"`
+}
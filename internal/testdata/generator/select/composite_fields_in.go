@@ -0,0 +1,18 @@
+package testdata
+
+type CompositeFieldsAddress struct {
+	City    string `sql:"city"`
+	Street  string `sql:"street"`
+	ZipCode *int   `sql:"zip"`
+	Note    string `sql:"-"`
+}
+
+type CompositeFieldsRecord struct {
+	Id        int                       `sql:"id"`
+	Address   *CompositeFieldsAddress   `sql:"address"`
+	Addresses []*CompositeFieldsAddress `sql:"addresses"`
+}
+
+type SelectCompositeFieldsQuery struct {
+	Records []*CompositeFieldsRecord `rel:"test_composite:c"`
+}
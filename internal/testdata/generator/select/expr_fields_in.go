@@ -0,0 +1,16 @@
+package testdata
+
+type ExprFieldsUser struct {
+	Id         int     `sql:"id"`
+	Email      string  `sql:"email"`
+	EmailLower string  `sql:"=lower(u.email)"`
+	CreatedAt  float64 `sql:"=extract(epoch from u.created_at)"`
+	FullName   string  `sql:"=coalesce(nullif(u.full_name, ''), u.email)"`
+}
+
+type SelectExprFieldsQuery struct {
+	User  *ExprFieldsUser `rel:"test_user:u"`
+	Where struct {
+		Id int `sql:"u.id"`
+	}
+}
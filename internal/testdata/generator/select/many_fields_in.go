@@ -0,0 +1,30 @@
+package testdata
+
+import (
+	"time"
+
+	"github.com/frk/gosql"
+)
+
+type ManyFieldsPost struct {
+	Id        int       `sql:"id" json:"id"`
+	Content   string    `sql:"content" json:"content"`
+	CreatedAt time.Time `sql:"created_at" json:"created_at"`
+}
+
+type ManyFieldsNote struct {
+	_       gosql.SoftDelete `sql:"deleted_at"`
+	Id      int              `sql:"id"`
+	Content string           `sql:"content"`
+}
+
+type ManyFieldsUser struct {
+	Id    int               `sql:"id"`
+	Email string            `sql:"email"`
+	Posts []*ManyFieldsPost `sql:"test_post:p,many,p.user_id = u.id,p.is_spam isfalse"`
+	Notes []ManyFieldsNote  `sql:"test_soft_delete:n,many,n.user_id = u.id"`
+}
+
+type SelectManyFieldsQuery struct {
+	Users []*ManyFieldsUser `rel:"test_user:u"`
+}
@@ -0,0 +1,17 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+type OrderByOperatorPlace struct {
+	Id   int    `sql:"id"`
+	Name string `sql:"name"`
+}
+
+type SelectWithOrderByOperatorQuery struct {
+	Places []*OrderByOperatorPlace `rel:"test_place:p"`
+	_      gosql.OrderBy           `sql:"p.location <-> $Point,-p.id"`
+	Point  [2]float64
+	Limit  int
+}
@@ -0,0 +1,10 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+type SelectSoftDeleteWithFilterQuery struct {
+	Records []*SoftDeleteRecord `rel:"test_soft_delete:s"`
+	gosql.Filter
+}
@@ -0,0 +1,16 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+	"github.com/frk/gosql/internal/testdata/common"
+)
+
+type SelectSoftDeleteWithJoinQuery struct {
+	Users []*common.User `rel:"test_user:u"`
+	Join  struct {
+		_ gosql.InnerJoin `sql:"test_soft_delete:s,s.user_id = u.id"`
+	}
+	Where struct {
+		Content string `sql:"s.content"`
+	}
+}
@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"time"
+
+	"github.com/frk/gosql"
+)
+
+type SoftDeleteRecord struct {
+	_         gosql.SoftDelete `sql:"deleted_at"`
+	Id        int              `sql:"id"`
+	UserId    int              `sql:"user_id"`
+	Content   string           `sql:"content"`
+	DeletedAt *time.Time       `sql:"deleted_at"`
+}
+
+type SelectSoftDeleteWithWhereQuery struct {
+	Records []*SoftDeleteRecord `rel:"test_soft_delete:s"`
+	Where   struct {
+		UserId int `sql:"s.user_id"`
+	}
+}
@@ -0,0 +1,13 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+)
+
+type SelectSoftDeleteWithDeletedQuery struct {
+	Records []*SoftDeleteRecord `rel:"test_soft_delete:s"`
+	Where   struct {
+		UserId int `sql:"s.user_id"`
+	}
+	_ gosql.WithDeleted
+}
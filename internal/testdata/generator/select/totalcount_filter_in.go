@@ -0,0 +1,12 @@
+package testdata
+
+import (
+	"github.com/frk/gosql"
+	"github.com/frk/gosql/internal/testdata/common"
+)
+
+type SelectTotalCountWithFilterQuery struct {
+	Users []*common.User2 `rel:"test_user:u"`
+	gosql.Filter
+	TotalCount int
+}
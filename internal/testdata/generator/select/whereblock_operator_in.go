@@ -0,0 +1,12 @@
+package testdata
+
+import (
+	"github.com/frk/gosql/internal/testdata/common"
+)
+
+type SelectWithWhereBlockOperatorQuery struct {
+	Users []*common.User `rel:"test_user:u"`
+	Where struct {
+		FullName string `sql:"u.full_name ~~*"`
+	}
+}
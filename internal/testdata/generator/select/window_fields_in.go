@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"time"
+)
+
+type WindowFieldsPost struct {
+	Id        int       `sql:"id"`
+	UserId    int       `sql:"user_id"`
+	CreatedAt time.Time `sql:"created_at"`
+	RowNumber int64     `sql:"@row_number,over:partition=p.user_id;order=-p.created_at"`
+	Rank      int64     `sql:"@rank,over:order=p.created_at:nullsfirst"`
+	PrevId    *int      `sql:"@lag(p.id),over:partition=p.user_id;order=p.id"`
+	UserPosts int64     `sql:"@count,over:partition=p.user_id"`
+}
+
+type SelectWindowFieldsQuery struct {
+	Posts []*WindowFieldsPost `rel:"test_post:p"`
+	Where struct {
+		IsSpam bool `sql:"p.is_spam"`
+	}
+}
@@ -0,0 +1,17 @@
+package testdata
+
+import (
+	"time"
+)
+
+type AutoNowUser struct {
+	Id        int       `sql:"id,pk,ro"`
+	Email     string    `sql:"email"`
+	FullName  string    `sql:"full_name"`
+	CreatedAt time.Time `sql:"created_at,autonow=insert"`
+	UpdatedAt time.Time `sql:"updated_at,autonow"`
+}
+
+type UpdateAutoNowSingleQuery struct {
+	User *AutoNowUser `rel:"test_user"`
+}
@@ -0,0 +1,5 @@
+package testdata
+
+type UpdateAutoNowSliceQuery struct {
+	Users []*AutoNowUser `rel:"test_user:u"`
+}
@@ -438,3 +438,268 @@ type InsertPostgresTestBAD_OnConflictIndexColumnsNotUnique struct {
 		_ gosql.Column `sql:"c.col_indkey1,c.col_indkey2,c.col_indkey3"`
 	}
 }
+
+//BAD: autonow option on column that is not of a timestamp type.
+type InsertPostgresTestBAD_AutoNowColumnType struct {
+	Rel struct {
+		B string `sql:"col_b,autonow"`
+	} `rel:"column_tests_1:c"`
+}
+
+//BAD: force directive referencing a generated column.
+type UpdatePostgresTestBAD_ForceGeneratedColumn struct {
+	Rel struct {
+		Price float64 `sql:"price"`
+		Total float64 `sql:"total,-w"`
+	} `rel:"test_generated:g"`
+	_ gosql.Force `sql:"g.total"`
+}
+
+//BAD: insert into always-identity column without override.
+type InsertPostgresTestBAD_IdentityNoOverride struct {
+	Rel struct {
+		Id    int     `sql:"id"`
+		Price float64 `sql:"price"`
+		Qty   int     `sql:"qty"`
+	} `rel:"test_generated:g"`
+}
+
+//BAD: update of always-identity column.
+type UpdatePostgresTestBAD_IdentityUpdate struct {
+	Rel struct {
+		Id    int     `sql:"id"`
+		Price float64 `sql:"price"`
+	} `rel:"test_generated:g"`
+}
+
+//BAD: strict nulls, NULLable column read into non-nilable field.
+type SelectPostgresTestBAD_StrictNullsColumn struct {
+	Rel struct {
+		A int  `sql:"col_a"`
+		C bool `sql:"col_c"`
+	} `rel:"column_tests_1"`
+}
+
+//BAD: strict nulls, view column NULLable due to outer join.
+type SelectPostgresTestBAD_StrictNullsOuterJoinView struct {
+	Rel struct {
+		B string `sql:"col_b"`
+	} `rel:"view_test_outer"`
+}
+
+//BAD: update of a materialized view.
+type UpdatePostgresTestBAD_MaterializedView struct {
+	Rel struct {
+		A int    `sql:"col_a"`
+		B string `sql:"col_b"`
+	} `rel:"matview_test"`
+}
+
+//BAD: insert into a foreign table whose foreign-data wrapper has no handler.
+type InsertPostgresTestBAD_ForeignTable struct {
+	Rel struct {
+		Id   int    `sql:"id"`
+		Name string `sql:"name"`
+	} `rel:"test_foreign"`
+}
+
+//BAD: runtime role without the SELECT privilege on a read column.
+type SelectPostgresTestBAD_RuntimeRoleColumn struct {
+	Rel struct {
+		A int   `sql:"col_a"`
+		C *bool `sql:"col_c"`
+	} `rel:"column_tests_1"`
+}
+
+//BAD: runtime role without the DELETE privilege on the target relation.
+type DeletePostgresTestBAD_RuntimeRoleRelation struct {
+	Rel struct {
+		A int `sql:"col_a"`
+	} `rel:"column_tests_1"`
+	Where struct {
+		A int `sql:"col_a"`
+	}
+}
+
+//BAD: join condition omitted with no foreign key linking the relations.
+type SelectPostgresTestBAD_JoinInferredNone struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_user:u"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"column_tests_1:c"`
+	}
+}
+
+//BAD: join condition omitted with more than one foreign key linking the relations.
+type SelectPostgresTestBAD_JoinInferredAmbiguous struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_join_message:m"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"test_user:u"`
+	}
+}
+
+//BAD: the relation is not in the default schema and it exists in more than one of the other schemas.
+type SelectPostgresTestBAD_DefaultSchemaAmbiguous struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_account"`
+}
+
+//BAD: the relation type has drifted from the relation's columns.
+type SelectPostgresTestBAD_Drift struct {
+	Rel struct {
+		A string `sql:"col_a"`
+		B int    `sql:"col_b"`
+		C *int   `sql:"col_c"`
+		X string `sql:"col_x"`
+	} `rel:"test_coalesce"`
+}
+
+//BAD: strict enums, the field's type has the enum's Go name but not its package
+type SelectPostgresTestBAD_StrictEnumsPackage struct {
+	Rel struct {
+		ColorEnum common.ColorEnum `sql:"color_enum"`
+	} `rel:"column_tests_3"`
+}
+
+//BAD: strict enums, the IN predicate's field is not of the enum's Go type
+type SelectPostgresTestBAD_StrictEnumsWhere struct {
+	Rel struct {
+		ColorEnum ColorEnum `sql:"color_enum"`
+	} `rel:"column_tests_3"`
+	Where struct {
+		ColorEnums []string `sql:"color_enum isin"`
+	}
+}
+
+//BAD: strict nulls, view column produced by NULLIF.
+type SelectPostgresTestBAD_StrictNullsNullifView struct {
+	Rel struct {
+		B string `sql:"col_b"`
+	} `rel:"view_test_nullif"`
+}
+
+//BAD: strict nulls, view column produced by CASE.
+type SelectPostgresTestBAD_StrictNullsCaseView struct {
+	Rel struct {
+		B string `sql:"col_b"`
+	} `rel:"view_test_case"`
+}
+
+//BAD: strict nulls, view column produced by UNION.
+type SelectPostgresTestBAD_StrictNullsUnionView struct {
+	Rel struct {
+		B string `sql:"col_b"`
+	} `rel:"view_test_union"`
+}
+
+//BAD: strict nulls, view column named like a NOT NULL base column but referencing a NULLable one.
+type SelectPostgresTestBAD_StrictNullsAliasView struct {
+	Rel struct {
+		B bool `sql:"col_b"`
+	} `rel:"view_test_alias"`
+}
+
+//BAD: ON CONFLICT clause for a foreign table.
+type InsertPostgresTestBAD_ForeignTableOnConflict struct {
+	Rel struct {
+		Id   int    `sql:"id"`
+		Name string `sql:"name"`
+	} `rel:"test_foreign_updatable"`
+	OnConflict struct {
+		_ gosql.Ignore
+	}
+}
+
+//BAD: join condition of a self-join omitted with more than one self-referencing foreign key.
+type SelectPostgresTestBAD_JoinInferredSelfAmbiguous struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_join_pair:a"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"test_join_pair:b"`
+	}
+}
+
+//BAD: where field with an operator that does not exist for the column's type.
+type SelectPostgresTestBAD_WhereOperatorUnknown struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	Where struct {
+		Name string `sql:"p.name <->"`
+	}
+}
+
+//BAD: where field whose type does not match the operator's operand type.
+type SelectPostgresTestBAD_WhereOperatorFieldType struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	Where struct {
+		Name int `sql:"p.name ~~*"`
+	}
+}
+
+//BAD: where column with an operator whose operand types do not match.
+type SelectPostgresTestBAD_WhereOperatorColumnType struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	Where struct {
+		_ gosql.Column `sql:"p.id ~~* 'foo%'"`
+	}
+}
+
+//BAD: where field with an operator whose result is not boolean.
+type SelectPostgresTestBAD_WhereOperatorResult struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	Where struct {
+		Point [2]float64 `sql:"p.location <->"`
+	}
+}
+
+//BAD: order by param whose type does not match the operator's operand type.
+type SelectPostgresTestBAD_OrderByOperatorParamType struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	_     gosql.OrderBy  `sql:"p.location <-> $Point"`
+	Point int
+}
+
+// BAD: strict nulls, the result of lag is NULL for the first row
+type SelectPostgresTestBAD_StrictNullsWindowLag struct {
+	Rel struct {
+		Prev int `sql:"@lag(col_a),over:order=col_a"`
+	} `rel:"column_tests_1"`
+}
+
+//BAD: join condition of a self-join omitted with a self-referencing foreign key.
+type SelectPostgresTestBAD_JoinInferredSelf struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_join_node:n"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"test_join_node:p"`
+	}
+}
+
+//BAD: join condition of a USING list's relation omitted with a foreign key to the target only.
+type DeletePostgresTestBAD_JoinInferredTarget struct {
+	Rel   struct{} `rel:"test_post:p"`
+	Using struct {
+		_ gosql.Relation `sql:"test_user:u"`
+		_ gosql.LeftJoin `sql:"test_join1:j"`
+	}
+	Where struct {
+		_ gosql.Column `sql:"p.user_id = u.id"`
+	}
+}
+
+//BAD: the json number of an integer column cannot be decoded into a string field.
+type SelectPostgresTestBAD_ManyFieldType struct {
+	Rel struct {
+		Id    int                    `sql:"id"`
+		Posts []manyFieldTypePostBAD `sql:"test_post:p,many,p.user_id = u.id"`
+	} `rel:"test_user:u"`
+}
+
+type manyFieldTypePostBAD struct {
+	UserId string `sql:"user_id"`
+}
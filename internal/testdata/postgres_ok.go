@@ -63,3 +63,245 @@ type SelectPostgresTestOK_WhereJoinedUnaryNullColumn struct {
 		_ gosql.Column `sql:"b.col_baz isnull"`
 	}
 }
+
+type domainRecord struct {
+	Id          int      `sql:"id"`
+	Email       string   `sql:"email"`
+	BackupEmail string   `sql:"backup_email"`
+	Flag        byte     `sql:"flag"`
+	Emails      []string `sql:"emails"`
+}
+
+// OK: columns of domain types are checked against their base types
+type SelectPostgresTestOK_DomainTypes struct {
+	Rel   []*domainRecord `rel:"test_domains:d"`
+	Where struct {
+		Email  string   `sql:"d.email"`
+		Emails []string `sql:"d.backup_email isin"`
+	}
+}
+
+// OK: columns of domain types are checked against their base types
+type InsertPostgresTestOK_DomainTypes struct {
+	Rel *domainRecord `rel:"test_domains:d"`
+}
+
+type generatedRecord struct {
+	Id    int     `sql:"id"`
+	Seq   int     `sql:"seq"`
+	Price float64 `sql:"price"`
+	Qty   int     `sql:"qty"`
+	Total float64 `sql:"total"`
+}
+
+// OK: generated columns are not written to, always-identity column is overridden
+type InsertPostgresTestOK_GeneratedColumns struct {
+	Rel *generatedRecord `rel:"test_generated:g"`
+	_   gosql.Override   `sql:"system"`
+}
+
+// OK: generated columns are not written to, always-identity column is set to DEFAULT
+type InsertPostgresTestOK_IdentityDefault struct {
+	Rel struct {
+		Id    int     `sql:"id,default"`
+		Price float64 `sql:"price"`
+		Qty   int     `sql:"qty"`
+		Total float64 `sql:"total"`
+	} `rel:"test_generated:g"`
+}
+
+// OK: always-identity column excluded from writes
+type UpdatePostgresTestOK_IdentityReadOnly struct {
+	Rel struct {
+		Id    int     `sql:"id,-w"`
+		Price float64 `sql:"price"`
+		Total float64 `sql:"total"`
+	} `rel:"test_generated:g"`
+	Where struct {
+		Id int `sql:"g.id"`
+	}
+}
+
+// OK: strict nulls, NULLable columns read into nilable or coalesced fields
+type SelectPostgresTestOK_StrictNulls struct {
+	Rel struct {
+		A int       `sql:"col_a"`
+		B string    `sql:"col_b"`
+		C *bool     `sql:"col_c"`
+		D float64   `sql:"col_d,coalesce"`
+		E time.Time `sql:"col_e"`
+	} `rel:"column_tests_1"`
+}
+
+// OK: strict nulls, NOT NULL inferred from the view's base table columns
+type SelectPostgresTestOK_StrictNullsView struct {
+	Rel struct {
+		A int       `sql:"col_a"`
+		B string    `sql:"col_b"`
+		C *bool     `sql:"col_c"`
+		D float64   `sql:"col_d,nn"`
+		E time.Time `sql:"col_e"`
+		Z *bool     `sql:"col_z"`
+	} `rel:"view_test"`
+}
+
+type partitionedRecord struct {
+	Id     int    `sql:"id"`
+	Region string `sql:"region"`
+	Name   string `sql:"name"`
+}
+
+// OK: conflict target of a partitioned table includes the partition key
+type InsertPostgresTestOK_PartitionedOnConflict struct {
+	Rel        *partitionedRecord `rel:"test_partitioned:p"`
+	OnConflict struct {
+		_ gosql.Column `sql:"id,region"`
+		_ gosql.Update `sql:"name"`
+	}
+}
+
+// OK: select from a materialized view
+type SelectPostgresTestOK_MaterializedView struct {
+	Rel struct {
+		A int    `sql:"col_a"`
+		B string `sql:"col_b"`
+	} `rel:"matview_test"`
+}
+
+// OK: select from a foreign table
+type SelectPostgresTestOK_ForeignTable struct {
+	Rel struct {
+		Id   int    `sql:"id"`
+		Name string `sql:"name"`
+	} `rel:"test_foreign"`
+}
+
+// OK: runtime role has the SELECT privilege on the read and filtered columns
+type SelectPostgresTestOK_RuntimeRole struct {
+	Rel struct {
+		A int    `sql:"col_a"`
+		B string `sql:"col_b"`
+	} `rel:"column_tests_1"`
+	Where struct {
+		B string `sql:"col_b"`
+	}
+}
+
+// OK: the index advisor notes the filter, function, and sort columns without an index
+type SelectPostgresTestOK_AdviseIndexes struct {
+	Rel struct {
+		Id    int    `sql:"id"`
+		Email string `sql:"email"`
+	} `rel:"test_user:u"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"test_post:p,p.user_id = u.id"`
+	}
+	Where struct {
+		Email    string `sql:"u.email,@lower"`
+		IsActive bool   `sql:"u.is_active"`
+	}
+	_ gosql.OrderBy `sql:"u.created_at"`
+}
+
+// OK: the index advisor has nothing to note, the columns have matching indexes
+type SelectPostgresTestOK_AdviseIndexesCovered struct {
+	Rel struct {
+		Id   int    `sql:"id"`
+		Name string `sql:"name"`
+	} `rel:"test_onconflict"`
+	Where struct {
+		Name string `sql:"name,@lower"`
+		Key  int    `sql:"key"`
+	}
+	_ gosql.OrderBy `sql:"id"`
+}
+
+// OK: join conditions inferred from the foreign keys, in either direction
+type SelectPostgresTestOK_JoinInferred struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_post:p"`
+	Join struct {
+		_ gosql.LeftJoin  `sql:"test_user:u"`
+		_ gosql.InnerJoin `sql:"test_join1:j"`
+	}
+}
+
+// OK: the unqualified relation identifiers are resolved against the default
+// schema, or against the only schema that contains the relation
+type SelectPostgresTestOK_DefaultSchema struct {
+	Rel struct {
+		Id int `sql:"id"`
+	} `rel:"test_invoice:i"`
+	Join struct {
+		_ gosql.LeftJoin `sql:"test_auth.test_account:a,a.id = i.account_id"`
+		_ gosql.LeftJoin `sql:"test_session:s,s.account_id = a.id"`
+	}
+}
+
+type exprFieldsRecord struct {
+	Email     string  `sql:"email"`
+	FullName  string  `sql:"full_name"`
+	CreatedAt float64 `sql:"=extract(epoch from created_at)"`
+}
+
+// OK: the expression fields are not written, even if their alias matches a column
+type InsertPostgresTestOK_ExprFields struct {
+	Rel *exprFieldsRecord `rel:"test_user"`
+}
+
+// ColorEnum stands in for the Go type generated for the color_enum type.
+type ColorEnum string
+
+type strictEnumsRecord struct {
+	ColorText string    `sql:"color_text"`
+	ColorEnum ColorEnum `sql:"color_enum"`
+	SomeTime  time.Time `sql:"some_time"`
+}
+
+// OK: strict enums, the fields mapped to the enum column are of the enum's Go type
+type SelectPostgresTestOK_StrictEnums struct {
+	Rel   []*strictEnumsRecord `rel:"column_tests_3"`
+	Where struct {
+		ColorEnums []ColorEnum `sql:"color_enum isin"`
+	}
+}
+
+// OK: slices compared to an enum column with the IN and ANY predicates
+type SelectPostgresTestOK_EnumArrayPredicates struct {
+	Rel   []*CT3 `rel:"column_tests_3"`
+	Where struct {
+		In  []COLOR_ENUM `sql:"color_enum isin"`
+		Any []COLOR_ENUM `sql:"color_enum = any"`
+	}
+}
+
+// OK: strict nulls, NOT NULL inferred for the renamed columns of a view
+type SelectPostgresTestOK_StrictNullsAliasView struct {
+	Rel struct {
+		Id   int    `sql:"id"`
+		Name string `sql:"name"`
+		B    *bool  `sql:"col_b"`
+	} `rel:"view_test_alias"`
+}
+
+type placeRecord struct {
+	Id   int    `sql:"id"`
+	Name string `sql:"name"`
+}
+
+// OK: custom operators in where conditions
+type SelectPostgresTestOK_WhereOperators struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	Where struct {
+		Name string       `sql:"p.name ~~*"`
+		_    gosql.Column `sql:"p.name !~~ 'foo%'"`
+	}
+}
+
+// OK: order by the distance to a point
+type SelectPostgresTestOK_OrderByOperator struct {
+	Rel   []*placeRecord `rel:"test_place:p"`
+	_     gosql.OrderBy  `sql:"p.location <-> $Point,-p.id"`
+	Point [2]float64
+}
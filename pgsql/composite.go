@@ -0,0 +1,409 @@
+package pgsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Composite returns a value that implements both the driver.Valuer and sql.Scanner
+// interfaces. The driver.Valuer produces a PostgreSQL composite type value from the
+// given struct, or pointer to struct, val and the sql.Scanner reads a PostgreSQL
+// composite type value into the given pointer to struct val.
+//
+// The fields argument holds the names of the struct's fields in the order of
+// the composite type's attributes, an attribute with no corresponding field
+// is represented by an empty name.
+func Composite(val interface{}, fields ...string) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return composite{val: val, fields: fields}
+}
+
+// CompositeArray returns a value that implements both the driver.Valuer and
+// sql.Scanner interfaces. The driver.Valuer produces a PostgreSQL composite
+// type array from the given slice val and the sql.Scanner reads a PostgreSQL
+// composite type array into the given pointer to slice val. The slice's element
+// type must be a struct or a pointer to a struct, the fields argument is the
+// same as that of Composite.
+func CompositeArray(val interface{}, fields ...string) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return compositeArray{val: val, fields: fields}
+}
+
+type composite struct {
+	val    interface{}
+	fields []string
+}
+
+func (v composite) Value() (driver.Value, error) {
+	rv := reflect.ValueOf(v.val)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	return pgFormatComposite(nil, rv, v.fields)
+}
+
+func (v composite) Scan(src interface{}) error {
+	data, err := srcbytes(src)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v.val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("pgsql: cannot scan composite into %T", v.val)
+	}
+	return pgScanComposite(rv.Elem(), data, v.fields)
+}
+
+type compositeArray struct {
+	val    interface{}
+	fields []string
+}
+
+func (v compositeArray) Value() (driver.Value, error) {
+	rv := reflect.ValueOf(v.val)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return nil, nil
+	}
+
+	out := []byte{'{'}
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			out = append(out, ',')
+		}
+
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				out = append(out, 'N', 'U', 'L', 'L')
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		b, err := pgFormatComposite(nil, elem, v.fields)
+		if err != nil {
+			return nil, err
+		}
+		out = pgAppendQuote1(out, b)
+	}
+	out = append(out, '}')
+	return out, nil
+}
+
+func (v compositeArray) Scan(src interface{}) error {
+	data, err := srcbytes(src)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v.val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("pgsql: cannot scan composite array into %T", v.val)
+	}
+	rv = rv.Elem()
+	if data == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	elems := pgParseCompositeArray(data)
+	slice := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if err := pgScanComposite(slice.Index(i), elem, v.fields); err != nil {
+			return err
+		}
+	}
+	rv.Set(slice)
+	return nil
+}
+
+// pgFormatComposite appends the text representation of the composite
+// value, whose attributes are held by the given struct's fields, to buf.
+func pgFormatComposite(buf []byte, rv reflect.Value, fields []string) ([]byte, error) {
+	buf = append(buf, '(')
+	for i, name := range fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if len(name) == 0 {
+			continue // NULL
+		}
+
+		b, err := pgFormatCompositeAttr(rv.FieldByName(name))
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			buf = pgAppendCompositeQuote(buf, b)
+		}
+	}
+	return append(buf, ')'), nil
+}
+
+// pgFormatCompositeAttr returns the text representation of the given
+// struct field's value, or nil if the value should be represented by NULL.
+func pgFormatCompositeAttr(fv reflect.Value) ([]byte, error) {
+	if valuer, ok := fv.Interface().(driver.Valuer); ok {
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			return nil, nil
+		}
+		val, err := valuer.Value()
+		if err != nil || val == nil {
+			return nil, err
+		}
+		fv = reflect.ValueOf(val)
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		return pgFormatCompositeAttr(fv.Elem())
+	}
+
+	if t, ok := fv.Interface().(time.Time); ok {
+		return []byte(t.Format(timestamptzLayout + ":00")), nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return []byte(fv.String()), nil
+	case reflect.Bool:
+		if fv.Bool() {
+			return []byte{'t'}, nil
+		}
+		return []byte{'f'}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt([]byte{}, fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint([]byte{}, fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat([]byte{}, fv.Float(), 'g', -1, fv.Type().Bits()), nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			// bytea in the hex format
+			out := make([]byte, 2+hex.EncodedLen(fv.Len()))
+			out[0], out[1] = '\\', 'x'
+			_ = hex.Encode(out[2:], fv.Bytes())
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("pgsql: unsupported composite attribute type %s", fv.Type())
+}
+
+// pgScanComposite reads the given text representation of
+// a composite value into the fields of the given struct.
+func pgScanComposite(rv reflect.Value, data []byte, fields []string) error {
+	if rv.Kind() == reflect.Ptr {
+		if data == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if data == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	attrs := pgParseComposite(data)
+	for i, name := range fields {
+		if len(name) == 0 || i >= len(attrs) {
+			continue
+		}
+		if err := pgScanCompositeAttr(rv.FieldByName(name), attrs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pgScanCompositeAttr reads the given text representation of
+// a composite value's attribute into the given struct field.
+func pgScanCompositeAttr(fv reflect.Value, data []byte) (err error) {
+	if scanner, ok := fv.Addr().Interface().(sql.Scanner); ok {
+		if data == nil {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan(data)
+	}
+	if data == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return pgScanCompositeAttr(fv.Elem(), data)
+	}
+
+	if _, ok := fv.Interface().(time.Time); ok {
+		t, err := pgParseCompositeTime(data)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(string(data))
+		return nil
+	case reflect.Bool:
+		fv.SetBool(len(data) > 0 && data[0] == 't')
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i64, err := strconv.ParseInt(string(data), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i64)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u64, err := strconv.ParseUint(string(data), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u64)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f64, err := strconv.ParseFloat(string(data), fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f64)
+		return nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			// bytea in the hex format
+			if len(data) < 2 || data[0] != '\\' || data[1] != 'x' {
+				return fmt.Errorf("pgsql: invalid bytea composite attribute %q", data)
+			}
+			out := make([]byte, hex.DecodedLen(len(data)-2))
+			if _, err := hex.Decode(out, data[2:]); err != nil {
+				return err
+			}
+			fv.SetBytes(out)
+			return nil
+		}
+	}
+	return fmt.Errorf("pgsql: unsupported composite attribute type %s", fv.Type())
+}
+
+// pgParseCompositeTime parses the text representation of
+// a date, time, timestamp, or timestamptz attribute.
+func pgParseCompositeTime(data []byte) (t time.Time, err error) {
+	for _, layout := range []string{timestamptzLayout + ":00", timestamptzLayout, timestampLayout, dateLayout, timetzLayout, timeLayout} {
+		if t, err = time.ParseInLocation(layout, string(data), noZone); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+// Expected format: '(ATTR [, ...])' where ATTR is either empty (NULL), an unquoted,
+// or a double quoted string. Inside a double quoted string a double quote is
+// represented either by two double quotes or by a backslash-escaped double quote.
+func pgParseComposite(a []byte) (out [][]byte) {
+	a = a[1 : len(a)-1] // drop parentheses
+
+	var attr []byte
+	for i := 0; i < len(a); i++ {
+		switch c := a[i]; {
+		case c == ',':
+			out = append(out, attr)
+			attr = nil
+		case c == '"':
+			if attr == nil {
+				attr = []byte{}
+			}
+			for i = i + 1; i < len(a); i++ {
+				if a[i] == '\\' && i+1 < len(a) {
+					i++
+				} else if a[i] == '"' {
+					if i+1 < len(a) && a[i+1] == '"' {
+						i++
+					} else {
+						break
+					}
+				}
+				attr = append(attr, a[i])
+			}
+		case c == '\\' && i+1 < len(a):
+			i++
+			attr = append(attr, a[i])
+		default:
+			attr = append(attr, c)
+		}
+	}
+	return append(out, attr)
+}
+
+// Expected format: '{ELEM [, ...]}' where ELEM is either NULL
+// or a double quoted composite value.
+func pgParseCompositeArray(a []byte) (out [][]byte) {
+	a = a[1 : len(a)-1] // drop curly braces
+
+	for i := 0; i < len(a); i++ {
+		switch a[i] {
+		case ',':
+			continue
+		case '"':
+			elem := []byte{}
+			for i = i + 1; i < len(a) && a[i] != '"'; i++ {
+				if a[i] == '\\' && i+1 < len(a) {
+					i++
+				}
+				elem = append(elem, a[i])
+			}
+			out = append(out, elem)
+		case 'N':
+			out = append(out, nil)
+			i += 3
+		default:
+			j := i
+			for j < len(a) && a[j] != ',' {
+				j++
+			}
+			out = append(out, a[i:j])
+			i = j
+		}
+	}
+	return out
+}
+
+// pgAppendCompositeQuote appends the given composite
+// attribute, as a double quoted string, to buf.
+func pgAppendCompositeQuote(buf, attr []byte) []byte {
+	buf = append(buf, '"')
+	for _, c := range attr {
+		if c == '"' || c == '\\' {
+			buf = append(buf, c)
+		}
+		buf = append(buf, c)
+	}
+	return append(buf, '"')
+}
@@ -0,0 +1,86 @@
+package pgsql
+
+import (
+	"testing"
+
+	"github.com/frk/compare"
+)
+
+func TestComposite(t *testing.T) {
+	type address struct {
+		Street string
+		City   *string
+		Zip    int
+	}
+	city := `New "York", NY`
+	fields := []string{"Street", "", "City", "Zip"}
+
+	t.Run("value", func(t *testing.T) {
+		got, err := Composite(address{Street: "1 Main St", City: &city, Zip: 10001}, fields...).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []byte(`("1 Main St",,"New ""York"", NY","10001")`)
+		if err := compare.Compare(got, want); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var got address
+		if err := Composite(&got, fields...).Scan([]byte(`("1 Main St",,"New ""York"", NY",10001)`)); err != nil {
+			t.Fatal(err)
+		}
+		want := address{Street: "1 Main St", City: &city, Zip: 10001}
+		if err := compare.Compare(got, want); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("bytea", func(t *testing.T) {
+		type file struct {
+			Name string
+			Data []byte
+		}
+		fields := []string{"Name", "Data"}
+
+		got, err := Composite(file{Name: "a", Data: []byte{0xde, 0xad, 0x01}}, fields...).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []byte(`("a","\\xdead01")`)
+		if err := compare.Compare(got, want); err != nil {
+			t.Error(err)
+		}
+
+		var f file
+		if err := Composite(&f, fields...).Scan([]byte(`(a,"\\xdead01")`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := compare.Compare(f, file{Name: "a", Data: []byte{0xde, 0xad, 0x01}}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("array value", func(t *testing.T) {
+		got, err := CompositeArray([]*address{{Street: "a", Zip: 1}, nil}, fields...).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []byte(`{"(\"a\",,,\"1\")",NULL}`)
+		if err := compare.Compare(got, want); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("array scan", func(t *testing.T) {
+		var got []*address
+		if err := CompositeArray(&got, fields...).Scan([]byte(`{"(a,,\"New \"\"York\"\", NY\",1)",NULL}`)); err != nil {
+			t.Fatal(err)
+		}
+		want := []*address{{Street: "a", City: &city, Zip: 1}, nil}
+		if err := compare.Compare(got, want); err != nil {
+			t.Error(err)
+		}
+	})
+}
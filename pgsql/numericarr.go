@@ -126,6 +126,16 @@ func NumericArrayToFloat64Slice(val *[]float64) sql.Scanner {
 	return numericArrayToFloat64Slice{val: val}
 }
 
+// NumericArrayFromStringSlice returns a driver.Valuer that produces a PostgreSQL numeric[] from the given Go []string.
+func NumericArrayFromStringSlice(val []string) driver.Valuer {
+	return numericArrayFromStringSlice{val: val}
+}
+
+// NumericArrayToStringSlice returns an sql.Scanner that converts a PostgreSQL numeric[] into a Go []string and sets it to val.
+func NumericArrayToStringSlice(val *[]string) sql.Scanner {
+	return numericArrayToStringSlice{val: val}
+}
+
 type numericArrayFromIntSlice struct {
 	val []int
 }
@@ -715,3 +725,48 @@ func (v numericArrayToFloat64Slice) Scan(src interface{}) error {
 	*v.val = float64s
 	return nil
 }
+
+type numericArrayFromStringSlice struct {
+	val []string
+}
+
+func (v numericArrayFromStringSlice) Value() (driver.Value, error) {
+	if v.val == nil {
+		return nil, nil
+	} else if len(v.val) == 0 {
+		return []byte{'{', '}'}, nil
+	}
+
+	out := []byte{'{'}
+
+	for _, s := range v.val {
+		out = append(out, s...)
+		out = append(out, ',')
+	}
+
+	out[len(out)-1] = '}' // replace last "," with "}"
+	return out, nil
+}
+
+type numericArrayToStringSlice struct {
+	val *[]string
+}
+
+func (v numericArrayToStringSlice) Scan(src interface{}) error {
+	arr, err := srcbytes(src)
+	if err != nil {
+		return err
+	} else if arr == nil {
+		*v.val = nil
+		return nil
+	}
+
+	elems := pgParseCommaArray(arr)
+	strs := make([]string, len(elems))
+	for i := 0; i < len(elems); i++ {
+		strs[i] = string(elems[i])
+	}
+
+	*v.val = strs
+	return nil
+}
@@ -101,6 +101,16 @@ func TestNumericArray(t *testing.T) {
 				input:  []float64{0, 922337203685477580.0},
 				output: []float64{0, 922337203685477580.0}},
 		},
+	}, {
+		valuer:  NumericArrayFromStringSlice,
+		scanner: NumericArrayToStringSlice,
+		data: []testdata{
+			{input: []string(nil), output: []string(nil)},
+			{input: []string{}, output: []string{}},
+			{
+				input:  []string{"-9223372036854775808.0000000001", "12345678901234567890.123456789"},
+				output: []string{"-9223372036854775808.0000000001", "12345678901234567890.123456789"}},
+		},
 	}, {
 		data: []testdata{
 			{